@@ -140,7 +140,7 @@ func BenchmarkAsyncWriter_Throughput(b *testing.B) {
 // 5. fillCallerInfo 性能
 func BenchmarkFillCallerInfo(b *testing.B) {
 	logger := newLogger()
-	logger.enableCaller = true
+	logger.EnableCaller(true)
 
 	entry := getEntry()
 	defer putEntry(entry)
@@ -149,6 +149,6 @@ func BenchmarkFillCallerInfo(b *testing.B) {
 	b.ResetTimer()
 
 	for i := 0; i < b.N; i++ {
-		logger.fillCallerInfo(entry)
+		logger.config().fillCallerInfo(entry)
 	}
 }
@@ -37,7 +37,7 @@ func TestNewLogger_OutputSelection(t *testing.T) {
 	}
 
 	// 检查输出是否正确设置
-	if logger.out == nil {
+	if logger.config().out == nil {
 		t.Error("Logger output should not be nil")
 	}
 
@@ -46,13 +46,13 @@ func TestNewLogger_OutputSelection(t *testing.T) {
 		// 在 debug 模式下，应该包装 stdout
 		// 由于 AddSync 返回的是接口，我们无法检查内部类型
 		// 只需确认输出不是 nil
-		if logger.out == nil {
+		if logger.config().out == nil {
 			t.Error("In debug mode, logger should have output")
 		}
 	} else {
 		// 在 release 模式下，应该使用文件轮转器
 		// 只需确认输出不是 nil
-		if logger.out == nil {
+		if logger.config().out == nil {
 			t.Error("In release mode, logger should have output")
 		}
 	}
@@ -0,0 +1,53 @@
+package log
+
+import "time"
+
+// Clock tells the time to loggers and rotators, so that tests can control
+// entry timestamps and rotation. logtest.FakeClock is a manual implementation.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+// now returns the time from the logger's clock
+func (c *loggerConfig) now() time.Time {
+	if c.clock == nil {
+		return time.Now()
+	}
+	return c.clock.Now()
+}
+
+// SetClock sets the clock timestamping entries, nil restores the wall clock
+func (p *Logger) SetClock(clock Clock) *Logger {
+	return p.update(func(c *loggerConfig) {
+		c.clock = clock
+	})
+}
+
+// Clock returns the clock timestamping entries
+func (p *Logger) Clock() Clock {
+	if clock := p.config().clock; clock != nil {
+		return clock
+	}
+	return SystemClock
+}
+
+// SetClock sets the clock deciding rotation and retention, nil restores the
+// wall clock. Background timers such as pre-opening still run on real time.
+func (r *Rotator) SetClock(clock Clock) *Rotator {
+	if clock == nil {
+		clock = SystemClock
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.clock = clock
+	return r
+}
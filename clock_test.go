@@ -0,0 +1,79 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/logtest"
+)
+
+func TestLogger_SetClock(t *testing.T) {
+	var buf bytes.Buffer
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 59, 59, 0, time.UTC))
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).
+		SetFormatter(&JSONFormatter{}).SetClock(clock)
+
+	logger.Info("tick")
+	clock.Add(time.Second)
+	logger.InfoContext(t.Context(), "tock")
+
+	out := buf.String()
+	if !strings.Contains(out, "2024-01-02T10:59:59Z") || !strings.Contains(out, "2024-01-02T11:00:00Z") {
+		t.Errorf("entries should be stamped by the clock, got %q", out)
+	}
+	if logger.Clock() != clock {
+		t.Error("Clock should return the configured clock")
+	}
+	if logger.SetClock(nil).Clock() != SystemClock {
+		t.Error("nil should restore the system clock")
+	}
+}
+
+func TestSampler_UsesLoggerClock(t *testing.T) {
+	var buf bytes.Buffer
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).
+		SetClock(clock).SetSampler(NewSampler(time.Minute, 1, 0))
+
+	logger.Info("hot")
+	logger.Info("hot")
+	clock.Add(time.Minute)
+	logger.Info("hot")
+
+	if got := strings.Count(buf.String(), "hot"); got != 2 {
+		t.Errorf("a new tick should start when the clock moves on, got %d lines", got)
+	}
+}
+
+func TestDeduper_UsesLoggerClock(t *testing.T) {
+	var buf bytes.Buffer
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).
+		SetFormatter(&JSONFormatter{}).SetClock(clock).SetDeduper(d)
+
+	logger.Warn("disk low")
+	clock.Add(time.Second)
+	logger.Warn("disk low")
+	clock.Add(time.Second)
+	logger.Warn("disk low")
+
+	d.flushExpired()
+	if got := strings.Count(buf.String(), "\n"); got != 1 {
+		t.Fatalf("the window is still open on the logger clock, got %q", buf.String())
+	}
+
+	clock.Add(time.Hour)
+	d.flushExpired()
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("the window should close on the logger clock, got %q", buf.String())
+	}
+	want := `"repeated":2,"first":"2024-01-02T10:00:00Z","last":"2024-01-02T10:00:02Z"`
+	if !strings.Contains(lines[1], want) {
+		t.Errorf("collapsed line %s should contain %s", lines[1], want)
+	}
+}
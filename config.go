@@ -3,6 +3,7 @@
 import (
 	"os"
 	"path/filepath"
+	"time"
 )
 
 var (
@@ -12,4 +13,8 @@
 	DefaultMaxFiles = 48
 	// DefaultMaxFileSize 默认单文件的大小，如果超过的话，会自动文件分割
 	DefaultMaxFileSize = int64(1024 * 1024 * 8 * 100) // 800MB
+	// DefaultCleanupInterval 默认的过期文件清理间隔，每次轮转后也会清理
+	DefaultCleanupInterval = 10 * time.Minute
+	// DefaultPreOpen 默认在周期切换前多久于后台预先打开下一个文件
+	DefaultPreOpen = time.Second
 )
@@ -5,10 +5,15 @@
 	"time"
 )
 
-// KV represents a key-value pair for structured logging
+// KV represents a key-value pair for structured logging.
+//
+// Loose pairs keep their value in Value. Typed fields set Type and may keep
+// the value unboxed in Int instead, use Any to read it either way.
 type KV struct {
 	Key   string
 	Value interface{}
+	Type  FieldType
+	Int   int64
 }
 
 // Entry represents a log entry
@@ -43,6 +48,13 @@ type Entry struct {
 
 	// Structured fields (key-value pairs)
 	Fields []KV `json:"fields,omitempty"`
+
+	// EncodedFields holds Fields[:EncodedFieldsLen] already encoded by the
+	// active formatter (see FieldsEncoder), so bound fields are encoded once
+	// per logger instead of once per line. Formatters that don't use it
+	// simply encode Fields in full.
+	EncodedFields    []byte `json:"-"`
+	EncodedFieldsLen int    `json:"-"`
 }
 
 // MarshalJSON implements json.Marshaler interface for custom JSON serialization
@@ -88,7 +100,7 @@ func (e *Entry) MarshalJSON() ([]byte, error) {
 	if len(e.Fields) > 0 {
 		fields := make(map[string]interface{}, len(e.Fields))
 		for _, f := range e.Fields {
-			fields[f.Key] = f.Value
+			fields[f.Key] = f.Any()
 		}
 		m["fields"] = fields
 	}
@@ -111,5 +123,8 @@ func (p *Entry) Reset() {
 	p.CallerFunc = ""
 	p.PrefixMsg = p.PrefixMsg[:0]
 	p.SuffixMsg = p.SuffixMsg[:0]
+	p.Fields = nil
+	p.EncodedFields = nil
+	p.EncodedFieldsLen = 0
 }
 
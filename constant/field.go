@@ -0,0 +1,62 @@
+package constant
+
+import (
+	"math"
+	"time"
+)
+
+// FieldType tells how the value of a KV is stored
+type FieldType uint8
+
+const (
+	// FieldTypeAny keeps the value in Value, the type of loose key-value pairs
+	FieldTypeAny FieldType = iota
+	// FieldTypeString keeps a string in Value
+	FieldTypeString
+	// FieldTypeInt64 keeps an integer in Int
+	FieldTypeInt64
+	// FieldTypeFloat64 keeps the bits of a float64 in Int
+	FieldTypeFloat64
+	// FieldTypeBool keeps 1 or 0 in Int
+	FieldTypeBool
+	// FieldTypeDuration keeps nanoseconds in Int
+	FieldTypeDuration
+	// FieldTypeTime keeps Unix nanoseconds in Int and the *time.Location in Value
+	FieldTypeTime
+	// FieldTypeError keeps an error in Value
+	FieldTypeError
+	// FieldTypeStringer keeps a fmt.Stringer in Value, called when encoded
+	FieldTypeStringer
+	// FieldTypeBinary keeps a []byte in Value
+	FieldTypeBinary
+	// FieldTypeNamespace opens a namespace named Key holding the fields after it
+	FieldTypeNamespace
+	// FieldTypeObject keeps an object marshaler in Value
+	FieldTypeObject
+	// FieldTypeArray keeps an array marshaler in Value
+	FieldTypeArray
+)
+
+// Any returns the value of kv whatever the way it is stored
+func (kv KV) Any() interface{} {
+	switch kv.Type {
+	case FieldTypeInt64:
+		return kv.Int
+	case FieldTypeFloat64:
+		return math.Float64frombits(uint64(kv.Int))
+	case FieldTypeBool:
+		return kv.Int != 0
+	case FieldTypeDuration:
+		return time.Duration(kv.Int)
+	case FieldTypeTime:
+		t := time.Unix(0, kv.Int)
+		if loc, ok := kv.Value.(*time.Location); ok && loc != nil {
+			return t.In(loc)
+		}
+		return t
+	case FieldTypeNamespace:
+		return nil
+	default:
+		return kv.Value
+	}
+}
@@ -18,6 +18,40 @@ func (h HookFunc) OnWrite(entry interface{}) interface{} {
 	return h(entry)
 }
 
+// EntryHook is the typed counterpart of Hook, operating directly on *Entry.
+// Loggers call OnEntry instead of OnWrite for hooks implementing it.
+type EntryHook interface {
+	// OnEntry processes the log entry before writing
+	// Returns the entry to write, and false to skip logging
+	OnEntry(entry *Entry) (*Entry, bool)
+}
+
+// EntryHookFunc is a convenience type for implementing EntryHook with a function.
+// It also implements the legacy Hook interface.
+type EntryHookFunc func(entry *Entry) (*Entry, bool)
+
+// OnEntry implements EntryHook interface
+func (h EntryHookFunc) OnEntry(entry *Entry) (*Entry, bool) {
+	return h(entry)
+}
+
+// OnWrite implements Hook interface
+// Values other than *Entry are passed through unchanged
+func (h EntryHookFunc) OnWrite(entry interface{}) interface{} {
+	if entry == nil {
+		return nil
+	}
+	e, ok := entry.(*Entry)
+	if !ok {
+		return entry
+	}
+	out, keep := h(e)
+	if !keep || out == nil {
+		return nil
+	}
+	return out
+}
+
 // Format defines log formatting interface
 type Format interface {
 	// Format formats log entry to byte array
@@ -38,6 +72,15 @@ type FormatFull interface {
 	Clone() Format
 }
 
+// FieldsEncoder is implemented by formatters that can pre-encode a set of
+// fields once, so loggers with bound fields don't re-encode them on every line.
+// The returned bytes are placed in Entry.EncodedFields and must be
+// interpreted only by the formatter that produced them.
+type FieldsEncoder interface {
+	// EncodeFields encodes fields in the formatter's own field syntax
+	EncodeFields(fields []KV) []byte
+}
+
 // Writer defines the output writer interface
 type Writer interface {
 	Write(p []byte) (n int, err error)
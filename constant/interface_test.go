@@ -75,9 +75,51 @@ func TestHookFunc(t *testing.T) {
 	})
 }
 
+func TestEntryHookFunc(t *testing.T) {
+	upper := EntryHookFunc(func(entry *Entry) (*Entry, bool) {
+		if entry.Message == "drop" {
+			return nil, false
+		}
+		entry.Message = "hooked " + entry.Message
+		return entry, true
+	})
+
+	t.Run("OnEntry_executes_function", func(t *testing.T) {
+		e, keep := upper.OnEntry(&Entry{Message: "msg"})
+		if !keep || e.Message != "hooked msg" {
+			t.Errorf("unexpected result %v %v", e, keep)
+		}
+	})
+
+	t.Run("OnWrite_adapts_entry", func(t *testing.T) {
+		result := upper.OnWrite(&Entry{Message: "msg"})
+		e, ok := result.(*Entry)
+		if !ok || e.Message != "hooked msg" {
+			t.Errorf("OnWrite should return the modified *Entry, got %v", result)
+		}
+	})
+
+	t.Run("OnWrite_filters_entry", func(t *testing.T) {
+		if result := upper.OnWrite(&Entry{Message: "drop"}); result != nil {
+			t.Errorf("OnWrite should return nil when filtered, got %v", result)
+		}
+	})
+
+	t.Run("OnWrite_passes_through_other_types", func(t *testing.T) {
+		if result := upper.OnWrite("test"); result != "test" {
+			t.Errorf("OnWrite should pass through non-Entry values, got %v", result)
+		}
+		if result := upper.OnWrite(nil); result != nil {
+			t.Errorf("OnWrite(nil) should return nil, got %v", result)
+		}
+	})
+}
+
 func TestHookInterface(t *testing.T) {
 	t.Run("HookFunc_implements_Hook", func(t *testing.T) {
 		var _ Hook = HookFunc(nil) // Compile-time check
+		var _ Hook = EntryHookFunc(nil)
+		var _ EntryHook = EntryHookFunc(nil)
 	})
 }
 
@@ -2,6 +2,8 @@
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 )
 
 // Level represents log level, compatible with logrus
@@ -71,3 +73,64 @@ func (level Level) MarshalText() ([]byte, error) {
 	}
 	return nil, fmt.Errorf("invalid logrus level %d", level)
 }
+
+// levelNames maps accepted level names and aliases to levels
+var levelNames = map[string]Level{
+	"panic":    PanicLevel,
+	"fatal":    FatalLevel,
+	"crit":     FatalLevel,
+	"critical": FatalLevel,
+	"error":    ErrorLevel,
+	"err":      ErrorLevel,
+	"warn":     WarnLevel,
+	"warning":  WarnLevel,
+	"info":     InfoLevel,
+	"debug":    DebugLevel,
+	"trace":    TraceLevel,
+}
+
+// ParseLevel converts a level name, alias or number to Level, ignoring case
+// and surrounding spaces. Accepted aliases are warning, err, crit and critical;
+// numbers are the Level values, 0 (panic) to 6 (trace).
+func ParseLevel(text string) (Level, error) {
+	name := strings.ToLower(strings.TrimSpace(text))
+	if level, ok := levelNames[name]; ok {
+		return level, nil
+	}
+
+	if n, err := strconv.ParseUint(name, 10, 32); err == nil && n <= uint64(TraceLevel) {
+		return Level(n), nil
+	}
+
+	return 0, fmt.Errorf("not a valid log level: %q", text)
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler using ParseLevel
+func (level *Level) UnmarshalText(text []byte) error {
+	l, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	*level = l
+	return nil
+}
+
+// UnmarshalJSON implements json.Unmarshaler, accepting a level name or number
+func (level *Level) UnmarshalJSON(data []byte) error {
+	text := strings.TrimSpace(string(data))
+	if text == "null" {
+		return nil
+	}
+	if unquoted, err := strconv.Unquote(text); err == nil {
+		text = unquoted
+	}
+	return level.UnmarshalText([]byte(text))
+}
+
+// Set implements flag.Value, so a Level can be bound to a command-line flag
+//
+//	level := constant.InfoLevel
+//	flag.Var(&level, "log-level", "trace, debug, info, warn, error, fatal or panic")
+func (level *Level) Set(text string) error {
+	return level.UnmarshalText([]byte(text))
+}
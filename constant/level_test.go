@@ -0,0 +1,120 @@
+package constant
+
+import (
+	"encoding/json"
+	"flag"
+	"testing"
+)
+
+func TestParseLevel(t *testing.T) {
+	tests := []struct {
+		text string
+		want Level
+	}{
+		{"trace", TraceLevel},
+		{"DEBUG", DebugLevel},
+		{" Info ", InfoLevel},
+		{"warn", WarnLevel},
+		{"Warning", WarnLevel},
+		{"error", ErrorLevel},
+		{"ERR", ErrorLevel},
+		{"fatal", FatalLevel},
+		{"crit", FatalLevel},
+		{"critical", FatalLevel},
+		{"panic", PanicLevel},
+		{"0", PanicLevel},
+		{"4", InfoLevel},
+		{"6", TraceLevel},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseLevel(tt.text)
+		if err != nil {
+			t.Errorf("ParseLevel(%q) returned error: %v", tt.text, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseLevel(%q) = %v, want %v", tt.text, got, tt.want)
+		}
+	}
+
+	for _, text := range []string{"", "verbose", "7", "-1", "1.5"} {
+		if _, err := ParseLevel(text); err == nil {
+			t.Errorf("ParseLevel(%q) should fail", text)
+		}
+	}
+}
+
+func TestParseLevel_RoundTrip(t *testing.T) {
+	for level := PanicLevel; level <= TraceLevel; level++ {
+		text, err := level.MarshalText()
+		if err != nil {
+			t.Fatalf("MarshalText(%d): %v", level, err)
+		}
+		got, err := ParseLevel(string(text))
+		if err != nil || got != level {
+			t.Errorf("round trip of %v gave %v, %v", level, got, err)
+		}
+	}
+}
+
+func TestLevel_UnmarshalJSON(t *testing.T) {
+	var cfg struct {
+		Level Level `json:"level"`
+	}
+
+	for _, tt := range []struct {
+		data string
+		want Level
+	}{
+		{`{"level":"warning"}`, WarnLevel},
+		{`{"level":"DEBUG"}`, DebugLevel},
+		{`{"level":2}`, ErrorLevel},
+		{`{"level":"6"}`, TraceLevel},
+	} {
+		if err := json.Unmarshal([]byte(tt.data), &cfg); err != nil {
+			t.Errorf("Unmarshal(%s): %v", tt.data, err)
+			continue
+		}
+		if cfg.Level != tt.want {
+			t.Errorf("Unmarshal(%s) = %v, want %v", tt.data, cfg.Level, tt.want)
+		}
+	}
+
+	cfg.Level = InfoLevel
+	if err := json.Unmarshal([]byte(`{"level":null}`), &cfg); err != nil || cfg.Level != InfoLevel {
+		t.Errorf("null should leave the level unchanged, got %v, %v", cfg.Level, err)
+	}
+	if err := json.Unmarshal([]byte(`{"level":"loud"}`), &cfg); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+
+	data, err := json.Marshal(struct {
+		Level Level `json:"level"`
+	}{ErrorLevel})
+	if err != nil || string(data) != `{"level":"error"}` {
+		t.Errorf("Marshal = %s, %v", data, err)
+	}
+}
+
+func TestLevel_FlagValue(t *testing.T) {
+	level := InfoLevel
+	fs := flag.NewFlagSet("test", flag.ContinueOnError)
+	fs.Var(&level, "log-level", "log level")
+
+	if err := fs.Parse([]string{"-log-level", "err"}); err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+	if level != ErrorLevel {
+		t.Errorf("expected ErrorLevel, got %v", level)
+	}
+
+	fs.SetOutput(discard{})
+	if err := fs.Parse([]string{"-log-level", "nope"}); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+}
+
+type discard struct{}
+
+func (discard) Write(p []byte) (int, error) { return len(p), nil }
@@ -0,0 +1,101 @@
+package log
+
+import "context"
+
+type (
+	loggerCtxKey struct{}
+	traceCtxKey  struct{}
+	fieldsCtxKey struct{}
+)
+
+// ContextExtractor pulls request-scoped values (trace id, request id, tenant, ...)
+// out of a context into the entry being logged.
+//
+// Extractors run for the *Context logging methods after the entry has been
+// populated. They should only append to entry.Fields, never modify existing
+// elements in place, since those may be shared with the logger's bound fields.
+type ContextExtractor func(ctx context.Context, entry *Entry)
+
+// NewContext returns a copy of ctx carrying logger, retrievable with FromContext
+func NewContext(ctx context.Context, logger *Logger) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, loggerCtxKey{}, logger)
+}
+
+// FromContext returns the logger stored in ctx by NewContext,
+// or the standard logger if there is none
+func FromContext(ctx context.Context) *Logger {
+	if ctx != nil {
+		if l, ok := ctx.Value(loggerCtxKey{}).(*Logger); ok && l != nil {
+			return l
+		}
+	}
+	return std
+}
+
+// ContextWithTrace returns a copy of ctx carrying traceId.
+// Entries logged with the context use it instead of the goroutine trace ID.
+func ContextWithTrace(ctx context.Context, traceId string) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return context.WithValue(ctx, traceCtxKey{}, traceId)
+}
+
+// TraceFromContext returns the trace ID stored in ctx by ContextWithTrace
+func TraceFromContext(ctx context.Context) string {
+	if ctx == nil {
+		return ""
+	}
+	traceId, _ := ctx.Value(traceCtxKey{}).(string)
+	return traceId
+}
+
+// ContextWithFields returns a copy of ctx carrying key-value pairs that are
+// appended to every entry logged with the context. Fields already present in
+// ctx are kept.
+func ContextWithFields(ctx context.Context, kv ...interface{}) context.Context {
+	var parent []KV
+	if ctx == nil {
+		ctx = context.Background()
+	} else {
+		parent, _ = ctx.Value(fieldsCtxKey{}).([]KV)
+	}
+
+	fields := make([]KV, 0, len(parent)+(len(kv)+1)/2)
+	fields = append(fields, parent...)
+	fields = appendKVs(fields, kv...)
+
+	return context.WithValue(ctx, fieldsCtxKey{}, fields)
+}
+
+// AddContextExtractor registers extractors run for every *Context logging call
+func (p *Logger) AddContextExtractor(extractors ...ContextExtractor) *Logger {
+	return p.update(func(c *loggerConfig) {
+		c.ctxExtractors = append(c.ctxExtractors[:len(c.ctxExtractors):len(c.ctxExtractors)], extractors...)
+	})
+}
+
+// fillContext copies trace ID and fields carried by ctx into the entry,
+// then runs the registered extractors
+func (c *loggerConfig) fillContext(ctx context.Context, entry *Entry) {
+	if ctx == nil {
+		return
+	}
+
+	if c.enableTrace {
+		if traceId, ok := ctx.Value(traceCtxKey{}).(string); ok && traceId != "" {
+			entry.TraceId = traceId
+		}
+	}
+
+	if fields, ok := ctx.Value(fieldsCtxKey{}).([]KV); ok && len(fields) > 0 {
+		entry.Fields = append(entry.Fields, fields...)
+	}
+
+	for _, extract := range c.ctxExtractors {
+		extract(ctx, entry)
+	}
+}
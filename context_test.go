@@ -0,0 +1,146 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+)
+
+type tenantCtxKey struct{}
+
+func TestNewContext_FromContext(t *testing.T) {
+	t.Run("returns_stored_logger", func(t *testing.T) {
+		logger := New()
+		ctx := NewContext(context.Background(), logger)
+		if FromContext(ctx) != logger {
+			t.Error("FromContext should return the logger stored by NewContext")
+		}
+	})
+
+	t.Run("falls_back_to_std", func(t *testing.T) {
+		if FromContext(context.Background()) != std {
+			t.Error("FromContext should fall back to the standard logger")
+		}
+		//nolint:staticcheck // nil context is handled explicitly
+		if FromContext(nil) != std {
+			t.Error("FromContext(nil) should fall back to the standard logger")
+		}
+	})
+
+	t.Run("nil_logger_ignored", func(t *testing.T) {
+		ctx := NewContext(context.Background(), nil)
+		if FromContext(ctx) != std {
+			t.Error("a nil logger in context should fall back to the standard logger")
+		}
+	})
+}
+
+func TestContextWithTrace(t *testing.T) {
+	ctx := ContextWithTrace(context.Background(), "trace-abc")
+	if got := TraceFromContext(ctx); got != "trace-abc" {
+		t.Errorf("TraceFromContext = %q, want trace-abc", got)
+	}
+	if got := TraceFromContext(context.Background()); got != "" {
+		t.Errorf("TraceFromContext on empty context = %q, want empty", got)
+	}
+}
+
+func TestContextWithFields(t *testing.T) {
+	parent := ContextWithFields(context.Background(), "request_id", "r1")
+	child := ContextWithFields(parent, "tenant", "t1")
+
+	fields, _ := child.Value(fieldsCtxKey{}).([]KV)
+	if len(fields) != 2 || fields[0].Key != "request_id" || fields[1].Key != "tenant" {
+		t.Errorf("child context should accumulate fields, got %v", fields)
+	}
+
+	parentFields, _ := parent.Value(fieldsCtxKey{}).([]KV)
+	if len(parentFields) != 1 {
+		t.Errorf("parent context fields should be untouched, got %v", parentFields)
+	}
+}
+
+func TestContextWith_NilContext(t *testing.T) {
+	//nolint:staticcheck // nil context is handled explicitly
+	ctx := ContextWithFields(nil, "request_id", "r1")
+	if fields, _ := ctx.Value(fieldsCtxKey{}).([]KV); len(fields) != 1 {
+		t.Errorf("ContextWithFields(nil) should start from an empty context, got %v", fields)
+	}
+	//nolint:staticcheck // nil context is handled explicitly
+	if got := TraceFromContext(ContextWithTrace(nil, "t")); got != "t" {
+		t.Errorf("ContextWithTrace(nil) trace = %q, want t", got)
+	}
+	//nolint:staticcheck // nil context is handled explicitly
+	if FromContext(NewContext(nil, std)) != std {
+		t.Error("NewContext(nil) should carry the logger")
+	}
+}
+
+func TestLogger_FillContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.EnableCaller(false)
+	logger.AddContextExtractor(func(ctx context.Context, entry *Entry) {
+		if tenant, ok := ctx.Value(tenantCtxKey{}).(string); ok {
+			entry.Fields = append(entry.Fields, KV{Key: "tenant", Value: tenant})
+		}
+	})
+
+	ctx := ContextWithTrace(context.Background(), "ctx-trace")
+	ctx = ContextWithFields(ctx, "request_id", "r42")
+	ctx = context.WithValue(ctx, tenantCtxKey{}, "acme")
+
+	logger.With("svc", "api").InfoContext(ctx, "hello")
+
+	out := buf.String()
+	if !strings.Contains(out, "svc=api request_id=r42 tenant=acme") {
+		t.Errorf("expected bound, context and extracted fields in order, got %q", out)
+	}
+	if !strings.Contains(out, "ctx-trace") {
+		t.Errorf("expected trace id from context, got %q", out)
+	}
+}
+
+func TestLogger_FillContext_TraceDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.EnableCaller(false)
+	logger.EnableTrace(false)
+
+	logger.InfoContext(ContextWithTrace(context.Background(), "ctx-trace"), "hello")
+
+	if strings.Contains(buf.String(), "ctx-trace") {
+		t.Errorf("context trace should respect EnableTrace(false), got %q", buf.String())
+	}
+}
+
+func TestLogger_FillContext_NilContext(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+
+	//nolint:staticcheck // nil context is handled explicitly
+	logger.InfoContext(nil, "nil ctx")
+
+	if !strings.Contains(buf.String(), "nil ctx") {
+		t.Errorf("nil context should still log, got %q", buf.String())
+	}
+}
+
+func TestLogger_AddContextExtractor_Clone(t *testing.T) {
+	logger := New()
+	logger.AddContextExtractor(func(context.Context, *Entry) {})
+
+	cloned := logger.Clone()
+	if len(cloned.config().ctxExtractors) != 1 {
+		t.Fatalf("Clone should copy context extractors, got %d", len(cloned.config().ctxExtractors))
+	}
+
+	cloned.AddContextExtractor(func(context.Context, *Entry) {})
+	if len(logger.config().ctxExtractors) != 1 {
+		t.Error("adding extractors to a clone should not affect the original")
+	}
+}
@@ -0,0 +1,322 @@
+package log
+
+import (
+	"sync"
+	"time"
+)
+
+// Deduper collapses repeated entries.
+//
+// Entries are identical when they share level, message and field keys (field
+// values may differ). The first entry of a window is written as usual; the
+// duplicates that follow within the window are held back and written as a
+// single line, the latest duplicate annotated with repeated=N (the number of
+// collapsed entries), first (when the window opened) and last (the time of
+// the latest duplicate). The line is written when the window closes, on Flush
+// and on Logger.Sync, outside the lock shared by the loggers.
+//
+// Windows are measured with the clock of the logger (see Logger.SetClock);
+// closed windows are noticed by the next duplicate or by a background check
+// running every half window of real time while windows are open.
+//
+// Panic and fatal entries are never held back. A Deduper can be shared by
+// several loggers; collapsed lines go to the logger they were logged with,
+// behind the entries queued by SetAsync. Close or Shutdown writes the pending
+// lines and stops the Deduper.
+//
+//	logger.SetDeduper(log.NewDeduper(10 * time.Second))
+type Deduper struct {
+	window time.Duration
+
+	mu      sync.Mutex
+	records map[uint64]*dedupRecord
+
+	// running is set while the background check runs, closed once Close is called
+	running bool
+	closed  bool
+	done    chan struct{}
+}
+
+// dedupRecord tracks one kind of entry within its window
+type dedupRecord struct {
+	level   Level
+	message string
+	keys    []string
+
+	start time.Time
+	// clock is the clock of the logger that opened the window, nil for the wall clock
+	clock Clock
+
+	// repeated counts the duplicates held back since start
+	repeated int
+
+	// last is a copy of the latest duplicate, written out by flush
+	last Entry
+	cfg  *loggerConfig
+}
+
+// NewDeduper creates a Deduper collapsing duplicates within window
+func NewDeduper(window time.Duration) *Deduper {
+	return &Deduper{
+		window:  window,
+		records: make(map[uint64]*dedupRecord),
+		done:    make(chan struct{}),
+	}
+}
+
+// admit reports whether entry should be written now.
+// Duplicates are copied into their record and reported as held back.
+func (d *Deduper) admit(c *loggerConfig, entry *Entry) bool {
+	if entry.Level <= FatalLevel {
+		return true
+	}
+	now := entry.Time
+	if now.IsZero() {
+		now = c.now()
+	}
+	key := dedupKey(entry)
+
+	d.mu.Lock()
+	if d.closed {
+		d.mu.Unlock()
+		return true
+	}
+	r, ok := d.records[key]
+	if ok && !r.matches(entry) {
+		// Hash collision, let it through untracked
+		d.mu.Unlock()
+		return true
+	}
+	if ok && now.Sub(r.start) < d.window {
+		r.repeated++
+		r.store(c, entry)
+		d.mu.Unlock()
+		return false
+	}
+
+	var pending []dedupSummary
+	if ok {
+		pending = r.summarize(pending)
+	} else {
+		r = &dedupRecord{level: entry.Level, message: entry.Message, keys: fieldKeys(entry)}
+		d.records[key] = r
+		d.startLocked()
+	}
+	r.start = now
+	r.clock = c.clock
+	d.mu.Unlock()
+
+	// The closed window is summed up before the entry opening the next one
+	writeSummaries(pending)
+	return true
+}
+
+// startLocked starts the goroutine flushing records whose window has closed,
+// unless it runs already. It stops once no window is open.
+// Must be called with d.mu held.
+func (d *Deduper) startLocked() {
+	if d.running {
+		return
+	}
+	d.running = true
+	registerDeduper(d)
+
+	interval := d.window / 2
+	if interval <= 0 {
+		interval = time.Millisecond
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if !d.flushExpired() {
+					return
+				}
+			case <-d.done:
+				return
+			}
+		}
+	}()
+}
+
+// flushExpired writes and forgets records whose window has closed by now,
+// as told by the clock of the logger that opened it. It reports whether
+// windows are still open; if not, the background check is marked stopped.
+func (d *Deduper) flushExpired() bool {
+	var pending []dedupSummary
+
+	d.mu.Lock()
+	for key, r := range d.records {
+		if r.now().Sub(r.start) >= d.window {
+			pending = r.summarize(pending)
+			delete(d.records, key)
+		}
+	}
+	open := len(d.records) > 0
+	if !open && d.running {
+		d.running = false
+		unregisterDeduper(d)
+	}
+	d.mu.Unlock()
+
+	writeSummaries(pending)
+	return open
+}
+
+// Flush writes the collapsed lines of every open window.
+// The windows stay open, later duplicates are counted from zero.
+func (d *Deduper) Flush() {
+	var pending []dedupSummary
+
+	d.mu.Lock()
+	for _, r := range d.records {
+		pending = r.summarize(pending)
+	}
+	d.mu.Unlock()
+
+	writeSummaries(pending)
+	// Wait for the lines queued behind the entries of async loggers
+	for i := range pending {
+		if a := pending[i].cfg.async; a != nil {
+			a.flush()
+		}
+	}
+}
+
+// Close flushes pending lines and stops the background check; later entries
+// are written as they come. Shutdown closes the Dedupers in use as well.
+func (d *Deduper) Close() error {
+	d.mu.Lock()
+	if !d.closed {
+		d.closed = true
+		d.running = false
+		close(d.done)
+		unregisterDeduper(d)
+	}
+	d.mu.Unlock()
+
+	d.Flush()
+	return nil
+}
+
+// now returns the time from the clock of r
+func (r *dedupRecord) now() time.Time {
+	if r.clock == nil {
+		return time.Now()
+	}
+	return r.clock.Now()
+}
+
+// matches reports whether entry is of the kind tracked by r
+func (r *dedupRecord) matches(entry *Entry) bool {
+	if r.level != entry.Level || r.message != entry.Message || len(r.keys) != len(entry.Fields) {
+		return false
+	}
+	for i, kv := range entry.Fields {
+		if r.keys[i] != kv.Key {
+			return false
+		}
+	}
+	return true
+}
+
+// store keeps a copy of entry as the latest duplicate, reusing r's buffers
+func (r *dedupRecord) store(c *loggerConfig, entry *Entry) {
+	fields := append(r.last.Fields[:0], entry.Fields...)
+	r.last = *entry
+	r.last.Fields = fields
+	r.cfg = c
+}
+
+// dedupSummary is a collapsed line waiting to be written outside the lock
+type dedupSummary struct {
+	cfg   *loggerConfig
+	entry Entry
+}
+
+// summarize appends the collapsed line of the held back duplicates to dst
+// and starts counting from zero
+func (r *dedupRecord) summarize(dst []dedupSummary) []dedupSummary {
+	if r.repeated == 0 {
+		return dst
+	}
+
+	e := r.last
+	// Appending past the capped length copies the fields, so store can
+	// reuse r.last.Fields while the line is written
+	e.Fields = append(e.Fields[:len(e.Fields):len(e.Fields)],
+		KV{Key: "repeated", Value: r.repeated},
+		KV{Key: "first", Value: r.start.Format(time.RFC3339Nano)},
+		KV{Key: "last", Value: e.Time.Format(time.RFC3339Nano)},
+	)
+	dst = append(dst, dedupSummary{cfg: r.cfg, entry: e})
+
+	r.repeated = 0
+	r.cfg = nil
+	return dst
+}
+
+// writeSummaries writes collapsed lines through their logger, queued behind
+// the logger's entries when it is async
+func writeSummaries(pending []dedupSummary) {
+	for i := range pending {
+		s := &pending[i]
+		if s.cfg.async != nil && s.cfg.async.enqueueSummary(s.cfg, &s.entry) {
+			continue
+		}
+		s.cfg.writeEntry(&s.entry)
+	}
+}
+
+// dedupKey hashes level, message and field keys with 64-bit FNV-1a
+func dedupKey(entry *Entry) uint64 {
+	const (
+		offset64 = 14695981039346656037
+		prime64  = 1099511628211
+	)
+	h := uint64(offset64)
+	add := func(s string) {
+		for i := 0; i < len(s); i++ {
+			h ^= uint64(s[i])
+			h *= prime64
+		}
+		// Separator so ("ab","c") and ("a","bc") differ
+		h ^= 0xff
+		h *= prime64
+	}
+
+	h ^= uint64(entry.Level)
+	h *= prime64
+	add(entry.Message)
+	for _, kv := range entry.Fields {
+		add(kv.Key)
+	}
+	return h
+}
+
+// fieldKeys returns the keys of entry's fields
+func fieldKeys(entry *Entry) []string {
+	if len(entry.Fields) == 0 {
+		return nil
+	}
+	keys := make([]string, len(entry.Fields))
+	for i, kv := range entry.Fields {
+		keys[i] = kv.Key
+	}
+	return keys
+}
+
+// SetDeduper attaches d to the logger, nil removes deduplication
+func (p *Logger) SetDeduper(d *Deduper) *Logger {
+	return p.update(func(c *loggerConfig) {
+		c.dedup = d
+	})
+}
+
+// Deduper returns the deduper attached to the logger, if any
+func (p *Logger) Deduper() *Deduper {
+	return p.config().dedup
+}
@@ -0,0 +1,277 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestDeduper_CollapsesOnSync(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := newTestLogger(&buf).SetDeduper(d)
+
+	for i := 0; i < 5; i++ {
+		logger.Errorw("upstream failed", "attempt", i)
+	}
+	if got := strings.Count(buf.String(), "upstream failed"); got != 1 {
+		t.Fatalf("duplicates should be held back, got %d lines: %q", got, buf.String())
+	}
+
+	logger.Sync()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected first line plus one collapsed line, got %q", buf.String())
+	}
+	if !strings.Contains(lines[1], "attempt=4 repeated=4 first=") || !strings.Contains(lines[1], " last=") {
+		t.Errorf("collapsed line should carry the latest fields and counts, got %q", lines[1])
+	}
+
+	// Nothing left to flush
+	logger.Sync()
+	if got := strings.Count(buf.String(), "\n"); got != 2 {
+		t.Errorf("Sync should not repeat collapsed lines, got %q", buf.String())
+	}
+}
+
+func TestDeduper_Key(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := newTestLogger(&buf).SetDeduper(d)
+
+	logger.Errorw("msg", "a", 1)
+	logger.Errorw("msg", "b", 1)      // different field keys
+	logger.Warnw("msg", "a", 1)       // different level
+	logger.Errorw("other", "a", 1)    // different message
+	logger.Errorw("msg", "a", 2)      // duplicate
+	logger.Errorw("msg", "a", 3, "b") // extra key
+
+	if got := strings.Count(buf.String(), "\n"); got != 5 {
+		t.Errorf("expected 5 distinct lines, got %d: %q", got, buf.String())
+	}
+}
+
+func TestDeduper_WindowCloses(t *testing.T) {
+	out := &lockedBuffer{}
+	d := NewDeduper(20 * time.Millisecond)
+	defer d.Close()
+	logger := newTestLogger(out).SetDeduper(d)
+
+	logger.Error("flapping")
+	logger.Error("flapping")
+	logger.Error("flapping")
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !strings.Contains(out.String(), "repeated=2") {
+		if time.Now().After(deadline) {
+			t.Fatalf("collapsed line not written when the window closed, got %q", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	// A new window starts with a fresh line
+	logger.Error("flapping")
+	if got := strings.Count(out.String(), "flapping"); got != 3 {
+		t.Errorf("expected a new first line after the window, got %q", out.String())
+	}
+}
+
+func TestDeduper_ExpiredWindowFlushedInOrder(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := newTestLogger(&buf).SetDeduper(d)
+
+	logger.Error("again")
+	logger.Error("again")
+
+	// Move the window back instead of waiting for it
+	d.mu.Lock()
+	for _, r := range d.records {
+		r.start = r.start.Add(-2 * time.Hour)
+	}
+	d.mu.Unlock()
+
+	logger.Error("again")
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.Contains(lines[1], "repeated=1") || strings.Contains(lines[2], "repeated") {
+		t.Errorf("collapsed line should precede the first line of the next window, got %q", buf.String())
+	}
+}
+
+func TestDeduper_PanicNotHeld(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := newTestLogger(&buf).SetDeduper(d)
+
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("panic entries must not be held back")
+				}
+			}()
+			logger.Panic("boom")
+		}()
+	}
+}
+
+func TestDeduper_BoundFields(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := newTestLogger(&buf).SetDeduper(d).With("svc", "api")
+
+	logger.Info("ready")
+	logger.Info("ready")
+	logger.Sync()
+
+	if !strings.Contains(buf.String(), "svc=api repeated=1") {
+		t.Errorf("collapsed line should keep bound fields, got %q", buf.String())
+	}
+}
+
+func TestDeduper_AsyncWriter(t *testing.T) {
+	out := &lockedBuffer{}
+	async := NewAsyncWriter(nopCloser{out})
+	d := NewDeduper(time.Hour)
+	logger := newTestLogger(async).SetDeduper(d)
+
+	for i := 0; i < 10; i++ {
+		logger.Warn("slow disk")
+	}
+	_ = d.Close()
+	_ = async.Close()
+
+	if got := out.String(); strings.Count(got, "slow disk") != 2 || !strings.Contains(got, "repeated=9") {
+		t.Errorf("unexpected output %q", got)
+	}
+}
+
+// reentrantWriter logs through log on its first write
+type reentrantWriter struct {
+	bytes.Buffer
+	log  func()
+	done bool
+}
+
+func (w *reentrantWriter) Write(p []byte) (int, error) {
+	if !w.done && bytes.Contains(p, []byte("repeated=")) {
+		w.done = true
+		w.log()
+	}
+	return w.Buffer.Write(p)
+}
+
+func TestDeduper_WritesOutsideLock(t *testing.T) {
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	var other bytes.Buffer
+	w := &reentrantWriter{log: func() {
+		// Would deadlock if the collapsed line were written under the lock
+		newTestLogger(&other).SetDeduper(d).Warn("from the writer")
+	}}
+	logger := newTestLogger(w).SetDeduper(d)
+
+	logger.Error("busy")
+	logger.Error("busy")
+
+	done := make(chan struct{})
+	go func() {
+		d.Flush()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Flush should write outside the lock")
+	}
+
+	if !strings.Contains(other.String(), "from the writer") || !strings.Contains(w.String(), "repeated=1") {
+		t.Errorf("unexpected output %q and %q", w.String(), other.String())
+	}
+}
+
+type nopCloser struct {
+	*lockedBuffer
+}
+
+func (nopCloser) Close() error { return nil }
+
+func TestDeduper_StopsWhenIdle(t *testing.T) {
+	var buf bytes.Buffer
+	d := NewDeduper(time.Millisecond)
+	defer d.Close()
+	logger := newTestLogger(&buf).SetDeduper(d)
+
+	logger.Info("once")
+
+	deadline := time.Now().Add(time.Second)
+	for {
+		d.mu.Lock()
+		running := d.running
+		d.mu.Unlock()
+		if !running {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("background check should stop once no window is open")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	asyncMutex.Lock()
+	_, registered := deduperInstances[d]
+	asyncMutex.Unlock()
+	if registered {
+		t.Error("an idle Deduper should leave the shutdown registry")
+	}
+}
+
+// gatedWriter blocks writes of lines containing gate until release is closed
+type gatedWriter struct {
+	lockedBuffer
+	gate    string
+	release chan struct{}
+}
+
+func (w *gatedWriter) Write(p []byte) (int, error) {
+	if bytes.Contains(p, []byte(w.gate)) {
+		<-w.release
+	}
+	return w.lockedBuffer.Write(p)
+}
+
+func TestDeduper_SummaryQueuedBehindAsync(t *testing.T) {
+	out := &gatedWriter{gate: "second", release: make(chan struct{})}
+	d := NewDeduper(time.Hour)
+	defer d.Close()
+	logger := newTestLogger(out).SetDeduper(d).SetAsync(16)
+	defer logger.SetAsync(0)
+
+	logger.Info("first")
+	logger.Info("first")
+	logger.config().async.flush()
+
+	// The worker is stuck on "second" while "third" waits in the queue
+	logger.Info("second")
+	logger.Info("third")
+	flushed := make(chan struct{})
+	go func() {
+		d.Flush()
+		close(flushed)
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(out.release)
+	<-flushed
+
+	lines := strings.Split(strings.TrimSpace(out.String()), "\n")
+	if len(lines) != 4 || !strings.Contains(lines[2], "third") || !strings.Contains(lines[3], "repeated=1") {
+		t.Errorf("collapsed line should follow the queued entries, got %q", lines)
+	}
+}
@@ -6,8 +6,9 @@
 // # Main Types
 //
 // Logger - The core logging type with methods for all log levels
-// LoggerWithCtx - A context-aware logger that supports context.Context
-// HourlyRotator - Automatic log file rotation by hour and size
+// Rotator - Log file rotation by time period (minutely, hourly, daily, weekly
+// or custom) and size; HourlyRotator is the hourly variant
+// ReopenWriter - File writer reopening its path for external rotation (SIGHUP)
 // AsyncWriter - Asynchronous buffered writer for high-throughput scenarios
 //
 // # Log Levels
@@ -21,6 +22,26 @@
 //   - PanicLevel (logs then panics)
 //   - FatalLevel (logs then os.Exit(1))
 //
+// ParseLevel reads a level from a name, alias (warning, err, crit) or number.
+// Level implements encoding.TextUnmarshaler, json.Unmarshaler and flag.Value,
+// and the LOG_LEVEL environment variable sets the standard logger's level.
+//
+// Levels can be overridden per package with glob rules matched against the
+// caller's package (see ModuleLevels), at runtime with SetModuleLevels or
+// through the LOG_MODULES environment variable. Here ourapp/storage and all
+// its sub-packages log at trace:
+//
+//	LOG_MODULES=ourapp/storage/*=trace,*=info
+//
+// The level of a Logger is held by an AtomicLevel, which is shared with the
+// loggers derived by With and WithFields, can be shared by several loggers
+// (Logger.SetAtomicLevel) and mounted as an http.Handler to
+// read or change the level of a running service, optionally reverting after
+// a duration:
+//
+//	http.Handle("/log/level", log.GetAtomicLevel())
+//	// curl -X PUT -d '{"level":"debug","duration":"10m"}' localhost:8080/log/level
+//
 // # Build Tags
 //
 // The package supports conditional compilation for different environments:
@@ -32,8 +53,15 @@
 // # Thread Safety
 //
 // All logger types are safe for concurrent use by multiple goroutines.
+// Setters such as SetLevel, SetOutput, SetFormatter and AddHook may be called
+// while other goroutines are logging: the configuration is published as an
+// immutable snapshot, so the logging path stays lock-free.
 // The AsyncWriter uses internal buffering and goroutines for optimal
-// performance in high-concurrency scenarios.
+// performance in high-concurrency scenarios. Its buffer is bounded in entries
+// and bytes; NewAsyncWriterWithConfig selects what happens when it is full
+// (reject, block, block with timeout, drop oldest or drop below a level) and
+// Stats reports written, dropped and blocked counts. Sync waits for the
+// entries written before it and syncs the underlying writer.
 //
 // # Basic Usage
 //
@@ -54,13 +82,35 @@
 //
 // # Context-Aware Logging
 //
-//	ctx := context.Background()
-//	logger := log.NewLoggerWithCtx()
-//	logger.Log(ctx, log.InfoLevel, "message with context")
+// Every logging method has a *Context variant (InfoContext, InfofContext,
+// InfowContext, ...) that pulls the trace ID and fields carried by the context,
+// plus anything registered with AddContextExtractor, into the entry:
+//
+//	ctx := log.NewContext(context.Background(), logger)
+//	ctx = log.ContextWithFields(ctx, "request_id", id)
+//	log.InfoContext(ctx, "message with context")
+//
+// # log/slog Integration
+//
+// SlogHandler routes standard library slog records into a Logger, keeping its
+// formatter, hooks, trace IDs and outputs:
+//
+//	slog.SetDefault(log.NewSlogLogger(log.New()))
 //
 // # Performance
 //
 // This library uses sync.Pool for entry reuse, inline optimizations,
 // and zero-allocation fast paths for common operations, achieving
 // sub-microsecond latency for most logging operations.
+//
+// Logger.SetAsync moves formatting and writing to a background worker: the
+// caller only captures the entry, including its caller's program counter.
+// Entries keep their order, Sync waits for the queue and Panic and Fatal
+// entries are written synchronously.
+//
+// Hot loops can be protected with a Sampler, which keeps the first entries per
+// level and message within each tick and then only every Mth one. Dropped
+// entries never reach hooks or the formatter and are counted per level.
+// A Deduper collapses identical entries within a window into one line
+// annotated with repeated=N, written when the window closes or on Sync.
 package log
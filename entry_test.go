@@ -46,6 +46,7 @@ func TestEntry_Reset(t *testing.T) {
 	entry.CallerFunc = "main.test"
 	entry.PrefixMsg = []byte("prefix")
 	entry.SuffixMsg = []byte("suffix")
+	entry.Fields = []KV{{Key: "k", Value: "v"}}
 	entry.Time = time.Now()
 	entry.Level = InfoLevel
 	entry.CallerLine = 42
@@ -90,6 +91,10 @@ func TestEntry_Reset(t *testing.T) {
 		t.Errorf("Expected empty SuffixMsg after reset, got %v", entry.SuffixMsg)
 	}
 
+	if len(entry.Fields) != 0 {
+		t.Errorf("Expected empty Fields after reset, got %v", entry.Fields)
+	}
+
 	// 验证 Reset 不会改变 Pid、Time、Level、CallerLine
 	if entry.Pid != pid {
 		t.Errorf("Reset should not change Pid, expected %d, got %d", pid, entry.Pid)
@@ -6,6 +6,9 @@
 )
 
 // init sets the log level based on the APP_ENV environment variable.
+// LOG_LEVEL, when set to a level accepted by ParseLevel, takes precedence.
+// LOG_MODULES sets per-package overrides, e.g. LOG_MODULES=ourapp/storage/*=trace,*=info
+// for ourapp/storage and its sub-packages.
 func init() {
 	switch strings.ToLower(os.Getenv("APP_ENV")) {
 	case "dev", "development":
@@ -15,4 +18,14 @@ func init() {
 	case "prod", "release", "production":
 		SetLevel(InfoLevel)
 	}
+
+	if level, err := ParseLevel(os.Getenv("LOG_LEVEL")); err == nil {
+		SetLevel(level)
+	}
+
+	if spec := os.Getenv("LOG_MODULES"); spec != "" {
+		if err := SetModuleLevels(spec); err != nil {
+			Errorf("invalid LOG_MODULES: %v", err)
+		}
+	}
 }
@@ -16,9 +16,16 @@ type envTestResult struct {
 // runEnvSubprocess runs the current test binary in a subprocess with given APP_ENV,
 // and returns the log level that was set after init().
 func runEnvSubprocess(t *testing.T, testName, appEnv string) Level {
+	t.Helper()
+	return runEnvSubprocessWith(t, testName, "APP_ENV="+appEnv)
+}
+
+// runEnvSubprocessWith runs the current test binary in a subprocess with the given
+// extra environment, and returns the log level that was set after init().
+func runEnvSubprocessWith(t *testing.T, testName string, env ...string) Level {
 	t.Helper()
 	cmd := exec.Command(os.Args[0], "-test.run=^"+testName+"$")
-	cmd.Env = append(os.Environ(), "TEST_ENV_INIT=1", "APP_ENV="+appEnv)
+	cmd.Env = append(append(os.Environ(), "TEST_ENV_INIT=1", "LOG_LEVEL=", "LOG_MODULES="), env...)
 	cmd.Stderr = io.Discard
 	out, err := cmd.Output()
 	if err != nil {
@@ -152,3 +159,50 @@ func TestEnvInit_DefaultEnvironment(t *testing.T) {
 		})
 	}
 }
+
+func TestEnvInit_LogLevel(t *testing.T) {
+	if os.Getenv("TEST_ENV_INIT") == "1" {
+		result := envTestResult{Level: int(GetLevel())}
+		data, _ := json.Marshal(result)
+		os.Stdout.Write(data)
+		os.Exit(0)
+		return
+	}
+
+	tests := []struct {
+		name      string
+		env       []string
+		wantLevel Level
+	}{
+		{"name", []string{"LOG_LEVEL=warn"}, WarnLevel},
+		{"alias", []string{"LOG_LEVEL=ERR"}, ErrorLevel},
+		{"number", []string{"LOG_LEVEL=6"}, TraceLevel},
+		{"overrides_app_env", []string{"APP_ENV=prod", "LOG_LEVEL=debug"}, DebugLevel},
+		{"invalid_ignored", []string{"APP_ENV=prod", "LOG_LEVEL=loud"}, InfoLevel},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runEnvSubprocessWith(t, "TestEnvInit_LogLevel", tt.env...)
+			if got != tt.wantLevel {
+				t.Errorf("%v: expected level %v, got %v", tt.env, tt.wantLevel, got)
+			}
+		})
+	}
+}
+
+func TestEnvInit_LogModules(t *testing.T) {
+	if os.Getenv("TEST_ENV_INIT") == "1" {
+		// Report the level the rules give to this package
+		result := envTestResult{Level: int(std.ModuleLevels().Level("log", GetLevel()))}
+		data, _ := json.Marshal(result)
+		os.Stdout.Write(data)
+		os.Exit(0)
+		return
+	}
+
+	got := runEnvSubprocessWith(t, "TestEnvInit_LogModules", "LOG_MODULES=other/*=debug,log=trace")
+	if got != TraceLevel {
+		t.Errorf("LOG_MODULES: expected level %v, got %v", TraceLevel, got)
+	}
+}
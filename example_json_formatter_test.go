@@ -9,7 +9,7 @@
 func Example_jsonFormatter() {
 	// Create logger with JSON output
 	logger := New()
-	logger.Format = &JSONFormatter{}
+	logger.SetFormatter(&JSONFormatter{})
 	logger.EnableCaller(false)
 	logger.EnableTrace(false)
 
@@ -18,19 +18,19 @@ func Example_jsonFormatter() {
 	logger.Error("Error occurred in JSON format")
 
 	// Pretty print JSON
-	logger.Format = &JSONFormatter{EnablePrettyPrint: true}
+	logger.SetFormatter(&JSONFormatter{EnablePrettyPrint: true})
 	logger.Warn("This is pretty printed JSON")
 
 	// Minimal JSON (no caller, no trace)
-	logger.Format = &JSONFormatter{
+	logger.SetFormatter(&JSONFormatter{
 		DisableCaller: true,
 		DisableTrace:  true,
-	}
+	})
 	logger.Debug("Minimal JSON log")
 
 	// Output to file with JSON format
 	// logger.SetOutput(GetOutputWriterHourly("./logs/app.log"))
-	// logger.Format = &JSONFormatter{}
+	// logger.SetFormatter(&JSONFormatter{})
 	// logger.Info("JSON logs to file")
 }
 
@@ -43,7 +43,7 @@ func Example_jsonFormatter_multipleOutputs() {
 	// File logger with JSON format
 	// fileLogger := New()
 	// fileLogger.SetOutput(GetOutputWriterHourly("./logs/app.log"))
-	// fileLogger.Format = &JSONFormatter{}
+	// fileLogger.SetFormatter(&JSONFormatter{})
 
 	// Use both loggers as needed
 	consoleLogger.Info("This goes to console as text")
@@ -55,7 +55,7 @@ func Example_jsonFormatter_withContext() {
 	// import logctx "github.com/lazygophers/log/logctx"
 	//
 	// logger := logctx.New()
-	// logger.Format = &log.JSONFormatter{}
+	// logger.SetFormatter(&log.JSONFormatter{})
 	// logger.Info(ctx, "JSON with context")
 	_ = fmt.Sprintf("context example")
 }
@@ -63,7 +63,7 @@ func Example_jsonFormatter_withContext() {
 // Example_jsonFormatter_fields shows how to log structured data
 func Example_jsonFormatter_fields() {
 	logger := New()
-	logger.Format = &JSONFormatter{}
+	logger.SetFormatter(&JSONFormatter{})
 	logger.EnableCaller(false)
 	logger.EnableTrace(false)
 
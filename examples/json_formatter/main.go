@@ -12,7 +12,7 @@ func main() {
 	// 1. Basic JSON logging
 	fmt.Println("1. Basic JSON Logging:")
 	logger := log.New()
-	logger.Format = &log.JSONFormatter{}
+	logger.SetFormatter(&log.JSONFormatter{})
 	logger.EnableCaller(false)
 	logger.EnableTrace(false)
 
@@ -23,28 +23,41 @@ func main() {
 
 	// 2. Pretty print JSON
 	fmt.Println("2. Pretty Print JSON:")
-	logger.Format = &log.JSONFormatter{EnablePrettyPrint: true}
+	logger.SetFormatter(&log.JSONFormatter{EnablePrettyPrint: true})
 	logger.Warn("Warning with pretty print")
 
 	fmt.Println()
 
 	// 3. Minimal JSON
 	fmt.Println("3. Minimal JSON (no caller/trace):")
-	logger.Format = &log.JSONFormatter{
+	logger.SetFormatter(&log.JSONFormatter{
 		DisableCaller: true,
 		DisableTrace:  true,
-	}
+	})
 	logger.Debug("Minimal debug log")
 
 	fmt.Println()
 
 	// 4. JSON with all fields
 	fmt.Println("4. JSON with All Fields:")
-	logger.Format = &log.JSONFormatter{}
+	logger.SetFormatter(&log.JSONFormatter{})
 	logger.EnableCaller(true)
 	logger.EnableTrace(true)
 	log.SetTrace("trace-12345")
 	logger.Info("Full JSON log with all metadata")
 
+	fmt.Println()
+
+	// 5. JSON for an ELK mapping
+	fmt.Println("5. JSON with Renamed Keys and Flattened Fields:")
+	logger.SetFormatter(&log.JSONFormatter{Encoder: log.JSONEncoderConfig{
+		Keys:          log.JSONKeys{Time: "@timestamp", Level: "severity", Message: "msg"},
+		FlattenFields: true,
+		Time:          log.TimeEncodingEpochMillis,
+		Level:         log.LevelEncodingUpper,
+		Caller:        log.CallerEncodingShort,
+	}})
+	logger.Infow("ELK ready log", "user_id", 12345)
+
 	fmt.Println("\n=== Demo Complete ===")
 }
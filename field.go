@@ -0,0 +1,272 @@
+package log
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// With returns a child logger that adds the given key-value pairs to every entry,
+// including those logged through the non-w methods such as Info or Errorf.
+//
+// The child shares the level of p, so SetLevel on either logger or on their
+// AtomicLevel reaches both; other later configuration changes on p are not
+// propagated. Use Clone for a logger with a level of its own.
+// Bound fields are pre-encoded once by formatters implementing constant.FieldsEncoder.
+//
+//	reqLog := logger.With("request_id", id, "user", user)
+//	reqLog.Info("started")
+func (p *Logger) With(kv ...interface{}) *Logger {
+	if len(kv) == 0 {
+		return p.withFields(nil)
+	}
+	return p.withFields(appendKVs(make([]KV, 0, (len(kv)+1)/2), kv...))
+}
+
+// WithFields returns a child logger that adds fields to every entry, sharing
+// the level of p like With. Keys are bound in sorted order so output is
+// deterministic.
+func (p *Logger) WithFields(fields map[string]interface{}) *Logger {
+	if len(fields) == 0 {
+		return p.withFields(nil)
+	}
+
+	keys := make([]string, 0, len(fields))
+	for k := range fields {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	kvs := make([]KV, 0, len(keys))
+	for _, k := range keys {
+		kvs = append(kvs, KV{Key: k, Value: fields[k]})
+	}
+	return p.withFields(kvs)
+}
+
+// withFields derives a logger from p sharing its level, with kvs appended to
+// its bound fields
+func (p *Logger) withFields(kvs []KV) *Logger {
+	c := p.config().clone()
+
+	fields := make([]KV, 0, len(c.fields)+len(kvs))
+	fields = append(fields, c.fields...)
+	c.fields = uniqueKVs(append(fields, kvs...))
+	c.fieldKeys = lastObjectKeys(c.fields)
+	c.encodeFields()
+
+	return newLoggerWith(c)
+}
+
+// encodeFields pre-encodes the bound fields with the snapshot's formatter
+func (c *loggerConfig) encodeFields() {
+	c.fieldsEncoded = nil
+
+	if len(c.fields) == 0 {
+		return
+	}
+	if enc, ok := c.format.(constant.FieldsEncoder); ok {
+		c.fieldsEncoded = enc.EncodeFields(c.fields)
+	}
+}
+
+// attachEncodedFields hands the pre-encoded bound fields to the entry
+//
+//go:inline
+func (c *loggerConfig) attachEncodedFields(entry *Entry) {
+	if c.fieldsEncoded == nil {
+		return
+	}
+	entry.EncodedFields = c.fieldsEncoded
+	entry.EncodedFieldsLen = len(c.fields)
+}
+
+// keySetPool holds the sets used to look for repeated field keys
+var keySetPool = sync.Pool{
+	New: func() any {
+		return make(map[string]struct{})
+	},
+}
+
+// smallKeyScan is the number of fields below which repeated keys are looked
+// for by comparing them with one another rather than through a key set
+const smallKeyScan = 8
+
+// uniqueFields drops the fields of entry whose key is repeated later in the
+// same object, so the last one wins. Bound fields are unique already, only the
+// fields added for this entry are looked up, unless hooks may have changed them.
+func (c *loggerConfig) uniqueFields(entry *Entry) {
+	bound, keys := len(c.fields), c.fieldKeys
+	if len(c.hooks) > 0 {
+		bound, keys = 0, nil
+	}
+	if len(entry.Fields) <= bound || !repeatsKey(entry.Fields[bound:], keys) {
+		return
+	}
+
+	if entry.EncodedFieldsLen > 0 && replacesKey(entry.Fields[bound:], keys) {
+		// A pre-encoded bound field is replaced, encode them all again
+		entry.EncodedFields = nil
+		entry.EncodedFieldsLen = 0
+	}
+	entry.Fields = uniqueKVs(entry.Fields)
+}
+
+// replacesKey reports whether one of fields outside any namespace has a key
+// of bound
+func replacesKey(fields []KV, bound map[string]struct{}) bool {
+	for _, kv := range fields {
+		if _, ok := bound[kv.Key]; ok {
+			return true
+		}
+		if kv.Type == constant.FieldTypeNamespace {
+			return false
+		}
+	}
+	return false
+}
+
+// repeatsKey reports whether a key of fields is repeated within the same
+// object, or repeats one of bound, the keys of the object fields belong to
+func repeatsKey(fields []KV, bound map[string]struct{}) bool {
+	if len(fields) < smallKeyScan {
+		start := 0
+		for i, kv := range fields {
+			if _, ok := bound[kv.Key]; ok {
+				return true
+			}
+			for _, prev := range fields[start:i] {
+				if prev.Key == kv.Key {
+					return true
+				}
+			}
+			if kv.Type == constant.FieldTypeNamespace {
+				// Later fields go into a new object
+				bound, start = nil, i+1
+			}
+		}
+		return false
+	}
+
+	seen := keySetPool.Get().(map[string]struct{})
+	defer func() {
+		clear(seen)
+		keySetPool.Put(seen)
+	}()
+	for _, kv := range fields {
+		if _, ok := bound[kv.Key]; ok {
+			return true
+		}
+		if _, ok := seen[kv.Key]; ok {
+			return true
+		}
+		seen[kv.Key] = struct{}{}
+		if kv.Type == constant.FieldTypeNamespace {
+			bound = nil
+			clear(seen)
+		}
+	}
+	return false
+}
+
+// uniqueKVs returns fields without those whose key is repeated later in the
+// same object, keeping the order of the others. fields is returned as is when
+// its keys are unique.
+func uniqueKVs(fields []KV) []KV {
+	if len(fields) < 2 || !repeatsKey(fields, nil) {
+		return fields
+	}
+
+	seen := keySetPool.Get().(map[string]struct{})
+	defer func() {
+		clear(seen)
+		keySetPool.Put(seen)
+	}()
+
+	// Walk backwards so the last field with a key is the one kept
+	out := make([]KV, len(fields))
+	i := len(out)
+	for j := len(fields) - 1; j >= 0; j-- {
+		kv := fields[j]
+		if kv.Type == constant.FieldTypeNamespace {
+			// The fields seen so far belong to the object kv opens
+			clear(seen)
+		}
+		if _, ok := seen[kv.Key]; ok {
+			continue
+		}
+		seen[kv.Key] = struct{}{}
+		i--
+		out[i] = kv
+	}
+	return out[i:]
+}
+
+// lastObjectKeys returns the keys of the fields in the object the fields
+// logged after them go into, that is after the last namespace
+func lastObjectKeys(fields []KV) map[string]struct{} {
+	start := 0
+	for i, kv := range fields {
+		if kv.Type == constant.FieldTypeNamespace {
+			start = i + 1
+		}
+	}
+	if start == len(fields) {
+		return nil
+	}
+
+	keys := make(map[string]struct{}, len(fields)-start)
+	for _, kv := range fields[start:] {
+		keys[kv.Key] = struct{}{}
+	}
+	return keys
+}
+
+// appendEncodedFields appends the bound fields of entry pre-encoded by
+// EncodeFields, then sep when more fields follow, and returns the fields
+// still to encode. Without pre-encoded fields dst is left as is.
+func appendEncodedFields(dst []byte, entry *Entry, sep byte) ([]byte, []KV) {
+	fields := entry.Fields
+	n := entry.EncodedFieldsLen
+	if n <= 0 || n > len(fields) {
+		return dst, fields
+	}
+
+	dst = append(dst, entry.EncodedFields...)
+	if fields = fields[n:]; len(fields) > 0 {
+		dst = append(dst, sep)
+	}
+	return dst, fields
+}
+
+// appendKVs parses loose key-value pairs (odd=key, even=value) and appends them to dst.
+// Typed fields (see Field) take the place of a whole pair.
+// A trailing key without value is stored with a nil value.
+func appendKVs(dst []KV, args ...interface{}) []KV {
+	for i := 0; i < len(args); i += 2 {
+		if f, ok := args[i].(Field); ok {
+			dst = append(dst, f)
+			i--
+			continue
+		}
+		if i+1 >= len(args) {
+			// Odd number of args, last key without value
+			dst = append(dst, KV{Key: kvKey(args[i]), Value: nil})
+			break
+		}
+		dst = append(dst, KV{Key: kvKey(args[i]), Value: args[i+1]})
+	}
+	return dst
+}
+
+// kvKey converts a loose key to string, skipping fmt for the common string case
+//
+//go:inline
+func kvKey(k interface{}) string {
+	if s, ok := k.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", k)
+}
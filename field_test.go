@@ -1,8 +1,14 @@
 package log
 
 import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/lazygophers/log/constant"
 )
 
 func TestLogger_Infow_Basic(t *testing.T) {
@@ -164,3 +170,237 @@ func BenchmarkLogger_Infow(b *testing.B) {
 		)
 	}
 }
+
+type countingFormatter struct {
+	Formatter
+	encodeCalls int
+}
+
+func (f *countingFormatter) EncodeFields(fields []KV) []byte {
+	f.encodeCalls++
+	return f.Formatter.EncodeFields(fields)
+}
+
+// Clone shares the formatter so calls made through children are counted
+func (f *countingFormatter) Clone() constant.Format {
+	return f
+}
+
+func TestLogger_With(t *testing.T) {
+	t.Run("bound_fields_on_all_methods", func(t *testing.T) {
+		var buf bytes.Buffer
+		child := newTestLogger(&buf).With("request_id", "abc", "attempt", 2)
+
+		child.Info("plain")
+		child.Errorf("formatted %d", 1)
+		child.Warnw("structured", "extra", true)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		if len(lines) != 3 {
+			t.Fatalf("expected 3 lines, got %d: %q", len(lines), buf.String())
+		}
+		for _, line := range lines {
+			if !strings.Contains(line, "request_id=abc attempt=2") {
+				t.Errorf("line missing bound fields: %q", line)
+			}
+		}
+		if !strings.Contains(lines[2], "attempt=2 extra=true") {
+			t.Errorf("call fields should follow bound fields: %q", lines[2])
+		}
+	})
+
+	t.Run("parent_unaffected", func(t *testing.T) {
+		var buf bytes.Buffer
+		parent := newTestLogger(&buf)
+		_ = parent.With("bound", 1)
+
+		parent.Info("parent")
+		if strings.Contains(buf.String(), "bound=") {
+			t.Errorf("parent should not carry child fields: %q", buf.String())
+		}
+	})
+
+	t.Run("nested_accumulates", func(t *testing.T) {
+		var buf bytes.Buffer
+		a := newTestLogger(&buf).With("a", 1)
+		b := a.With("b", 2)
+
+		b.Info("nested")
+		if !strings.Contains(buf.String(), "a=1 b=2") {
+			t.Errorf("expected accumulated fields, got %q", buf.String())
+		}
+
+		buf.Reset()
+		a.Info("sibling")
+		if strings.Contains(buf.String(), "b=2") {
+			t.Errorf("parent child should not see grandchild fields: %q", buf.String())
+		}
+	})
+
+	t.Run("odd_args", func(t *testing.T) {
+		var buf bytes.Buffer
+		newTestLogger(&buf).With("k", "v", "dangling").Info("odd")
+		if !strings.Contains(buf.String(), "k=v dangling=<nil>") {
+			t.Errorf("unexpected output %q", buf.String())
+		}
+	})
+
+	t.Run("no_args_clones", func(t *testing.T) {
+		logger := New()
+		if logger.With() == logger {
+			t.Error("With() should return a new logger")
+		}
+	})
+}
+
+func TestLogger_WithFields(t *testing.T) {
+	var buf bytes.Buffer
+	newTestLogger(&buf).WithFields(map[string]interface{}{
+		"zeta":  1,
+		"alpha": "x",
+		"mid":   true,
+	}).Info("sorted")
+
+	if !strings.Contains(buf.String(), "alpha=x mid=true zeta=1") {
+		t.Errorf("fields should be bound in sorted order, got %q", buf.String())
+	}
+}
+
+func TestLogger_With_PreEncoded(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+	f := &countingFormatter{Formatter: Formatter{DisableParsingAndEscaping: true}}
+	logger.SetFormatter(f)
+
+	child := logger.With("svc", "api")
+	if f.encodeCalls != 1 {
+		t.Fatalf("With should encode bound fields once, got %d calls", f.encodeCalls)
+	}
+
+	for i := 0; i < 5; i++ {
+		child.Infow("line", "i", i)
+	}
+	if f.encodeCalls != 1 {
+		t.Errorf("logging should reuse the encoded fields, got %d encode calls", f.encodeCalls)
+	}
+	if !strings.Contains(buf.String(), "svc=api i=4") {
+		t.Errorf("unexpected output %q", buf.String())
+	}
+}
+
+func TestLogger_With_FormatChanged(t *testing.T) {
+	var buf bytes.Buffer
+	child := newTestLogger(&buf).With("svc", "api")
+
+	// Encoded bytes belong to the old formatter and must not be reused
+	child.SetFormatter(&Formatter{DisableParsingAndEscaping: true, DisableCaller: true})
+	child.Info("after swap")
+
+	if !strings.Contains(buf.String(), "svc=api") {
+		t.Errorf("bound fields should still be written, got %q", buf.String())
+	}
+}
+
+func TestLogger_With_PooledEntryIsolation(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+	child := logger.With("secret", "child-only")
+
+	for i := 0; i < 10; i++ {
+		child.Info("child")
+		logger.Info("parent")
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.Contains(line, "parent") && strings.Contains(line, "secret=") {
+			t.Fatalf("bound fields leaked through entry pool: %q", line)
+		}
+	}
+}
+
+func TestLogger_With_Hooks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf)
+	logger.AddHook(constant.HookFunc(func(entry interface{}) interface{} {
+		if e, ok := entry.(*Entry); ok {
+			for i := range e.Fields {
+				e.Fields[i].Value = "masked"
+			}
+		}
+		return entry
+	}))
+	child := logger.With("token", "s3cr3t")
+
+	child.Info("first")
+	child.Info("second")
+
+	if strings.Contains(buf.String(), "s3cr3t") {
+		t.Errorf("hook changes should be written, got %q", buf.String())
+	}
+	if len(child.config().fields) != 1 || child.config().fields[0].Value != "s3cr3t" {
+		t.Errorf("hooks must not modify the logger's bound fields, got %v", child.config().fields)
+	}
+}
+
+func TestWith_Global(t *testing.T) {
+	if With("k", "v") == std {
+		t.Error("With should return a child of the standard logger")
+	}
+	if WithFields(map[string]interface{}{"k": "v"}) == std {
+		t.Error("WithFields should return a child of the standard logger")
+	}
+}
+
+func TestUniqueKVs(t *testing.T) {
+	keys := func(fields []KV) string {
+		var b strings.Builder
+		for _, kv := range fields {
+			fmt.Fprintf(&b, "%s=%v ", kv.Key, kv.Any())
+		}
+		return strings.TrimSpace(b.String())
+	}
+
+	tests := []struct {
+		fields []KV
+		want   string
+	}{
+		{[]KV{{Key: "a", Value: 1}, {Key: "b", Value: 2}}, "a=1 b=2"},
+		{[]KV{{Key: "a", Value: 1}, {Key: "b", Value: 2}, {Key: "a", Value: 3}}, "b=2 a=3"},
+		// Keys only clash within the same object
+		{[]KV{{Key: "id", Value: 1}, Namespace("req"), {Key: "id", Value: 2}}, "id=1 req=<nil> id=2"},
+		{[]KV{{Key: "req", Value: 1}, Namespace("req"), {Key: "id", Value: 2}, {Key: "id", Value: 3}}, "req=<nil> id=3"},
+	}
+	for _, tt := range tests {
+		if got := keys(uniqueKVs(tt.fields)); got != tt.want {
+			t.Errorf("uniqueKVs(%v) = %s, want %s", tt.fields, got, tt.want)
+		}
+	}
+
+	// Enough fields to go through the key set
+	var many []KV
+	for i := range 2 * smallKeyScan {
+		many = append(many, KV{Key: strconv.Itoa(i % (smallKeyScan + 1)), Value: i})
+	}
+	if got := uniqueKVs(many); len(got) != smallKeyScan+1 || got[len(got)-1].Value != 2*smallKeyScan-1 {
+		t.Errorf("uniqueKVs kept %v", got)
+	}
+	if !repeatsKey(many, nil) || repeatsKey(many[:smallKeyScan+1], nil) {
+		t.Error("repeatsKey should find repeated keys through the key set")
+	}
+}
+
+func TestLogger_With_RepeatedKeys(t *testing.T) {
+	var buf bytes.Buffer
+	child := newTestLogger(&buf).With("user", "bob", "svc", "api").With("user", "carol")
+
+	child.Infow("login", "svc", "web", "n", 1, "n", 2)
+	if want := "user=carol svc=web n=2"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output %q should contain %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	child.Info("plain")
+	if want := "svc=api user=carol"; !strings.Contains(buf.String(), want) {
+		t.Errorf("output %q should contain %q", buf.String(), want)
+	}
+}
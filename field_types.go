@@ -0,0 +1,203 @@
+package log
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// Field is a typed key-value pair, built by String, Int64, Err and the other
+// constructors. Fields can be mixed with loose pairs in the w-methods and With:
+//
+//	logger.Infow("request", log.String("method", "GET"), log.Duration("took", d), "status", 200)
+//
+// Values are kept unboxed where possible, so formatters encode them
+// without reflection.
+type Field = KV
+
+// FieldType re-exports constant.FieldType for convenience
+type FieldType = constant.FieldType
+
+// Times representable as Unix nanoseconds
+var (
+	minTimeField = time.Unix(0, math.MinInt64)
+	maxTimeField = time.Unix(0, math.MaxInt64)
+)
+
+// String constructs a field carrying a string
+func String(key, val string) Field {
+	return Field{Key: key, Type: constant.FieldTypeString, Value: val}
+}
+
+// Int constructs a field carrying an int
+func Int(key string, val int) Field {
+	return Int64(key, int64(val))
+}
+
+// Int64 constructs a field carrying an int64
+func Int64(key string, val int64) Field {
+	return Field{Key: key, Type: constant.FieldTypeInt64, Int: val}
+}
+
+// Uint64 constructs a field carrying a uint64
+func Uint64(key string, val uint64) Field {
+	if val > math.MaxInt64 {
+		// Out of the int64 range, keep the value itself
+		return Field{Key: key, Value: val}
+	}
+	return Int64(key, int64(val))
+}
+
+// Float64 constructs a field carrying a float64
+func Float64(key string, val float64) Field {
+	return Field{Key: key, Type: constant.FieldTypeFloat64, Int: int64(math.Float64bits(val))}
+}
+
+// Bool constructs a field carrying a bool
+func Bool(key string, val bool) Field {
+	var i int64
+	if val {
+		i = 1
+	}
+	return Field{Key: key, Type: constant.FieldTypeBool, Int: i}
+}
+
+// Duration constructs a field carrying a time.Duration
+func Duration(key string, val time.Duration) Field {
+	return Field{Key: key, Type: constant.FieldTypeDuration, Int: int64(val)}
+}
+
+// Time constructs a field carrying a time.Time
+func Time(key string, val time.Time) Field {
+	if val.Before(minTimeField) || val.After(maxTimeField) {
+		// Out of the Unix nanosecond range, keep the time itself
+		return Field{Key: key, Value: val}
+	}
+	return Field{Key: key, Type: constant.FieldTypeTime, Int: val.UnixNano(), Value: val.Location()}
+}
+
+// Err constructs a field carrying err under the key "error"
+func Err(err error) Field {
+	return NamedErr("error", err)
+}
+
+// NamedErr constructs a field carrying err under key
+func NamedErr(key string, err error) Field {
+	return Field{Key: key, Type: constant.FieldTypeError, Value: err}
+}
+
+// Stringer constructs a field carrying val, whose String method is only
+// called when the entry is formatted. Like fmt, a nil pointer whose String
+// method panics is written as "<nil>".
+func Stringer(key string, val fmt.Stringer) Field {
+	return Field{Key: key, Type: constant.FieldTypeStringer, Value: val}
+}
+
+// Binary constructs a field carrying raw bytes, encoded as base64
+func Binary(key string, val []byte) Field {
+	return Field{Key: key, Type: constant.FieldTypeBinary, Value: val}
+}
+
+// Namespace nests the fields that follow it under key: as an object in
+// JSON, as key.sub=value in text
+func Namespace(key string) Field {
+	return Field{Key: key, Type: constant.FieldTypeNamespace}
+}
+
+// Any constructs a field for val, picking the typed constructor matching
+// its type and keeping other values as they are
+func Any(key string, val interface{}) Field {
+	switch v := val.(type) {
+	case ObjectMarshaler:
+		return Object(key, v)
+	case ArrayMarshaler:
+		return Array(key, v)
+	case string:
+		return String(key, v)
+	case int:
+		return Int64(key, int64(v))
+	case int8:
+		return Int64(key, int64(v))
+	case int16:
+		return Int64(key, int64(v))
+	case int32:
+		return Int64(key, int64(v))
+	case int64:
+		return Int64(key, v)
+	case uint:
+		return Uint64(key, uint64(v))
+	case uint8:
+		return Uint64(key, uint64(v))
+	case uint16:
+		return Uint64(key, uint64(v))
+	case uint32:
+		return Uint64(key, uint64(v))
+	case uint64:
+		return Uint64(key, v)
+	case float32:
+		return Float64(key, float64(v))
+	case float64:
+		return Float64(key, v)
+	case bool:
+		return Bool(key, v)
+	case time.Duration:
+		return Duration(key, v)
+	case time.Time:
+		return Time(key, v)
+	case []byte:
+		return Binary(key, v)
+	case error:
+		return NamedErr(key, v)
+	case fmt.Stringer:
+		return Stringer(key, v)
+	default:
+		return Field{Key: key, Value: val}
+	}
+}
+
+// fieldString returns the string form of a field holding text, reporting
+// false for other types
+func fieldString(kv KV) (string, bool) {
+	switch kv.Type {
+	case constant.FieldTypeString:
+		s, ok := kv.Value.(string)
+		return s, ok
+	case constant.FieldTypeError:
+		if err, ok := kv.Value.(error); ok && err != nil {
+			return callString(err, "Error", err.Error), true
+		}
+	case constant.FieldTypeStringer:
+		if s, ok := kv.Value.(fmt.Stringer); ok && s != nil {
+			return callString(s, "String", s.String), true
+		}
+	}
+	return "", false
+}
+
+// callString returns the result of method, the String or Error method of v
+// named name, recovering from a panic the way fmt does
+func callString(v interface{}, name string, method func() string) (s string) {
+	defer func() {
+		if r := recover(); r != nil {
+			if rv := reflect.ValueOf(v); rv.Kind() == reflect.Pointer && rv.IsNil() {
+				s = "<nil>"
+				return
+			}
+			s = fmt.Sprintf("%%!v(PANIC=%s method: %v)", name, r)
+		}
+	}()
+	return method()
+}
+
+// hasNamespace reports whether fields open a namespace
+func hasNamespace(fields []KV) bool {
+	for _, kv := range fields {
+		if kv.Type == constant.FieldTypeNamespace {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,214 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"io"
+	"math"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/constant"
+)
+
+func TestFieldConstructors(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.FixedZone("CET", 3600))
+	err := errors.New("boom")
+
+	tests := []struct {
+		field Field
+		typ   FieldType
+		want  interface{}
+	}{
+		{String("s", "v"), constant.FieldTypeString, "v"},
+		{Int("i", -3), constant.FieldTypeInt64, int64(-3)},
+		{Int64("i64", math.MaxInt64), constant.FieldTypeInt64, int64(math.MaxInt64)},
+		{Float64("f", 1.5), constant.FieldTypeFloat64, 1.5},
+		{Bool("b", true), constant.FieldTypeBool, true},
+		{Bool("b", false), constant.FieldTypeBool, false},
+		{Duration("d", time.Second), constant.FieldTypeDuration, time.Second},
+		{Err(err), constant.FieldTypeError, err},
+		{NamedErr("cause", err), constant.FieldTypeError, err},
+		{Stringer("month", time.March), constant.FieldTypeStringer, time.March},
+		{Namespace("ns"), constant.FieldTypeNamespace, nil},
+		{Any("a", "v"), constant.FieldTypeString, "v"},
+		{Any("a", 7), constant.FieldTypeInt64, int64(7)},
+		{Any("a", float32(0.5)), constant.FieldTypeFloat64, 0.5},
+		{Any("a", time.Minute), constant.FieldTypeDuration, time.Minute},
+		{Any("a", err), constant.FieldTypeError, err},
+		{Uint64("u64", 7), constant.FieldTypeInt64, int64(7)},
+		{Uint64("u64", math.MaxUint64), constant.FieldTypeAny, uint64(math.MaxUint64)},
+		{Any("a", uint(7)), constant.FieldTypeInt64, int64(7)},
+		{Any("a", uint8(7)), constant.FieldTypeInt64, int64(7)},
+		{Any("a", uint16(7)), constant.FieldTypeInt64, int64(7)},
+		{Any("a", uint32(7)), constant.FieldTypeInt64, int64(7)},
+		{Any("a", uint64(7)), constant.FieldTypeInt64, int64(7)},
+	}
+
+	for _, tt := range tests {
+		if tt.field.Type != tt.typ {
+			t.Errorf("%s: type = %d, want %d", tt.field.Key, tt.field.Type, tt.typ)
+		}
+		if got := tt.field.Any(); got != tt.want {
+			t.Errorf("%s: Any() = %#v, want %#v", tt.field.Key, got, tt.want)
+		}
+	}
+
+	if got := Time("t", ts).Any().(time.Time); !got.Equal(ts) || got.Location() != ts.Location() {
+		t.Errorf("Time should keep instant and location, got %v", got)
+	}
+	if got := Time("t", time.Time{}); got.Type != constant.FieldTypeAny || got.Value != (time.Time{}) {
+		t.Errorf("times out of the nanosecond range should be kept as is, got %#v", got)
+	}
+	if got := Binary("bin", []byte("hi")).Any().([]byte); string(got) != "hi" {
+		t.Errorf("Binary should keep the bytes, got %q", got)
+	}
+	if Err(err).Key != "error" {
+		t.Error("Err should use the error key")
+	}
+}
+
+func TestTypedFields_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false)
+
+	logger.Infow("typed",
+		String("s", "v"),
+		"loose", 1,
+		Int64("i", 42),
+		Float64("f", 0.25),
+		Bool("b", true),
+		Duration("d", 1500*time.Millisecond),
+		Time("t", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Err(errors.New("boom")),
+		Stringer("ip", net.IPv4(10, 0, 0, 1)),
+		Binary("bin", []byte("hi")),
+		Namespace("req"),
+		String("id", "abc"),
+	)
+
+	want := "s=v loose=1 i=42 f=0.25 b=true d=1.5s t=2024-01-02T03:04:05Z error=boom ip=10.0.0.1 bin=aGk= req.id=abc"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("text output %q should contain %q", buf.String(), want)
+	}
+}
+
+func TestTypedFields_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	logger.Infow("typed",
+		String("s", "v"),
+		"loose", 1,
+		Int64("i", 42),
+		Float64("f", math.Inf(1)),
+		Bool("b", false),
+		Duration("d", time.Second),
+		Time("t", time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)),
+		Err(errors.New("boom")),
+		Binary("bin", []byte("hi")),
+		Namespace("req"),
+		String("id", "abc"),
+		Namespace("user"),
+		Int("uid", 7),
+	)
+
+	want := `"fields":{"s":"v","loose":1,"i":42,"f":"+Inf","b":false,"d":1000000000,` +
+		`"t":"2024-01-02T03:04:05Z","error":"boom","bin":"aGk=","req":{"id":"abc","user":{"uid":7}}}}`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("JSON output %q should contain %q", buf.String(), want)
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Errorf("output should be valid JSON: %s", buf.String())
+	}
+}
+
+// panicStringer panics in String, even on a nil pointer
+type panicStringer struct{ name string }
+
+func (p *panicStringer) String() string {
+	if p.name == "" {
+		panic("no name")
+	}
+	return p.name
+}
+
+func TestTypedFields_PanickingStringer(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false)
+
+	var nilPtr *panicStringer
+	logger.Infow("stringers", Stringer("nil", nilPtr), Any("empty", &panicStringer{}), Any("n", uint8(3)))
+
+	want := "nil=<nil> empty=%!v(PANIC=String method: no name) n=3"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("text output %q should contain %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	logger.SetFormatter(&JSONFormatter{}).Infow("stringers", Stringer("nil", nilPtr))
+	if want := `"fields":{"nil":"<nil>"}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("JSON output %q should contain %q", buf.String(), want)
+	}
+}
+
+func TestTypedFields_BoundNamespace(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	child := logger.With(String("service", "api"), Namespace("req"))
+	if child.config().fieldsEncoded != nil {
+		t.Error("bound fields opening a namespace should not be pre-encoded")
+	}
+	child.Infow("nested", "id", 1)
+
+	if !strings.Contains(buf.String(), `"fields":{"service":"api","req":{"id":1}}`) {
+		t.Errorf("fields logged after a bound namespace belong to it, got %s", buf.String())
+	}
+}
+
+func TestAppendKVs_MixedFields(t *testing.T) {
+	kvs := appendKVs(nil, String("a", "1"), "b", 2, Int("c", 3), "dangling")
+	if len(kvs) != 4 {
+		t.Fatalf("expected 4 fields, got %v", kvs)
+	}
+	keys := []string{"a", "b", "c", "dangling"}
+	for i, kv := range kvs {
+		if kv.Key != keys[i] {
+			t.Errorf("field %d key = %q, want %q", i, kv.Key, keys[i])
+		}
+	}
+	if kvs[3].Value != nil {
+		t.Error("a dangling key should get a nil value")
+	}
+}
+
+// Values for the field benchmarks, variables so the compiler can't box them statically
+var (
+	benchMethod  = "GET"
+	benchStatus  = 503
+	benchLatency = 1.25
+	benchCached  = true
+)
+
+func BenchmarkInfow_LoosePairs(b *testing.B) {
+	logger := New().SetOutput(io.Discard).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Infow("request", "method", benchMethod, "status", benchStatus, "latency", benchLatency, "cached", benchCached)
+	}
+}
+
+func BenchmarkInfow_TypedFields(b *testing.B) {
+	logger := New().SetOutput(io.Discard).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	b.ReportAllocs()
+	for b.Loop() {
+		logger.Infow("request", String("method", benchMethod), Int("status", benchStatus),
+			Float64("latency", benchLatency), Bool("cached", benchCached))
+	}
+}
@@ -2,10 +2,13 @@
 
 import (
 	"bytes"
+	"encoding/base64"
 	"fmt"
+	"math"
 	"path"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/lazygophers/log/constant"
 )
@@ -22,6 +25,13 @@ func (p *Formatter) format(entry *Entry) []byte {
 	b := GetBuffer()
 	defer PutBuffer(b)
 
+	p.formatLine(b, entry)
+
+	return bufferCopy(b)
+}
+
+// formatLine writes a single log line into b
+func (p *Formatter) formatLine(b *bytes.Buffer, entry *Entry) {
 	p.formatPrefix(b, entry)
 	p.formatTimestamp(b, entry)
 	p.formatLevel(b, entry)
@@ -29,8 +39,6 @@ func (p *Formatter) format(entry *Entry) []byte {
 	p.formatFields(b, entry) // Format structured fields
 	p.formatCallerAndTrace(b, entry)
 	p.formatSuffix(b, entry)
-
-	return b.Bytes()
 }
 
 // formatPrefix writes prefix message and process/goroutine IDs
@@ -74,14 +82,67 @@ func (p *Formatter) formatFields(b *bytes.Buffer, entry *Entry) {
 	}
 
 	b.WriteByte(' ')
-	for i, field := range entry.Fields {
-		if i > 0 {
-			b.WriteByte(' ')
+
+	data, fields := appendEncodedFields(b.AvailableBuffer(), entry, ' ')
+	b.Write(data)
+
+	p.writeFields(b, fields)
+}
+
+// writeFields writes fields as space separated key=value pairs
+func (p *Formatter) writeFields(b *bytes.Buffer, fields []KV) {
+	b.Write(appendTextFields(b.AvailableBuffer(), fields, false))
+}
+
+// appendTextValue appends the value of field as fmt's %v would print it,
+// without going through fmt for typed fields and common types
+func appendTextValue(dst []byte, field KV) []byte {
+	switch field.Type {
+	case constant.FieldTypeInt64:
+		return strconv.AppendInt(dst, field.Int, 10)
+	case constant.FieldTypeFloat64:
+		return strconv.AppendFloat(dst, math.Float64frombits(uint64(field.Int)), 'g', -1, 64)
+	case constant.FieldTypeBool:
+		return strconv.AppendBool(dst, field.Int != 0)
+	case constant.FieldTypeDuration:
+		return append(dst, time.Duration(field.Int).String()...)
+	case constant.FieldTypeTime:
+		return field.Any().(time.Time).AppendFormat(dst, time.RFC3339Nano)
+	case constant.FieldTypeBinary:
+		data, _ := field.Value.([]byte)
+		return base64.StdEncoding.AppendEncode(dst, data)
+	case constant.FieldTypeError, constant.FieldTypeStringer:
+		if s, ok := fieldString(field); ok {
+			return append(dst, s...)
 		}
-		b.WriteString(field.Key)
-		b.WriteByte('=')
-		b.WriteString(fmt.Sprintf("%v", field.Value))
 	}
+
+	// Loose values and typed strings
+	switch v := field.Value.(type) {
+	case string:
+		return append(dst, v...)
+	case int:
+		return strconv.AppendInt(dst, int64(v), 10)
+	case int64:
+		return strconv.AppendInt(dst, v, 10)
+	case bool:
+		return strconv.AppendBool(dst, v)
+	case float64:
+		return strconv.AppendFloat(dst, v, 'g', -1, 64)
+	default:
+		return fmt.Appendf(dst, "%v", v)
+	}
+}
+
+// EncodeFields implements constant.FieldsEncoder.
+// Fields opening a namespace are left to Format.
+func (p *Formatter) EncodeFields(fields []KV) []byte {
+	if hasNamespace(fields) {
+		return nil
+	}
+	var b bytes.Buffer
+	p.writeFields(&b, fields)
+	return b.Bytes()
 }
 
 // formatCallerAndTrace writes caller and trace information
@@ -148,18 +209,18 @@ func (p *Formatter) Format(entry interface{}) []byte {
 		if idx == -1 {
 			// Last line (or only line if no \n found)
 			e.Message = msg[start:]
-			b.Write(p.format(e))
+			p.formatLine(b, e)
 			break
 		}
 		// idx is relative to msg[start:], so we add start to get absolute position
 		absIdx := start + idx
 		// Extract line without the newline character
 		e.Message = msg[start:absIdx]
-		b.Write(p.format(e))
+		p.formatLine(b, e)
 		start = absIdx + 1 // Move past the newline
 	}
 
-	return b.Bytes()
+	return bufferCopy(b)
 }
 
 // ParsingAndEscaping sets message parsing and escaping
@@ -1,18 +1,121 @@
 package log
 
 import (
-	"bytes"
 	"encoding/json"
+	"path"
+	"strconv"
+	"time"
 )
 
 // JSONFormatter implements Format interface for JSON output
 type JSONFormatter struct {
-	EnablePrettyPrint bool // Enable pretty print with indentation
-	DisableCaller    bool // Disable caller information
-	DisableTrace     bool // Disable trace information
+	EnablePrettyPrint bool              // Enable pretty print with indentation
+	DisableCaller     bool              // Disable caller information
+	DisableTrace      bool              // Disable trace information
+	Encoder           JSONEncoderConfig // Key names and value encodings
 }
 
-// Format formats log entry to JSON
+// JSONEncoderConfig tells JSONFormatter how to name and encode each key. The
+// zero value gives the default output.
+//
+// For an ELK mapping expecting msg, @timestamp, severity and top level fields:
+//
+//	&log.JSONFormatter{Encoder: log.JSONEncoderConfig{
+//		Keys:          log.JSONKeys{Time: "@timestamp", Level: "severity", Message: "msg"},
+//		FlattenFields: true,
+//		Time:          log.TimeEncodingEpochMillis,
+//		Level:         log.LevelEncodingUpper,
+//	}}
+type JSONEncoderConfig struct {
+	Keys JSONKeys
+
+	// FlattenFields writes fields as top level keys instead of nesting them
+	// under the fields key. A field named like another key replaces it.
+	FlattenFields bool
+
+	Time   TimeEncoding
+	Level  LevelEncoding
+	Caller CallerEncoding
+}
+
+// JSONKeys names the keys written by JSONFormatter. An empty name keeps the
+// default, "-" leaves the key out.
+type JSONKeys struct {
+	Time       string // Default "time"
+	Level      string // Default "level"
+	Message    string // Default "message"
+	Pid        string // Default "pid"
+	Gid        string // Default "gid"
+	TraceID    string // Default "trace_id"
+	Caller     string // Default "caller", for CallerEncodingShort and CallerEncodingFull
+	CallerFile string // Default "caller_file", for CallerEncodingSplit
+	CallerLine string // Default "caller_line", for CallerEncodingSplit
+	CallerFunc string // Default "caller_func"
+	CallerDir  string // Default "caller_dir", for CallerEncodingSplit
+	CallerName string // Default "caller_name", for CallerEncodingSplit
+	Prefix     string // Default "prefix_msg"
+	Suffix     string // Default "suffix_msg"
+	Fields     string // Default "fields", unless fields are flattened
+}
+
+// TimeEncoding selects how JSONFormatter encodes the entry time
+type TimeEncoding uint8
+
+const (
+	// TimeEncodingRFC3339Nano writes RFC 3339 strings with nanoseconds, the default
+	TimeEncodingRFC3339Nano TimeEncoding = iota
+	// TimeEncodingRFC3339 writes RFC 3339 strings with whole seconds
+	TimeEncodingRFC3339
+	// TimeEncodingEpochSeconds writes whole seconds since the Unix epoch
+	TimeEncodingEpochSeconds
+	// TimeEncodingEpochMillis writes milliseconds since the Unix epoch
+	TimeEncodingEpochMillis
+	// TimeEncodingEpochNanos writes nanoseconds since the Unix epoch
+	TimeEncodingEpochNanos
+)
+
+// LevelEncoding selects how JSONFormatter encodes the level
+type LevelEncoding uint8
+
+const (
+	// LevelEncodingLower writes lower case names such as "info", the default
+	LevelEncodingLower LevelEncoding = iota
+	// LevelEncodingUpper writes upper case names such as "INFO"
+	LevelEncodingUpper
+	// LevelEncodingNumeric writes the Level value, from 0 for panic to 6 for trace
+	LevelEncodingNumeric
+)
+
+// CallerEncoding selects how JSONFormatter encodes the caller
+type CallerEncoding uint8
+
+const (
+	// CallerEncodingSplit writes caller_file, caller_line, caller_func,
+	// caller_dir and caller_name, the default
+	CallerEncodingSplit CallerEncoding = iota
+	// CallerEncodingShort writes "dir/file.go:42" under caller, plus caller_func
+	CallerEncodingShort
+	// CallerEncodingFull writes "/path/to/dir/file.go:42" under caller, plus caller_func
+	CallerEncodingFull
+)
+
+// upperLevels holds the names written by LevelEncodingUpper
+var upperLevels = [...]string{
+	PanicLevel: "PANIC",
+	FatalLevel: "FATAL",
+	ErrorLevel: "ERROR",
+	WarnLevel:  "WARN",
+	InfoLevel:  "INFO",
+	DebugLevel: "DEBUG",
+	TraceLevel: "TRACE",
+}
+
+// Format formats log entry to JSON.
+//
+// The entry is encoded straight into a pooled buffer with a fixed key order:
+// time, level, message, pid, gid, trace_id, caller_*, prefix_msg, suffix_msg
+// and fields, named and encoded as set in Encoder. Only values of uncommon
+// types go through encoding/json.
 func (f *JSONFormatter) Format(entry interface{}) []byte {
 	// Type assert to *Entry
 	e, ok := entry.(*Entry)
@@ -23,81 +126,239 @@ func (f *JSONFormatter) Format(entry interface{}) []byte {
 	b := GetBuffer()
 	defer PutBuffer(b)
 
-	// Apply conditional modifications by creating a shallow copy
-	serializeEntry := *e
+	data, err := f.appendEntry(b.AvailableBuffer(), e)
+	if err != nil {
+		// Fallback to error message if a field value can't be encoded
+		levelKey, msgKey := "level", "message"
+		if key := configuredKey(f.Encoder.Keys.Level, levelKey); key != "" {
+			levelKey = key
+		}
+		if key := configuredKey(f.Encoder.Keys.Message, msgKey); key != "" {
+			msgKey = key
+		}
+		data = append(b.AvailableBuffer(), '{')
+		data = appendJSONString(data, levelKey)
+		data = append(data, `:"error",`...)
+		data = appendJSONString(data, msgKey)
+		data = append(data, `:"JSON marshaling failed: `...)
+		data = appendJSONEscaped(data, err.Error())
+		data = append(data, `","original":"`...)
+		data = appendJSONEscaped(data, e.Message)
+		data = append(data, `"}`...)
+		b.Write(data)
+	} else if f.EnablePrettyPrint {
+		// Indent reads data while writing into b, use a scratch buffer
+		scratch := GetBuffer()
+		defer PutBuffer(scratch)
+		scratch.Write(data)
+		_ = json.Indent(b, scratch.Bytes(), "", "  ")
+	} else {
+		b.Write(data)
+	}
+
+	b.WriteByte('\n')
 
-	if f.DisableTrace {
-		serializeEntry.Gid = 0
-		serializeEntry.TraceId = ""
+	return bufferCopy(b)
+}
+
+// appendKey appends ,"key": when the key isn't left out nor replaced by one
+// of the flattened fields, reporting whether the value should follow
+func appendKey(dst []byte, name, def string, flat []KV) ([]byte, bool) {
+	key := configuredKey(name, def)
+	if key == "" || hasTopLevelKey(flat, key) {
+		return dst, false
 	}
+	dst = append(dst, ',')
+	dst = appendJSONString(dst, key)
+	return append(dst, ':'), true
+}
 
-	if f.DisableCaller {
-		serializeEntry.File = ""
-		serializeEntry.CallerLine = 0
-		serializeEntry.CallerFunc = ""
-		serializeEntry.CallerDir = ""
-		serializeEntry.CallerName = ""
+// appendEntry appends e as a JSON object
+func (f *JSONFormatter) appendEntry(dst []byte, e *Entry) ([]byte, error) {
+	var ok bool
+	enc := &f.Encoder
+	keys := &enc.Keys
+	flat := f.flatFields(e)
+
+	// Every member starts with a comma, the first one is dropped at the end
+	open := len(dst)
+	dst = append(dst, '{')
+
+	if e.TimeStrSet || !e.Time.IsZero() {
+		if dst, ok = appendKey(dst, keys.Time, "time", flat); ok {
+			dst = appendJSONTime(dst, e, enc.Time)
+		}
+	}
+	if dst, ok = appendKey(dst, keys.Level, "level", flat); ok {
+		switch {
+		case enc.Level == LevelEncodingNumeric:
+			dst = strconv.AppendUint(dst, uint64(e.Level), 10)
+		case enc.Level == LevelEncodingUpper && int(e.Level) < len(upperLevels):
+			dst = appendJSONString(dst, upperLevels[e.Level])
+		default:
+			dst = appendJSONString(dst, e.Level.String())
+		}
+	}
+	if dst, ok = appendKey(dst, keys.Message, "message", flat); ok {
+		dst = appendJSONString(dst, e.Message)
+	}
+	if dst, ok = appendKey(dst, keys.Pid, "pid", flat); ok {
+		dst = strconv.AppendInt(dst, int64(e.Pid), 10)
 	}
 
-	var data []byte
-	var err error
+	if !f.DisableTrace {
+		if e.Gid != 0 {
+			if dst, ok = appendKey(dst, keys.Gid, "gid", flat); ok {
+				dst = strconv.AppendInt(dst, e.Gid, 10)
+			}
+		}
+		if e.TraceId != "" {
+			if dst, ok = appendKey(dst, keys.TraceID, "trace_id", flat); ok {
+				dst = appendJSONString(dst, e.TraceId)
+			}
+		}
+	}
 
-	if f.EnablePrettyPrint {
-		data, err = json.MarshalIndent(&serializeEntry, "", "  ")
-	} else {
-		data, err = json.Marshal(&serializeEntry)
+	if !f.DisableCaller && e.File != "" {
+		dst = f.appendCaller(dst, e)
 	}
 
-	if err != nil {
-		// Fallback to error message if JSON marshaling fails
-		b.WriteString(`{"level":"error","message":"JSON marshaling failed: `)
-		b.WriteString(err.Error())
-		b.WriteString(`","original":"`)
-		b.WriteString(jsonEscapeString(e.Message))
-		b.WriteString(`"}`)
-	} else {
-		b.Write(data)
+	if len(e.PrefixMsg) > 0 {
+		if dst, ok = appendKey(dst, keys.Prefix, "prefix_msg", flat); ok {
+			dst = append(dst, '"')
+			dst = appendJSONEscaped(dst, string(e.PrefixMsg))
+			dst = append(dst, '"')
+		}
+	}
+	if len(e.SuffixMsg) > 0 {
+		if dst, ok = appendKey(dst, keys.Suffix, "suffix_msg", flat); ok {
+			dst = append(dst, '"')
+			dst = appendJSONEscaped(dst, string(e.SuffixMsg))
+			dst = append(dst, '"')
+		}
 	}
 
-	b.WriteByte('\n')
-	return b.Bytes()
+	if len(e.Fields) > 0 {
+		var err error
+		if enc.FlattenFields {
+			dst = append(dst, ',')
+		} else if dst, ok = appendKey(dst, keys.Fields, "fields", flat); ok {
+			dst = append(dst, '{')
+		}
+
+		if enc.FlattenFields || ok {
+			var fields []KV
+			dst, fields = appendEncodedFields(dst, e, ',')
+			if dst, err = appendJSONFields(dst, fields); err != nil {
+				return dst, err
+			}
+			if !enc.FlattenFields {
+				dst = append(dst, '}')
+			}
+		}
+	}
+
+	if len(dst) > open+1 && dst[open+1] == ',' {
+		dst = append(dst[:open+1], dst[open+2:]...)
+	}
+	return append(dst, '}'), nil
 }
 
-// jsonEscapeString escapes special characters for JSON strings
-// Optimized with bytes.Buffer and pre-allocation to reduce allocations
-func jsonEscapeString(s string) string {
-	if s == "" {
-		return ""
-	}
-
-	// Pre-allocate capacity to reduce reallocations
-	estimatedLen := len(s) + len(s)/4
-	var result bytes.Buffer
-	result.Grow(estimatedLen)
-
-	for _, r := range s {
-		switch r {
-		case '"':
-			result.WriteString(`\"`)
-		case '\\':
-			result.WriteString(`\\`)
-		case '\n':
-			result.WriteString(`\n`)
-		case '\r':
-			result.WriteString(`\r`)
-		case '\t':
-			result.WriteString(`\t`)
-		default:
-			if r < 32 {
-				result.WriteString(`\u00`)
-				result.WriteByte(hexByte(byte(r) >> 4))
-				result.WriteByte(hexByte(byte(r) & 0x0F))
+// flatFields returns the fields of e written as top level keys, if any
+func (f *JSONFormatter) flatFields(e *Entry) []KV {
+	if f.Encoder.FlattenFields {
+		return e.Fields
+	}
+	return nil
+}
+
+// appendJSONTime appends the time of e encoded as te
+func appendJSONTime(dst []byte, e *Entry, te TimeEncoding) []byte {
+	switch te {
+	case TimeEncodingRFC3339:
+		dst = append(dst, '"')
+		dst = e.Time.AppendFormat(dst, time.RFC3339)
+		return append(dst, '"')
+	case TimeEncodingEpochSeconds:
+		return strconv.AppendInt(dst, e.Time.Unix(), 10)
+	case TimeEncodingEpochMillis:
+		return strconv.AppendInt(dst, e.Time.UnixMilli(), 10)
+	case TimeEncodingEpochNanos:
+		return strconv.AppendInt(dst, e.Time.UnixNano(), 10)
+	}
+	if e.TimeStrSet {
+		// Already formatted as RFC 3339 with nanoseconds by the logger
+		return appendJSONString(dst, e.TimeStr)
+	}
+	dst = append(dst, '"')
+	dst = e.Time.AppendFormat(dst, time.RFC3339Nano)
+	return append(dst, '"')
+}
+
+// appendCaller appends the caller of e as configured
+func (f *JSONFormatter) appendCaller(dst []byte, e *Entry) []byte {
+	var ok bool
+	keys := &f.Encoder.Keys
+	flat := f.flatFields(e)
+
+	switch f.Encoder.Caller {
+	case CallerEncodingShort, CallerEncodingFull:
+		if dst, ok = appendKey(dst, keys.Caller, "caller", flat); ok {
+			dst = append(dst, '"')
+			if f.Encoder.Caller == CallerEncodingShort {
+				dst = appendJSONEscaped(dst, path.Join(e.CallerDir, path.Base(e.File)))
 			} else {
-				result.WriteRune(r)
+				dst = appendJSONEscaped(dst, e.File)
 			}
+			dst = append(dst, ':')
+			dst = strconv.AppendInt(dst, int64(e.CallerLine), 10)
+			dst = append(dst, '"')
+		}
+		if dst, ok = appendKey(dst, keys.CallerFunc, "caller_func", flat); ok {
+			dst = appendJSONString(dst, e.CallerFunc)
+		}
+		return dst
+	}
+
+	if dst, ok = appendKey(dst, keys.CallerFile, "caller_file", flat); ok {
+		dst = appendJSONString(dst, e.File)
+	}
+	if dst, ok = appendKey(dst, keys.CallerLine, "caller_line", flat); ok {
+		dst = strconv.AppendInt(dst, int64(e.CallerLine), 10)
+	}
+	if dst, ok = appendKey(dst, keys.CallerFunc, "caller_func", flat); ok {
+		dst = appendJSONString(dst, e.CallerFunc)
+	}
+	if e.CallerDir != "" {
+		if dst, ok = appendKey(dst, keys.CallerDir, "caller_dir", flat); ok {
+			dst = appendJSONString(dst, e.CallerDir)
+		}
+	}
+	if e.CallerName != "" {
+		if dst, ok = appendKey(dst, keys.CallerName, "caller_name", flat); ok {
+			dst = appendJSONString(dst, e.CallerName)
 		}
 	}
-	return result.String()
+	return dst
+}
+
+// EncodeFields implements constant.FieldsEncoder.
+// It returns nil for fields opening a namespace or holding a value it can't
+// encode, leaving them to Format.
+func (f *JSONFormatter) EncodeFields(fields []KV) []byte {
+	if hasNamespace(fields) {
+		return nil
+	}
+	data, err := appendJSONFields(nil, fields)
+	if err != nil {
+		return nil
+	}
+	return data
+}
+
+// jsonEscapeString escapes special characters for JSON strings
+func jsonEscapeString(s string) string {
+	return string(appendJSONEscaped(nil, s))
 }
 
 // hexByte converts a byte to its hex character
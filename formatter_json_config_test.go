@@ -0,0 +1,253 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newJSONConfigEntry() *Entry {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 123456789, time.UTC)
+	return &Entry{
+		Level:      WarnLevel,
+		Message:    "disk low",
+		Time:       ts,
+		TimeStr:    ts.Format(time.RFC3339Nano),
+		TimeStrSet: true,
+		Pid:        7,
+		File:       "/src/app/store/disk.go",
+		CallerDir:  "store",
+		CallerFunc: "Check",
+		CallerLine: 42,
+		Fields:     []KV{{Key: "free", Value: 3}, String("mount", "/data")},
+	}
+}
+
+func formatJSONConfig(t *testing.T, enc JSONEncoderConfig, entry *Entry) string {
+	t.Helper()
+	out := (&JSONFormatter{Encoder: enc}).Format(entry)
+	if !json.Valid(out) {
+		t.Fatalf("output should be valid JSON: %s", out)
+	}
+	return strings.TrimSuffix(string(out), "\n")
+}
+
+func TestJSONEncoderConfig_Default(t *testing.T) {
+	got := formatJSONConfig(t, JSONEncoderConfig{}, newJSONConfigEntry())
+	want := `{"time":"2024-01-02T03:04:05.123456789Z","level":"warn","message":"disk low","pid":7,` +
+		`"caller_file":"/src/app/store/disk.go","caller_line":42,"caller_func":"Check","caller_dir":"store",` +
+		`"fields":{"free":3,"mount":"/data"}}`
+	if got != want {
+		t.Errorf("default output =\n%s want\n%s", got, want)
+	}
+}
+
+func TestJSONEncoderConfig_ELK(t *testing.T) {
+	got := formatJSONConfig(t, JSONEncoderConfig{
+		Keys:          JSONKeys{Time: "@timestamp", Level: "severity", Message: "msg", Pid: "-"},
+		FlattenFields: true,
+		Time:          TimeEncodingEpochMillis,
+		Level:         LevelEncodingUpper,
+		Caller:        CallerEncodingShort,
+	}, newJSONConfigEntry())
+	want := `{"@timestamp":1704164645123,"severity":"WARN","msg":"disk low",` +
+		`"caller":"store/disk.go:42","caller_func":"Check","free":3,"mount":"/data"}`
+	if got != want {
+		t.Errorf("ELK output =\n%s want\n%s", got, want)
+	}
+}
+
+func TestJSONEncoderConfig_Time(t *testing.T) {
+	tests := []struct {
+		enc  TimeEncoding
+		want string
+	}{
+		{TimeEncodingRFC3339Nano, `"2024-01-02T03:04:05.123456789Z"`},
+		{TimeEncodingRFC3339, `"2024-01-02T03:04:05Z"`},
+		{TimeEncodingEpochSeconds, `1704164645`},
+		{TimeEncodingEpochMillis, `1704164645123`},
+		{TimeEncodingEpochNanos, `1704164645123456789`},
+	}
+	for _, tt := range tests {
+		got := formatJSONConfig(t, JSONEncoderConfig{Time: tt.enc}, newJSONConfigEntry())
+		if !strings.HasPrefix(got, `{"time":`+tt.want+`,`) {
+			t.Errorf("time encoding %d: got %s, want time %s", tt.enc, got, tt.want)
+		}
+	}
+
+	// Entries without a time leave the key out
+	got := formatJSONConfig(t, JSONEncoderConfig{Time: TimeEncodingEpochSeconds}, &Entry{Message: "m"})
+	if strings.Contains(got, `"time"`) {
+		t.Errorf("entry without time should have no time key: %s", got)
+	}
+}
+
+func TestJSONEncoderConfig_Level(t *testing.T) {
+	tests := []struct {
+		enc  LevelEncoding
+		want string
+	}{
+		{LevelEncodingLower, `"level":"warn"`},
+		{LevelEncodingUpper, `"level":"WARN"`},
+		{LevelEncodingNumeric, `"level":3`},
+	}
+	for _, tt := range tests {
+		got := formatJSONConfig(t, JSONEncoderConfig{Level: tt.enc}, newJSONConfigEntry())
+		if !strings.Contains(got, tt.want) {
+			t.Errorf("level encoding %d: got %s, want %s", tt.enc, got, tt.want)
+		}
+	}
+}
+
+func TestJSONEncoderConfig_Caller(t *testing.T) {
+	got := formatJSONConfig(t, JSONEncoderConfig{Caller: CallerEncodingFull, Keys: JSONKeys{CallerFunc: "-"}}, newJSONConfigEntry())
+	if want := `"pid":7,"caller":"/src/app/store/disk.go:42","fields"`; !strings.Contains(got, want) {
+		t.Errorf("full caller: got %s, want %s", got, want)
+	}
+
+	got = formatJSONConfig(t, JSONEncoderConfig{Keys: JSONKeys{CallerFile: "file", CallerLine: "line", CallerDir: "-"}}, newJSONConfigEntry())
+	if want := `"file":"/src/app/store/disk.go","line":42,"caller_func":"Check","fields"`; !strings.Contains(got, want) {
+		t.Errorf("split caller: got %s, want %s", got, want)
+	}
+}
+
+func TestJSONEncoderConfig_OmitKeys(t *testing.T) {
+	entry := newJSONConfigEntry()
+	entry.PrefixMsg = []byte("[svc]")
+	entry.TraceId = "abc"
+	entry.Gid = 9
+
+	got := formatJSONConfig(t, JSONEncoderConfig{Keys: JSONKeys{
+		Time:    "-",
+		Level:   "-",
+		Gid:     "-",
+		TraceID: "trace",
+		Caller:  "-",
+		Prefix:  "prefix",
+		Fields:  "ctx",
+	}, Caller: CallerEncodingShort}, entry)
+	want := `{"message":"disk low","pid":7,"trace":"abc","caller_func":"Check","prefix":"[svc]","ctx":{"free":3,"mount":"/data"}}`
+	if got != want {
+		t.Errorf("output =\n%s want\n%s", got, want)
+	}
+
+	got = formatJSONConfig(t, JSONEncoderConfig{Keys: JSONKeys{Fields: "-"}}, newJSONConfigEntry())
+	if strings.Contains(got, "free") {
+		t.Errorf("fields should be left out: %s", got)
+	}
+}
+
+func TestJSONEncoderConfig_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	f := &JSONFormatter{EnablePrettyPrint: true, Encoder: JSONEncoderConfig{
+		Keys:          JSONKeys{Message: "msg"},
+		FlattenFields: true,
+		Level:         LevelEncodingNumeric,
+	}}
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(f)
+
+	logger.With("svc", "api", Namespace("req")).Infow("done", "path", "/a")
+
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("output should be valid JSON: %v\n%s", err, buf.String())
+	}
+	if got["msg"] != "done" || got["level"] != float64(InfoLevel) || got["svc"] != "api" {
+		t.Errorf("unexpected output %s", buf.String())
+	}
+	if req, ok := got["req"].(map[string]interface{}); !ok || req["path"] != "/a" {
+		t.Errorf("namespaced fields should stay nested when flattened: %s", buf.String())
+	}
+
+	// Bound fields encoded once are flattened as well
+	buf.Reset()
+	f.EnablePrettyPrint = false
+	logger.SetFormatter(f).With("svc", "api").Infow("done", "n", 1)
+	if want := `,"svc":"api","n":1}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("output %s should contain %s", buf.String(), want)
+	}
+}
+
+func TestJSONEncoderConfig_Fallback(t *testing.T) {
+	f := &JSONFormatter{Encoder: JSONEncoderConfig{Keys: JSONKeys{Level: "severity", Message: "msg"}}}
+	out := f.Format(&Entry{Message: "m", Fields: []KV{{Key: "ch", Value: make(chan int)}}})
+	if !strings.HasPrefix(string(out), `{"severity":"error","msg":"JSON marshaling failed: `) {
+		t.Errorf("fallback should use the configured keys: %s", out)
+	}
+
+	f.Encoder.Keys = JSONKeys{Level: "-", Message: "-"}
+	out = f.Format(&Entry{Message: "m", Fields: []KV{{Key: "ch", Value: make(chan int)}}})
+	if !strings.HasPrefix(string(out), `{"level":"error","message":"JSON marshaling failed: `) {
+		t.Errorf("fallback should use the default keys when left out: %s", out)
+	}
+}
+
+func BenchmarkJSONEncoderConfig_ELK(b *testing.B) {
+	f := &JSONFormatter{Encoder: JSONEncoderConfig{
+		Keys:          JSONKeys{Time: "@timestamp", Level: "severity", Message: "msg"},
+		FlattenFields: true,
+		Time:          TimeEncodingEpochMillis,
+		Level:         LevelEncodingUpper,
+	}}
+	entry := newJSONConfigEntry()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = f.Format(entry)
+	}
+}
+
+func TestJSONFormatter_DuplicateKeys(t *testing.T) {
+	var buf bytes.Buffer
+	f := &JSONFormatter{}
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(f)
+
+	// A call-site field replaces the bound field, as the map it used to be did
+	logger.With("user", "bob", "svc", "api").Infow("login", "user", "alice", "n", 1, "n", 2)
+	if want := `"fields":{"svc":"api","user":"alice","n":2}}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("output %s should contain %s", buf.String(), want)
+	}
+
+	// Keys only clash within the same object
+	buf.Reset()
+	logger.Infow("nested", "id", 1, Namespace("req"), "id", 2)
+	if want := `"fields":{"id":1,"req":{"id":2}}}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("output %s should contain %s", buf.String(), want)
+	}
+
+	// Flattened fields replace the keys they are named like
+	buf.Reset()
+	f.Encoder.FlattenFields = true
+	logger.SetFormatter(f).With("level", "custom").Infow("flat", "message", "override")
+	if !json.Valid(buf.Bytes()) || strings.Count(buf.String(), `"level"`) != 1 || strings.Count(buf.String(), `"message"`) != 1 {
+		t.Fatalf("keys should appear once: %s", buf.String())
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["level"] != "custom" || got["message"] != "override" {
+		t.Errorf("fields should win over entry keys: %s", buf.String())
+	}
+}
+
+func TestJSONFormatter_DuplicateKeysEncodedOnce(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetFormatter(&JSONFormatter{}).With("svc", "api", "user", "bob")
+
+	// Call-site keys repeating each other keep the pre-encoded bound fields
+	entry := &Entry{Fields: append(append([]KV{}, logger.config().fields...), KV{Key: "n", Value: 1}, KV{Key: "n", Value: 2})}
+	logger.config().attachEncodedFields(entry)
+	logger.config().uniqueFields(entry)
+	if entry.EncodedFieldsLen != 2 || len(entry.Fields) != 3 {
+		t.Errorf("bound fields should stay encoded, got %d encoded of %v", entry.EncodedFieldsLen, entry.Fields)
+	}
+
+	// Replacing a bound field encodes them again
+	logger.Infow("login", "user", "alice")
+	if want := `"fields":{"svc":"api","user":"alice"}}`; !strings.Contains(buf.String(), want) {
+		t.Errorf("output %s should contain %s", buf.String(), want)
+	}
+}
@@ -9,25 +9,25 @@
 func TestJSONFormatter_Usage(t *testing.T) {
 	// Example 1: Basic JSON logging
 	logger := New()
-	logger.Format = &JSONFormatter{}
+	logger.SetFormatter(&JSONFormatter{})
 
 	// This will output JSON format instead of text
 	logger.Info("JSON log message")
 
 	// Example 2: Pretty print JSON
-	logger.Format = &JSONFormatter{EnablePrettyPrint: true}
+	logger.SetFormatter(&JSONFormatter{EnablePrettyPrint: true})
 	logger.Info("Pretty JSON log")
 
 	// Example 3: JSON with disabled caller
-		logger.Format = &JSONFormatter{
+		logger.SetFormatter(&JSONFormatter{
 		DisableCaller: true,
-	}
+	})
 	logger.Info("JSON without caller info")
 
 	// Example 4: JSON with disabled trace
-	logger.Format = &JSONFormatter{
+	logger.SetFormatter(&JSONFormatter{
 		DisableTrace: true,
-	}
+	})
 	logger.Error("JSON without trace")
 }
 
@@ -39,7 +39,7 @@ func TestJSONFormatter_Integration(t *testing.T) {
 	logger.EnableTrace(false)
 
 	// Switch to JSON output
-	logger.Format = &JSONFormatter{}
+	logger.SetFormatter(&JSONFormatter{})
 
 	// Log at different levels
 	logger.Trace("Trace message in JSON")
@@ -54,7 +54,7 @@ func TestJSONFormatter_Integration(t *testing.T) {
 
 func BenchmarkJSONFormatter_Basic(b *testing.B) {
 	logger := New()
-	logger.Format = &JSONFormatter{}
+	logger.SetFormatter(&JSONFormatter{})
 	logger.SetLevel(InfoLevel)
 	logger.EnableCaller(false)
 	logger.EnableTrace(false)
@@ -67,13 +67,13 @@ func BenchmarkJSONFormatter_Basic(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = logger.Format.Format(entry)
+		_ = logger.Formatter().Format(entry)
 	}
 }
 
 func BenchmarkJSONFormatter_WithAllFields(b *testing.B) {
 	logger := New()
-	logger.Format = &JSONFormatter{}
+	logger.SetFormatter(&JSONFormatter{})
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
@@ -87,13 +87,13 @@ func BenchmarkJSONFormatter_WithAllFields(b *testing.B) {
 			CallerLine: 42,
 			CallerFunc: "BenchmarkJSONFormatter",
 		}
-		_ = logger.Format.Format(entry)
+		_ = logger.Formatter().Format(entry)
 	}
 }
 
 func BenchmarkJSONFormatter_PrettyPrint(b *testing.B) {
 	logger := New()
-	logger.Format = &JSONFormatter{EnablePrettyPrint: true}
+	logger.SetFormatter(&JSONFormatter{EnablePrettyPrint: true})
 
 	entry := &Entry{
 		Level:   InfoLevel,
@@ -103,6 +103,6 @@ func BenchmarkJSONFormatter_PrettyPrint(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_ = logger.Format.Format(entry)
+		_ = logger.Formatter().Format(entry)
 	}
 }
@@ -186,7 +186,7 @@ func TestJSONFormatterCoverage(t *testing.T) {
 		var buf bytes.Buffer
 		logger := New()
 		logger.SetOutput(&buf)
-		logger.Format = &JSONFormatter{}
+		logger.SetFormatter(&JSONFormatter{})
 		logger.EnableCaller(false)
 		logger.EnableTrace(false)
 
@@ -218,7 +218,7 @@ func TestJSONFormatterCoverage(t *testing.T) {
 		var buf bytes.Buffer
 		logger := New()
 		logger.SetOutput(&buf)
-		logger.Format = &JSONFormatter{}
+		logger.SetFormatter(&JSONFormatter{})
 		logger.EnableCaller(false)
 		logger.EnableTrace(true)
 
@@ -244,7 +244,7 @@ func TestJSONFormatterCoverage(t *testing.T) {
 		formatter := &JSONFormatter{
 			EnablePrettyPrint: true,
 		}
-		logger.Format = formatter
+		logger.SetFormatter(formatter)
 		logger.EnableCaller(false)
 		logger.EnableTrace(false)
 
@@ -0,0 +1,156 @@
+package log
+
+import (
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// LogfmtKeys names the keys written by LogfmtFormatter. An empty name keeps
+// the default, "-" leaves the key out.
+type LogfmtKeys struct {
+	Time    string // Default "time"
+	Level   string // Default "level"
+	Message string // Default "msg"
+	Caller  string // Default "caller", as dir/file.go:line
+	TraceID string // Default "trace_id"
+	Prefix  string // Default "prefix"
+	Suffix  string // Default "suffix"
+}
+
+// LogfmtFormatter implements FormatFull interface for logfmt output, as read
+// by Loki, promtail and most log shippers:
+//
+//	time=2024-01-02T03:04:05Z level=info msg="user logged in" caller=api/login.go:42 trace_id=abc user=bob
+//
+// Keys come in a fixed order: time, level, msg, caller, trace_id, prefix,
+// suffix, then fields as they were logged. Values holding spaces, quotes,
+// '=' or control characters are quoted and escaped; in keys these bytes are
+// replaced by '_'.
+type LogfmtFormatter struct {
+	DisableParsingAndEscaping bool // Write the message unquoted, only line breaks escaped
+	DisableCaller             bool // Disable caller information
+	DisableTrace              bool // Disable trace information
+	Keys                      LogfmtKeys
+}
+
+// Format implements constant.Format interface
+func (f *LogfmtFormatter) Format(entry interface{}) []byte {
+	// Type assert to *Entry
+	e, ok := entry.(*Entry)
+	if !ok {
+		return nil
+	}
+
+	b := GetBuffer()
+	defer PutBuffer(b)
+
+	b.Write(f.appendEntry(b.AvailableBuffer(), e))
+	b.WriteByte('\n')
+
+	return bufferCopy(b)
+}
+
+// appendEntry appends e as a logfmt line, without the newline
+func (f *LogfmtFormatter) appendEntry(dst []byte, e *Entry) []byte {
+	enc := &textEncoder{buf: dst, first: true, quote: true}
+
+	if key := configuredKey(f.Keys.Time, "time"); key != "" && e.TimeStrSet {
+		enc.AddString(key, e.TimeStr)
+	}
+	if key := configuredKey(f.Keys.Level, "level"); key != "" {
+		enc.AddString(key, e.Level.String())
+	}
+	if key := configuredKey(f.Keys.Message, "msg"); key != "" {
+		msg := strings.TrimSpace(e.Message)
+		if f.DisableParsingAndEscaping {
+			enc.key(key)
+			enc.buf = appendLineBreaksEscaped(enc.buf, msg)
+		} else {
+			enc.AddString(key, msg)
+		}
+	}
+	if key := configuredKey(f.Keys.Caller, "caller"); key != "" && !f.DisableCaller && e.File != "" {
+		start := enc.key(key)
+		enc.buf = append(enc.buf, path.Join(e.CallerDir, path.Base(e.File))...)
+		enc.buf = append(enc.buf, ':')
+		enc.buf = strconv.AppendInt(enc.buf, int64(e.CallerLine), 10)
+		enc.quoteFrom(start)
+	}
+	if key := configuredKey(f.Keys.TraceID, "trace_id"); key != "" && !f.DisableTrace && e.TraceId != "" {
+		enc.AddString(key, e.TraceId)
+	}
+	if key := configuredKey(f.Keys.Prefix, "prefix"); key != "" && len(e.PrefixMsg) > 0 {
+		enc.AddString(key, string(e.PrefixMsg))
+	}
+	if key := configuredKey(f.Keys.Suffix, "suffix"); key != "" && len(e.SuffixMsg) > 0 {
+		enc.AddString(key, string(e.SuffixMsg))
+	}
+
+	if len(e.Fields) > 0 {
+		if !enc.first {
+			enc.buf = append(enc.buf, ' ')
+		}
+		var fields []KV
+		enc.buf, fields = appendEncodedFields(enc.buf, e, ' ')
+		enc.buf = appendTextFields(enc.buf, fields, true)
+	}
+
+	return enc.buf
+}
+
+// appendLineBreaksEscaped appends s with line breaks escaped as \n and \r,
+// keeping the entry on one line
+func appendLineBreaksEscaped(dst []byte, s string) []byte {
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\n':
+			dst = append(dst, '\\', 'n')
+		case '\r':
+			dst = append(dst, '\\', 'r')
+		default:
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// configuredKey resolves a configured key name: empty for the default, "-" to
+// leave the key out, reported as ""
+func configuredKey(name, def string) string {
+	switch name {
+	case "":
+		return def
+	case "-":
+		return ""
+	default:
+		return name
+	}
+}
+
+// EncodeFields implements constant.FieldsEncoder.
+// Fields opening a namespace are left to Format.
+func (f *LogfmtFormatter) EncodeFields(fields []KV) []byte {
+	if hasNamespace(fields) {
+		return nil
+	}
+	return appendTextFields(nil, fields, true)
+}
+
+// ParsingAndEscaping sets message quoting and escaping
+func (f *LogfmtFormatter) ParsingAndEscaping(disable bool) {
+	f.DisableParsingAndEscaping = disable
+}
+
+// Caller sets caller information display
+func (f *LogfmtFormatter) Caller(disable bool) {
+	f.DisableCaller = disable
+}
+
+// Clone creates a copy of LogfmtFormatter
+func (f *LogfmtFormatter) Clone() constant.Format {
+	clone := *f
+	return &clone
+}
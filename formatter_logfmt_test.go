@@ -0,0 +1,163 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/constant"
+)
+
+var _ constant.FormatFull = (*LogfmtFormatter)(nil)
+
+func newLogfmtEntry() *Entry {
+	return &Entry{
+		Level:      InfoLevel,
+		Message:    "user logged in",
+		TimeStr:    "2024-01-02T03:04:05Z",
+		TimeStrSet: true,
+		File:       "/src/app/api/login.go",
+		CallerDir:  "api",
+		CallerLine: 42,
+		TraceId:    "abc",
+		Fields:     []KV{{Key: "user", Value: "bob"}, Int("attempts", 2)},
+	}
+}
+
+func TestLogfmtFormatter_Format(t *testing.T) {
+	f := &LogfmtFormatter{}
+
+	got := string(f.Format(newLogfmtEntry()))
+	want := `time=2024-01-02T03:04:05Z level=info msg="user logged in" caller=api/login.go:42 trace_id=abc user=bob attempts=2` + "\n"
+	if got != want {
+		t.Errorf("Format() =\n%s want\n%s", got, want)
+	}
+
+	if f.Format("not an entry") != nil {
+		t.Error("Format should return nil for other types")
+	}
+}
+
+func TestLogfmtFormatter_Quoting(t *testing.T) {
+	tests := []struct {
+		value interface{}
+		want  string
+	}{
+		{"plain", `k=plain`},
+		{"", `k=""`},
+		{"two words", `k="two words"`},
+		{`say "hi"`, `k="say \"hi\""`},
+		{"a=b", `k="a=b"`},
+		{`back\slash`, `k="back\\slash"`},
+		{"line\nbreak", `k="line\nbreak"`},
+		{"tab\there", `k="tab\there"`},
+		{"ünïcode", `k=ünïcode`},
+		{"bad\xffutf8", `k="bad�utf8"`},
+		{3.5, `k=3.5`},
+		{time.Second, `k=1s`},
+		{[]string{"a", "b"}, `k="[a b]"`},
+	}
+	f := &LogfmtFormatter{Keys: LogfmtKeys{Time: "-", Level: "-", Message: "-"}}
+	for _, tt := range tests {
+		got := strings.TrimSuffix(string(f.Format(&Entry{Fields: []KV{Any("k", tt.value)}})), "\n")
+		if got != tt.want {
+			t.Errorf("value %#v: got %s, want %s", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestLogfmtFormatter_Keys(t *testing.T) {
+	f := &LogfmtFormatter{Keys: LogfmtKeys{
+		Time:    "ts",
+		Level:   "lvl",
+		Message: "message",
+		Caller:  "src",
+		TraceID: "-",
+	}}
+
+	got := string(f.Format(newLogfmtEntry()))
+	want := `ts=2024-01-02T03:04:05Z lvl=info message="user logged in" src=api/login.go:42 user=bob attempts=2` + "\n"
+	if got != want {
+		t.Errorf("Format() =\n%s want\n%s", got, want)
+	}
+}
+
+func TestLogfmtFormatter_KeySanitizing(t *testing.T) {
+	f := &LogfmtFormatter{Keys: LogfmtKeys{Time: "-", Level: "log level", Message: `"msg"`}}
+	entry := &Entry{Level: InfoLevel, Message: "m", Fields: []KV{
+		{Key: "a b", Value: 1},
+		{Key: "x=y", Value: 2},
+		{Key: "tab\tnl\n", Value: 3},
+		Namespace("req id"),
+		{Key: "path", Value: "/"},
+	}}
+
+	got := string(f.Format(entry))
+	want := `log_level=info _msg_=m a_b=1 x_y=2 tab_nl_=3 req_id.path=/` + "\n"
+	if got != want {
+		t.Errorf("Format() =\n%s want\n%s", got, want)
+	}
+}
+
+func TestLogfmtFormatter_RawMessageLineBreaks(t *testing.T) {
+	f := &LogfmtFormatter{DisableParsingAndEscaping: true, Keys: LogfmtKeys{Time: "-", Level: "-"}}
+
+	got := string(f.Format(&Entry{Message: "first\nsecond\r\nthird"}))
+	if want := `msg=first\nsecond\r\nthird` + "\n"; got != want {
+		t.Errorf("Format() = %q want %q", got, want)
+	}
+}
+
+func TestLogfmtFormatter_Options(t *testing.T) {
+	entry := newLogfmtEntry()
+	entry.Message = "a \"b\"\n"
+	entry.PrefixMsg = []byte("[svc]")
+	entry.SuffixMsg = []byte("end")
+
+	f := &LogfmtFormatter{}
+	f.Caller(true)
+	f.ParsingAndEscaping(true)
+	f.DisableTrace = true
+
+	got := string(f.Format(entry))
+	want := `time=2024-01-02T03:04:05Z level=info msg=a "b" prefix=[svc] suffix=end user=bob attempts=2` + "\n"
+	if got != want {
+		t.Errorf("Format() =\n%s want\n%s", got, want)
+	}
+
+	clone := f.Clone().(*LogfmtFormatter)
+	clone.Caller(false)
+	if !f.DisableCaller || !clone.DisableParsingAndEscaping || !clone.DisableTrace {
+		t.Error("Clone should copy the settings without sharing them")
+	}
+}
+
+func TestLogfmtFormatter_Logger(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(&LogfmtFormatter{})
+
+	logger.With("svc", "api", Namespace("req")).Infow("done", "path", "/a b", Object("user", &testCustomer{Name: "bob"}))
+
+	want := `level=info msg=done svc=api req.path="/a b" req.user.name=bob`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output %q should contain %q", buf.String(), want)
+	}
+
+	buf.Reset()
+	logger.With("svc", "api gw").Infow("done", "n", 1)
+	want = `level=info msg=done svc="api gw" n=1`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("output %q should contain %q", buf.String(), want)
+	}
+}
+
+func BenchmarkLogfmtFormatter_Format(b *testing.B) {
+	f := &LogfmtFormatter{}
+	entry := newLogfmtEntry()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = f.Format(entry)
+	}
+}
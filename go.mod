@@ -6,3 +6,5 @@ require (
 	github.com/lazygophers/log/constant v0.0.0-20260505024342-2c291363de69
 	github.com/petermattis/goid v0.0.0-20260113132338-7c7de50cc741
 )
+
+replace github.com/lazygophers/log/constant => ./constant
@@ -3,3 +3,5 @@ module github.com/lazygophers/log/hooks
 go 1.26.2
 
 require github.com/lazygophers/log/constant v0.0.0-20260505024342-2c291363de69
+
+replace github.com/lazygophers/log/constant => ../constant
@@ -1,7 +1,11 @@
 package hooks
 
 import (
+	"reflect"
 	"regexp"
+	"sort"
+	"sync"
+	"sync/atomic"
 	"unicode/utf8"
 
 	"github.com/lazygophers/log/constant"
@@ -13,6 +17,16 @@
 // HookFunc is exported from constant package for convenience
 type HookFunc = constant.HookFunc
 
+// EntryHook is exported from constant package for convenience
+type EntryHook = constant.EntryHook
+
+// EntryHookFunc is exported from constant package for convenience
+type EntryHookFunc = constant.EntryHookFunc
+
+// All built-in hooks implement both EntryHook, used by the Logger, and the
+// legacy Hook interface. OnWrite passes values other than *constant.Entry
+// through unchanged.
+
 // SensitiveDataMaskHook masks sensitive data in log messages
 type SensitiveDataMaskHook struct {
 	// Patterns to mask (regex patterns)
@@ -27,9 +41,9 @@ type SensitiveDataMaskHook struct {
 func NewSensitiveDataMaskHook() *SensitiveDataMaskHook {
 	return &SensitiveDataMaskHook{
 		patterns: []*regexp.Regexp{
-			regexp.MustCompile(`\b\d{4}[- ]?\d{4}[- ]?\d{4}[- ]?\d{4}\b`), // Credit card
-			regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Z|a-z]{2,}\b`), // Email
-			regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`), // SSN
+			regexp.MustCompile(`\b\d{4}[- ]?\d{4}[- ]?\d{4}[- ]?\d{4}\b`),                  // Credit card
+			regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Z|a-z]{2,}\b`),      // Email
+			regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`),                                    // SSN
 			regexp.MustCompile(`\b(?:password|passwd|pwd|token|secret|key)\s*[:=]\s*\S+`), // Password fields
 			regexp.MustCompile(`"(?:password|passwd|pwd|token|secret|key)"\s*:\s*"[^"]+"`), // JSON password
 		},
@@ -58,49 +72,40 @@ func (h *SensitiveDataMaskHook) AddMaskField(key string) {
 	h.maskFields[key] = true
 }
 
-// OnWrite implements Hook interface
-func (h *SensitiveDataMaskHook) OnWrite(entry interface{}) interface{} {
-	// Type assertion to access Entry fields
-	// This works because we pass the actual *Entry from logger
-	type entryLike struct {
-		Message    string
-		Fields     []interface{}
-		File       string
-		CallerName string
-	}
-
+// OnEntry implements EntryHook interface
+// Masks the message, string field values and caller info; fields whose key
+// was registered with AddMaskField are replaced by the mask entirely.
+func (h *SensitiveDataMaskHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
 	if entry == nil {
-		return nil
-	}
-
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
+		return nil, false
 	}
 
 	// Mask in message
-	e.Message = h.maskString(e.Message)
+	entry.Message = h.maskString(entry.Message)
 
 	// Mask in fields
-	for i, field := range e.Fields {
-		if fieldMap, ok := field.(map[string]interface{}); ok {
-			// Handle field as map
-			for key, val := range fieldMap {
-				if h.maskFields[key] {
-					fieldMap[key] = h.mask
-				} else if strVal, ok := val.(string); ok {
-					fieldMap[key] = h.maskString(strVal)
-				}
-			}
-			e.Fields[i] = fieldMap
+	for i := range entry.Fields {
+		field := &entry.Fields[i]
+		if field.Type == constant.FieldTypeNamespace {
+			continue
+		}
+		if h.maskFields[field.Key] {
+			*field = constant.KV{Key: field.Key, Value: h.mask}
+		} else if strVal, ok := field.Any().(string); ok {
+			*field = constant.KV{Key: field.Key, Value: h.maskString(strVal)}
 		}
 	}
 
 	// Mask in caller info
-	e.File = h.maskString(e.File)
-	e.CallerName = h.maskString(e.CallerName)
+	entry.File = h.maskString(entry.File)
+	entry.CallerName = h.maskString(entry.CallerName)
 
-	return entry
+	return entry, true
+}
+
+// OnWrite implements Hook interface
+func (h *SensitiveDataMaskHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 func (h *SensitiveDataMaskHook) maskString(s string) string {
@@ -110,50 +115,73 @@ func (h *SensitiveDataMaskHook) maskString(s string) string {
 	return s
 }
 
-// ContextEnrichHook automatically adds contextual fields to log entries
+// ContextEnrichHook automatically adds contextual fields to log entries.
+// Fields can be changed while entries are being logged.
 type ContextEnrichHook struct {
+	mu     sync.Mutex // serializes changes to fields
 	fields map[string]interface{}
+
+	// kvs holds fields sorted by key, so output order is deterministic.
+	// Every change publishes a new slice, OnEntry reads it without locking.
+	kvs atomic.Pointer[[]constant.KV]
 }
 
 // NewContextEnrichHook creates a new context enrichment hook
 func NewContextEnrichHook(fields map[string]interface{}) *ContextEnrichHook {
-	return &ContextEnrichHook{fields: fields}
+	h := &ContextEnrichHook{fields: fields}
+	h.sortFields()
+	return h
 }
 
 // AddField adds a field to enrich
 func (h *ContextEnrichHook) AddField(key string, value interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	if h.fields == nil {
 		h.fields = make(map[string]interface{})
 	}
 	h.fields[key] = value
+	h.sortFields()
 }
 
 // SetFields sets multiple fields
 func (h *ContextEnrichHook) SetFields(fields map[string]interface{}) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	h.fields = fields
+	h.sortFields()
 }
 
-// OnWrite implements Hook interface
-func (h *ContextEnrichHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Fields []interface{}
-	}
-
-	if entry == nil || len(h.fields) == 0 {
-		return entry
+// sortFields publishes a new kvs built from fields
+func (h *ContextEnrichHook) sortFields() {
+	kvs := make([]constant.KV, 0, len(h.fields))
+	for key, value := range h.fields {
+		kvs = append(kvs, constant.KV{Key: key, Value: value})
 	}
+	sort.Slice(kvs, func(i, j int) bool {
+		return kvs[i].Key < kvs[j].Key
+	})
+	h.kvs.Store(&kvs)
+}
 
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
+// OnEntry implements EntryHook interface
+func (h *ContextEnrichHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
+	if entry == nil {
+		return nil, false
 	}
 
 	// Append enrichment fields
-	for key, value := range h.fields {
-		e.Fields = append(e.Fields, map[string]interface{}{key: value})
+	if kvs := h.kvs.Load(); kvs != nil {
+		entry.Fields = append(entry.Fields, *kvs...)
 	}
+	return entry, true
+}
 
-	return entry
+// OnWrite implements Hook interface
+func (h *ContextEnrichHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // LevelFilterHook filters logs by level
@@ -162,29 +190,28 @@ type LevelFilterHook struct {
 }
 
 // NewLevelFilterHook creates a new level filter hook
+// Only entries at least as severe as minLevel are kept, e.g.
+// NewLevelFilterHook(int(constant.WarnLevel)) keeps warn, error, fatal and panic.
 func NewLevelFilterHook(minLevel int) *LevelFilterHook {
 	return &LevelFilterHook{minLevel: minLevel}
 }
 
-// OnWrite implements Hook interface
-func (h *LevelFilterHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Level int
-	}
-
+// OnEntry implements EntryHook interface
+func (h *LevelFilterHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
 	if entry == nil {
-		return nil
+		return nil, false
 	}
 
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
+	// Lower levels are more severe
+	if int(entry.Level) > h.minLevel {
+		return nil, false // Filter out
 	}
+	return entry, true
+}
 
-	if e.Level < h.minLevel {
-		return nil // Filter out
-	}
-	return entry
+// OnWrite implements Hook interface
+func (h *LevelFilterHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // MessageFilterHook filters logs by message content
@@ -220,44 +247,40 @@ func (h *MessageFilterHook) AddDenyPattern(pattern string) error {
 	return nil
 }
 
-// OnWrite implements Hook interface
-func (h *MessageFilterHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Message string
-	}
-
+// OnEntry implements EntryHook interface
+func (h *MessageFilterHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
 	if entry == nil {
-		return nil
+		return nil, false
 	}
 
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
-	}
-
-	msg := e.Message
+	msg := entry.Message
 
 	// Check deny patterns first
 	for _, pattern := range h.deny {
 		if pattern.MatchString(msg) {
-			return nil // Filter out
+			return nil, false // Filter out
 		}
 	}
 
 	// If no allow patterns, allow all
 	if len(h.allow) == 0 {
-		return entry
+		return entry, true
 	}
 
 	// Check allow patterns
 	for _, pattern := range h.allow {
 		if pattern.MatchString(msg) {
-			return entry // Allow
+			return entry, true // Allow
 		}
 	}
 
 	// No allow pattern matched, filter out
-	return nil
+	return nil, false
+}
+
+// OnWrite implements Hook interface
+func (h *MessageFilterHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // FieldFilterHook filters logs by field values
@@ -272,7 +295,7 @@ type FieldFilterHook struct {
 func NewFieldFilterHook() *FieldFilterHook {
 	return &FieldFilterHook{
 		allowedFields: make(map[string]map[interface{}]bool),
-		deniedFields: make(map[string]map[interface{}]bool),
+		deniedFields:  make(map[string]map[interface{}]bool),
 	}
 }
 
@@ -292,42 +315,43 @@ func (h *FieldFilterHook) DenyField(key string, value interface{}) {
 	h.deniedFields[key][value] = true
 }
 
-// OnWrite implements Hook interface
-func (h *FieldFilterHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Fields []interface{}
-	}
-
+// OnEntry implements EntryHook interface
+func (h *FieldFilterHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
 	if entry == nil {
-		return nil
+		return nil, false
 	}
 
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
-	}
+	for _, field := range entry.Fields {
+		// Check denied values first
+		if deniedValues, ok := h.deniedFields[field.Key]; ok {
+			if containsValue(deniedValues, field.Any()) {
+				return nil, false // Filter out
+			}
+		}
 
-	for _, field := range e.Fields {
-		if fieldMap, ok := field.(map[string]interface{}); ok {
-			for key, value := range fieldMap {
-				// Check denied values first
-				if deniedValues, ok := h.deniedFields[key]; ok {
-					if deniedValues[value] {
-						return nil // Filter out
-					}
-				}
-
-				// Check allowed values
-				if allowedValues, ok := h.allowedFields[key]; ok {
-					if len(allowedValues) > 0 && !allowedValues[value] {
-						return nil // Filter out
-					}
-				}
+		// Check allowed values
+		if allowedValues, ok := h.allowedFields[field.Key]; ok {
+			if len(allowedValues) > 0 && !containsValue(allowedValues, field.Any()) {
+				return nil, false // Filter out
 			}
 		}
 	}
 
-	return entry
+	return entry, true
+}
+
+// OnWrite implements Hook interface
+func (h *FieldFilterHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
+}
+
+// containsValue reports whether value is in set.
+// Uncomparable values (slices, maps) are never in the set instead of panicking.
+func containsValue(set map[interface{}]bool, value interface{}) bool {
+	if value != nil && !reflect.TypeOf(value).Comparable() {
+		return false
+	}
+	return set[value]
 }
 
 // MinLengthHook filters logs shorter than minimum length
@@ -340,28 +364,24 @@ func NewMinLengthHook(minLength int) *MinLengthHook {
 	return &MinLengthHook{MinLength: minLength}
 }
 
-// OnWrite implements Hook interface
-func (h *MinLengthHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Message string
-	}
-
+// OnEntry implements EntryHook interface
+func (h *MinLengthHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
 	if entry == nil {
-		return nil
-	}
-
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
+		return nil, false
 	}
 
 	// Count UTF-8 characters (not bytes)
-	length := utf8.RuneCountInString(e.Message)
+	length := utf8.RuneCountInString(entry.Message)
 	if length < h.MinLength {
-		return nil // Filter out short messages
+		return nil, false // Filter out short messages
 	}
 
-	return entry
+	return entry, true
+}
+
+// OnWrite implements Hook interface
+func (h *MinLengthHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // MaxLengthHook truncates log messages longer than maximum length
@@ -378,30 +398,26 @@ func NewMaxLengthHook(maxLength int) *MaxLengthHook {
 	}
 }
 
-// OnWrite implements Hook interface
-func (h *MaxLengthHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Message string
-	}
-
+// OnEntry implements EntryHook interface
+func (h *MaxLengthHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
 	if entry == nil {
-		return nil
-	}
-
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
+		return nil, false
 	}
 
 	// Count UTF-8 characters
-	length := utf8.RuneCountInString(e.Message)
+	length := utf8.RuneCountInString(entry.Message)
 	if length > h.MaxLength {
 		// Truncate message
-		runes := []rune(e.Message)
-		e.Message = string(runes[:h.MaxLength]) + h.TruncateSuffix
+		runes := []rune(entry.Message)
+		entry.Message = string(runes[:h.MaxLength]) + h.TruncateSuffix
 	}
 
-	return entry
+	return entry, true
+}
+
+// OnWrite implements Hook interface
+func (h *MaxLengthHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // PrefixHook adds a prefix to log messages
@@ -414,23 +430,19 @@ func NewPrefixHook(prefix string) *PrefixHook {
 	return &PrefixHook{Prefix: prefix}
 }
 
-// OnWrite implements Hook interface
-func (h *PrefixHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Message string
-	}
-
-	if entry == nil || h.Prefix == "" {
-		return entry
+// OnEntry implements EntryHook interface
+func (h *PrefixHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
+	if entry == nil {
+		return nil, false
 	}
 
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
-	}
+	entry.Message = h.Prefix + entry.Message
+	return entry, true
+}
 
-	e.Message = h.Prefix + e.Message
-	return entry
+// OnWrite implements Hook interface
+func (h *PrefixHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // SuffixHook adds a suffix to log messages
@@ -443,23 +455,19 @@ func NewSuffixHook(suffix string) *SuffixHook {
 	return &SuffixHook{Suffix: suffix}
 }
 
-// OnWrite implements Hook interface
-func (h *SuffixHook) OnWrite(entry interface{}) interface{} {
-	type entryLike struct {
-		Message string
-	}
-
-	if entry == nil || h.Suffix == "" {
-		return entry
+// OnEntry implements EntryHook interface
+func (h *SuffixHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
+	if entry == nil {
+		return nil, false
 	}
 
-	e, ok := entry.(entryLike)
-	if !ok {
-		return entry
-	}
+	entry.Message = entry.Message + h.Suffix
+	return entry, true
+}
 
-	e.Message = e.Message + h.Suffix
-	return entry
+// OnWrite implements Hook interface
+func (h *SuffixHook) OnWrite(entry interface{}) interface{} {
+	return EntryHookFunc(h.OnEntry).OnWrite(entry)
 }
 
 // ConditionalHook executes a hook only when condition is met
@@ -469,6 +477,7 @@ type ConditionalHook struct {
 }
 
 // NewConditionalHook creates a new conditional hook
+// The condition receives the *constant.Entry being logged
 func NewConditionalHook(condition func(interface{}) bool, hook constant.Hook) *ConditionalHook {
 	return &ConditionalHook{
 		condition: condition,
@@ -476,8 +485,22 @@ func NewConditionalHook(condition func(interface{}) bool, hook constant.Hook) *C
 	}
 }
 
+// OnEntry implements EntryHook interface
+func (h *ConditionalHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
+	if entry == nil {
+		return nil, false
+	}
+	if h.hook == nil || !h.condition(entry) {
+		return entry, true
+	}
+	return runHook(h.hook, entry)
+}
+
 // OnWrite implements Hook interface
 func (h *ConditionalHook) OnWrite(entry interface{}) interface{} {
+	if e, ok := entry.(*constant.Entry); ok {
+		return EntryHookFunc(h.OnEntry).OnWrite(e)
+	}
 	if entry == nil || !h.condition(entry) {
 		return entry
 	}
@@ -494,8 +517,28 @@ func NewChainHook(hooks ...constant.Hook) *ChainHook {
 	return &ChainHook{hooks: hooks}
 }
 
+// OnEntry implements EntryHook interface
+func (h *ChainHook) OnEntry(entry *constant.Entry) (*constant.Entry, bool) {
+	if entry == nil {
+		return nil, false
+	}
+
+	for _, hook := range h.hooks {
+		var keep bool
+		entry, keep = runHook(hook, entry)
+		if !keep {
+			return nil, false // Stop chain if hook filtered
+		}
+	}
+
+	return entry, true
+}
+
 // OnWrite implements Hook interface
 func (h *ChainHook) OnWrite(entry interface{}) interface{} {
+	if e, ok := entry.(*constant.Entry); ok {
+		return EntryHookFunc(h.OnEntry).OnWrite(e)
+	}
 	if entry == nil {
 		return nil
 	}
@@ -509,3 +552,24 @@ func (h *ChainHook) OnWrite(entry interface{}) interface{} {
 
 	return entry
 }
+
+// runHook applies hook to entry, preferring the typed EntryHook interface
+func runHook(hook constant.Hook, entry *constant.Entry) (*constant.Entry, bool) {
+	if h, ok := hook.(constant.EntryHook); ok {
+		e, keep := h.OnEntry(entry)
+		if !keep || e == nil {
+			return nil, false
+		}
+		return e, true
+	}
+
+	result := hook.OnWrite(entry)
+	if result == nil {
+		return nil, false
+	}
+	if e, ok := result.(*constant.Entry); ok {
+		return e, true
+	}
+	// Non-*Entry result keeps the original entry
+	return entry, true
+}
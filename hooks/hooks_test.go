@@ -1,6 +1,8 @@
 package hooks
 
 import (
+	"strconv"
+	"sync"
 	"testing"
 
 	"github.com/lazygophers/log/constant"
@@ -582,3 +584,221 @@ func TestChainHook(t *testing.T) {
 		}
 	})
 }
+
+func newEntry(level constant.Level, msg string, fields ...constant.KV) *constant.Entry {
+	return &constant.Entry{Level: level, Message: msg, Fields: fields}
+}
+
+func TestBuiltinHooksOperateOnEntry(t *testing.T) {
+	t.Run("SensitiveDataMaskHook_masks_message_and_fields", func(t *testing.T) {
+		hook := NewSensitiveDataMaskHook()
+		entry := newEntry(constant.InfoLevel, "mail test@example.com",
+			constant.KV{Key: "password", Value: "hunter2"},
+			constant.KV{Key: "note", Value: "ssn 123-45-6789"},
+			constant.KV{Key: "count", Value: 3},
+		)
+
+		result := hook.OnWrite(entry)
+		e, ok := result.(*constant.Entry)
+		if !ok {
+			t.Fatalf("OnWrite should return *constant.Entry, got %T", result)
+		}
+		if e.Message != "mail ***" {
+			t.Errorf("message should be masked, got %q", e.Message)
+		}
+		if e.Fields[0].Value != "***" {
+			t.Errorf("password field should be masked, got %v", e.Fields[0].Value)
+		}
+		if e.Fields[1].Value != "ssn ***" {
+			t.Errorf("string field values should be masked, got %v", e.Fields[1].Value)
+		}
+		if e.Fields[2].Value != 3 {
+			t.Errorf("non-string values should be untouched, got %v", e.Fields[2].Value)
+		}
+	})
+
+	t.Run("SensitiveDataMaskHook_masks_typed_fields", func(t *testing.T) {
+		hook := NewSensitiveDataMaskHook()
+		entry := newEntry(constant.InfoLevel, "msg",
+			constant.KV{Key: "token", Type: constant.FieldTypeString, Value: "abc"},
+			constant.KV{Key: "note", Type: constant.FieldTypeString, Value: "mail test@example.com"},
+			constant.KV{Key: "secret", Type: constant.FieldTypeNamespace},
+		)
+
+		e, _ := hook.OnEntry(entry)
+		if e.Fields[0].Any() != "***" {
+			t.Errorf("typed field should be masked, got %v", e.Fields[0].Any())
+		}
+		if e.Fields[1].Any() != "mail ***" {
+			t.Errorf("typed string values should be masked, got %v", e.Fields[1].Any())
+		}
+		if e.Fields[2].Type != constant.FieldTypeNamespace {
+			t.Error("namespaces should be left alone")
+		}
+
+		filter := NewFieldFilterHook()
+		filter.DenyField("env", "test")
+		if _, keep := filter.OnEntry(newEntry(constant.InfoLevel, "m",
+			constant.KV{Key: "env", Type: constant.FieldTypeString, Value: "test"})); keep {
+			t.Error("typed field values should be filtered too")
+		}
+	})
+
+	t.Run("ContextEnrichHook_appends_sorted_fields", func(t *testing.T) {
+		hook := NewContextEnrichHook(map[string]interface{}{"version": "1.0", "service": "api"})
+		entry := newEntry(constant.InfoLevel, "msg", constant.KV{Key: "k", Value: "v"})
+
+		e, keep := hook.OnEntry(entry)
+		if !keep {
+			t.Fatal("ContextEnrichHook should keep entries")
+		}
+		if len(e.Fields) != 3 || e.Fields[1].Key != "service" || e.Fields[2].Key != "version" {
+			t.Errorf("enrichment fields should be appended in key order, got %v", e.Fields)
+		}
+	})
+
+	t.Run("LevelFilterHook_keeps_severe_levels", func(t *testing.T) {
+		hook := NewLevelFilterHook(int(constant.WarnLevel))
+
+		if hook.OnWrite(newEntry(constant.InfoLevel, "info")) != nil {
+			t.Error("info should be filtered below warn")
+		}
+		if hook.OnWrite(newEntry(constant.WarnLevel, "warn")) == nil {
+			t.Error("warn should be kept")
+		}
+		if hook.OnWrite(newEntry(constant.ErrorLevel, "error")) == nil {
+			t.Error("error should be kept")
+		}
+	})
+
+	t.Run("MessageFilterHook_allow_and_deny", func(t *testing.T) {
+		hook := NewMessageFilterHook()
+		_ = hook.AddAllowPattern(`^order`)
+		_ = hook.AddDenyPattern(`debug`)
+
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "order created")); !keep {
+			t.Error("allowed message should be kept")
+		}
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "order debug dump")); keep {
+			t.Error("denied message should be filtered")
+		}
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "user created")); keep {
+			t.Error("message matching no allow pattern should be filtered")
+		}
+	})
+
+	t.Run("FieldFilterHook_filters_on_fields", func(t *testing.T) {
+		hook := NewFieldFilterHook()
+		hook.DenyField("env", "test")
+		hook.AllowField("region", "eu")
+
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "m", constant.KV{Key: "env", Value: "test"})); keep {
+			t.Error("denied field value should be filtered")
+		}
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "m", constant.KV{Key: "region", Value: "us"})); keep {
+			t.Error("field value outside the allow list should be filtered")
+		}
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "m", constant.KV{Key: "region", Value: "eu"})); !keep {
+			t.Error("allowed field value should be kept")
+		}
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "m", constant.KV{Key: "env", Value: []int{1}})); !keep {
+			t.Error("uncomparable values should not panic and be kept")
+		}
+	})
+
+	t.Run("MinLengthHook_filters_short_messages", func(t *testing.T) {
+		hook := NewMinLengthHook(5)
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "héll")); keep {
+			t.Error("message shorter than minimum should be filtered")
+		}
+		if _, keep := hook.OnEntry(newEntry(constant.InfoLevel, "héllo")); !keep {
+			t.Error("message of minimum length should be kept")
+		}
+	})
+
+	t.Run("MaxLengthHook_truncates_message", func(t *testing.T) {
+		hook := NewMaxLengthHook(5)
+		e, _ := hook.OnEntry(newEntry(constant.InfoLevel, "日本語のメッセージ"))
+		if e.Message != "日本語のメ..." {
+			t.Errorf("message should be truncated by runes, got %q", e.Message)
+		}
+	})
+
+	t.Run("PrefixHook_and_SuffixHook_modify_message", func(t *testing.T) {
+		entry := newEntry(constant.InfoLevel, "msg")
+		NewPrefixHook("[p] ").OnWrite(entry)
+		NewSuffixHook(" [s]").OnWrite(entry)
+		if entry.Message != "[p] msg [s]" {
+			t.Errorf("unexpected message %q", entry.Message)
+		}
+	})
+
+	t.Run("ConditionalHook_with_entry", func(t *testing.T) {
+		isError := func(entry interface{}) bool {
+			e, ok := entry.(*constant.Entry)
+			return ok && e.Level == constant.ErrorLevel
+		}
+		hook := NewConditionalHook(isError, NewPrefixHook("ALERT "))
+
+		errEntry := newEntry(constant.ErrorLevel, "boom")
+		infoEntry := newEntry(constant.InfoLevel, "fine")
+		hook.OnWrite(errEntry)
+		hook.OnWrite(infoEntry)
+
+		if errEntry.Message != "ALERT boom" {
+			t.Errorf("hook should apply when condition holds, got %q", errEntry.Message)
+		}
+		if infoEntry.Message != "fine" {
+			t.Errorf("hook should not apply when condition fails, got %q", infoEntry.Message)
+		}
+	})
+
+	t.Run("ChainHook_mixes_typed_and_legacy_hooks", func(t *testing.T) {
+		legacy := constant.HookFunc(func(entry interface{}) interface{} {
+			if e, ok := entry.(*constant.Entry); ok {
+				e.Message += "!"
+			}
+			return entry
+		})
+		hook := NewChainHook(NewPrefixHook("> "), legacy, NewMinLengthHook(100))
+
+		entry := newEntry(constant.InfoLevel, "hi")
+		if result := hook.OnWrite(entry); result != nil {
+			t.Error("chain should stop when a hook filters")
+		}
+		if entry.Message != "> hi!" {
+			t.Errorf("hooks before the filter should have run in order, got %q", entry.Message)
+		}
+	})
+}
+
+func TestContextEnrichHook_ConcurrentChanges(t *testing.T) {
+	hook := NewContextEnrichHook(map[string]interface{}{"service": "api"})
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			hook.AddField("k"+strconv.Itoa(i%10), i)
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			e, _ := hook.OnEntry(newEntry(constant.InfoLevel, "msg"))
+			for j := 1; j < len(e.Fields); j++ {
+				if e.Fields[j-1].Key >= e.Fields[j].Key {
+					t.Errorf("fields should stay sorted, got %v", e.Fields)
+					return
+				}
+			}
+		}
+	}()
+	wg.Wait()
+
+	var zero ContextEnrichHook
+	if e, keep := zero.OnEntry(newEntry(constant.InfoLevel, "msg")); !keep || len(e.Fields) != 0 {
+		t.Errorf("zero hook should keep entries unchanged, got %v", e.Fields)
+	}
+}
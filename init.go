@@ -32,6 +32,29 @@ func GetLevel() Level {
 	return std.Level()
 }
 
+// SetAtomicLevel makes the standard logger use level
+func SetAtomicLevel(level *AtomicLevel) *Logger {
+	return std.SetAtomicLevel(level)
+}
+
+// GetAtomicLevel returns the standard logger's level handle,
+// which can be mounted as an http.Handler
+func GetAtomicLevel() *AtomicLevel {
+	return std.AtomicLevel()
+}
+
+// SetModuleLevels sets per-package level overrides on the standard logger,
+// see ModuleLevels for the spec format
+func SetModuleLevels(spec string) error {
+	return std.SetModuleLevels(spec)
+}
+
+// SetAsync makes the standard logger format and write on a background worker,
+// see Logger.SetAsync
+func SetAsync(size int) *Logger {
+	return std.SetAsync(size)
+}
+
 // Sync flushes all buffered log entries to their output destinations
 func Sync() {
 	std.Sync()
@@ -42,6 +65,20 @@ func Clone() *Logger {
 	return std.Clone()
 }
 
+// With returns a child of the standard logger with bound key-value fields
+func With(kv ...interface{}) *Logger {
+	return std.With(kv...)
+}
+
+// WithFields returns a child of the standard logger with bound fields
+func WithFields(fields map[string]interface{}) *Logger {
+	return std.WithFields(fields)
+}
+
+// AddContextExtractor registers context extractors on the standard logger
+func AddContextExtractor(extractors ...ContextExtractor) *Logger {
+	return std.AddContextExtractor(extractors...)
+}
 
 // SetCallerDepth sets the caller stack depth
 func SetCallerDepth(callerDepth int) *Logger {
@@ -16,8 +16,8 @@ func TestNew(t *testing.T) {
 	if logger == nil {
 		t.Fatal("New() returned nil")
 	}
-	if logger.level != DebugLevel {
-		t.Errorf("Expected default level DebugLevel, got %v", logger.level)
+	if logger.Level() != DebugLevel {
+		t.Errorf("Expected default level DebugLevel, got %v", logger.Level())
 	}
 }
 
@@ -26,8 +26,8 @@ func TestClone(t *testing.T) {
 	if cloned == nil {
 		t.Fatal("Clone() returned nil")
 	}
-	if cloned.level != std.level {
-		t.Errorf("Cloned logger level mismatch: expected %v, got %v", std.level, cloned.level)
+	if cloned.Level() != std.Level() {
+		t.Errorf("Cloned logger level mismatch: expected %v, got %v", std.Level(), cloned.Level())
 	}
 }
 
@@ -41,11 +41,11 @@ func TestSetCallerDepth(t *testing.T) {
 	if result != std {
 		t.Error("SetCallerDepth should return std logger")
 	}
-	if std.callerDepth != 5 {
-		t.Errorf("Expected callerDepth 5, got %d", std.callerDepth)
+	if std.config().callerDepth != 5 {
+		t.Errorf("Expected callerDepth 5, got %d", std.config().callerDepth)
 	}
 	// Reset to default
-	std.callerDepth = 4
+	std.SetCallerDepth(4)
 }
 
 func TestSetPrefixMsg(t *testing.T) {
@@ -53,11 +53,11 @@ func TestSetPrefixMsg(t *testing.T) {
 	if result != std {
 		t.Error("SetPrefixMsg should return std logger")
 	}
-	if string(std.PrefixMsg) != "[test]" {
-		t.Errorf("Expected PrefixMsg [test], got %s", string(std.PrefixMsg))
+	if string(std.Prefix()) != "[test]" {
+		t.Errorf("Expected PrefixMsg [test], got %s", string(std.Prefix()))
 	}
 	// Reset
-	std.PrefixMsg = nil
+	std.SetPrefixMsg("")
 }
 
 func TestAppendPrefixMsg(t *testing.T) {
@@ -66,11 +66,11 @@ func TestAppendPrefixMsg(t *testing.T) {
 	if result != std {
 		t.Error("AppendPrefixMsg should return std logger")
 	}
-	if string(std.PrefixMsg) != "[a][b]" {
-		t.Errorf("Expected PrefixMsg [a][b], got %s", string(std.PrefixMsg))
+	if string(std.Prefix()) != "[a][b]" {
+		t.Errorf("Expected PrefixMsg [a][b], got %s", string(std.Prefix()))
 	}
 	// Reset
-	std.PrefixMsg = nil
+	std.SetPrefixMsg("")
 }
 
 func TestSetSuffixMsg(t *testing.T) {
@@ -78,11 +78,11 @@ func TestSetSuffixMsg(t *testing.T) {
 	if result != std {
 		t.Error("SetSuffixMsg should return std logger")
 	}
-	if string(std.SuffixMsg) != "[test]" {
-		t.Errorf("Expected SuffixMsg [test], got %s", string(std.SuffixMsg))
+	if string(std.Suffix()) != "[test]" {
+		t.Errorf("Expected SuffixMsg [test], got %s", string(std.Suffix()))
 	}
 	// Reset
-	std.SuffixMsg = nil
+	std.SetSuffixMsg("")
 }
 
 func TestAppendSuffixMsg(t *testing.T) {
@@ -91,11 +91,11 @@ func TestAppendSuffixMsg(t *testing.T) {
 	if result != std {
 		t.Error("AppendSuffixMsg should return std logger")
 	}
-	if string(std.SuffixMsg) != "[a][b]" {
-		t.Errorf("Expected SuffixMsg [a][b], got %s", string(std.SuffixMsg))
+	if string(std.Suffix()) != "[a][b]" {
+		t.Errorf("Expected SuffixMsg [a][b], got %s", string(std.Suffix()))
 	}
 	// Reset
-	std.SuffixMsg = nil
+	std.SetSuffixMsg("")
 }
 
 func TestParsingAndEscaping(t *testing.T) {
@@ -0,0 +1,437 @@
+package log
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// appendJSONString appends s as a quoted JSON string
+func appendJSONString(dst []byte, s string) []byte {
+	dst = append(dst, '"')
+	dst = appendJSONEscaped(dst, s)
+	return append(dst, '"')
+}
+
+// appendJSONEscaped appends s escaped for use inside a JSON string.
+// Invalid UTF-8 is replaced by U+FFFD like encoding/json does; HTML
+// characters are left as is.
+func appendJSONEscaped(dst []byte, s string) []byte {
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c < utf8.RuneSelf {
+			if c >= 0x20 && c != '"' && c != '\\' {
+				i++
+				continue
+			}
+
+			dst = append(dst, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				dst = append(dst, '\\', c)
+			case '\n':
+				dst = append(dst, '\\', 'n')
+			case '\r':
+				dst = append(dst, '\\', 'r')
+			case '\t':
+				dst = append(dst, '\\', 't')
+			default:
+				dst = append(dst, '\\', 'u', '0', '0', hexByte(c>>4), hexByte(c&0x0F))
+			}
+			i++
+			start = i
+			continue
+		}
+
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, "\ufffd"...)
+			i += size
+			start = i
+			continue
+		}
+		// Line and paragraph separators break JavaScript string literals
+		if r == '\u2028' || r == '\u2029' {
+			dst = append(dst, s[start:i]...)
+			dst = append(dst, '\\', 'u', '2', '0', '2', hexByte(byte(r&0x0F)))
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	return append(dst, s[start:]...)
+}
+
+// appendJSONFloat appends f the way encoding/json does, encoding NaN and
+// infinities, which JSON cannot represent, as strings
+func appendJSONFloat(dst []byte, f float64, bits int) []byte {
+	switch {
+	case math.IsNaN(f):
+		return append(dst, `"NaN"`...)
+	case math.IsInf(f, 1):
+		return append(dst, `"+Inf"`...)
+	case math.IsInf(f, -1):
+		return append(dst, `"-Inf"`...)
+	}
+
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 {
+		if bits == 32 {
+			abs = float64(float32(abs))
+		}
+		if abs < 1e-6 || abs >= 1e21 {
+			format = 'e'
+		}
+	}
+	dst = strconv.AppendFloat(dst, f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		if n := len(dst); n >= 4 && dst[n-4] == 'e' && dst[n-3] == '-' && dst[n-2] == '0' {
+			dst[n-2] = dst[n-1]
+			dst = dst[:n-1]
+		}
+	}
+	return dst
+}
+
+// appendJSONValue appends v as JSON. Common types are encoded directly;
+// anything else goes through encoding/json, whose error is returned.
+func appendJSONValue(dst []byte, v interface{}) ([]byte, error) {
+	switch val := v.(type) {
+	case nil:
+		return append(dst, "null"...), nil
+	case string:
+		return appendJSONString(dst, val), nil
+	case []byte:
+		dst = append(dst, '"')
+		dst = base64.StdEncoding.AppendEncode(dst, val)
+		return append(dst, '"'), nil
+	case int:
+		return strconv.AppendInt(dst, int64(val), 10), nil
+	case int8:
+		return strconv.AppendInt(dst, int64(val), 10), nil
+	case int16:
+		return strconv.AppendInt(dst, int64(val), 10), nil
+	case int32:
+		return strconv.AppendInt(dst, int64(val), 10), nil
+	case int64:
+		return strconv.AppendInt(dst, val, 10), nil
+	case uint:
+		return strconv.AppendUint(dst, uint64(val), 10), nil
+	case uint8:
+		return strconv.AppendUint(dst, uint64(val), 10), nil
+	case uint16:
+		return strconv.AppendUint(dst, uint64(val), 10), nil
+	case uint32:
+		return strconv.AppendUint(dst, uint64(val), 10), nil
+	case uint64:
+		return strconv.AppendUint(dst, val, 10), nil
+	case float32:
+		return appendJSONFloat(dst, float64(val), 32), nil
+	case float64:
+		return appendJSONFloat(dst, val, 64), nil
+	case bool:
+		return strconv.AppendBool(dst, val), nil
+	case time.Duration:
+		return strconv.AppendInt(dst, int64(val), 10), nil
+	case time.Time:
+		dst = append(dst, '"')
+		dst = val.AppendFormat(dst, time.RFC3339Nano)
+		return append(dst, '"'), nil
+	case ObjectMarshaler:
+		return appendJSONObject(dst, val)
+	case ArrayMarshaler:
+		return appendJSONArray(dst, val)
+	case json.Marshaler:
+		// Checked before error, types choosing their JSON form win
+		return appendJSONMarshal(dst, val)
+	case error:
+		return appendJSONString(dst, val.Error()), nil
+	default:
+		return appendJSONMarshal(dst, val)
+	}
+}
+
+// appendJSONMarshal appends v encoded by encoding/json
+func appendJSONMarshal(dst []byte, v interface{}) ([]byte, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return dst, err
+	}
+	return append(dst, data...), nil
+}
+
+// appendJSONField appends the value of field as JSON
+func appendJSONField(dst []byte, field KV) ([]byte, error) {
+	switch field.Type {
+	case constant.FieldTypeInt64, constant.FieldTypeDuration:
+		return strconv.AppendInt(dst, field.Int, 10), nil
+	case constant.FieldTypeFloat64:
+		return appendJSONFloat(dst, math.Float64frombits(uint64(field.Int)), 64), nil
+	case constant.FieldTypeBool:
+		return strconv.AppendBool(dst, field.Int != 0), nil
+	case constant.FieldTypeTime:
+		dst = append(dst, '"')
+		dst = field.Any().(time.Time).AppendFormat(dst, time.RFC3339Nano)
+		return append(dst, '"'), nil
+	case constant.FieldTypeError, constant.FieldTypeStringer:
+		if s, ok := fieldString(field); ok {
+			return appendJSONString(dst, s), nil
+		}
+	}
+	// Loose values and typed strings
+	return appendJSONValue(dst, field.Value)
+}
+
+// appendJSONFields appends fields as comma separated "key":value members.
+// A namespace opens an object holding the fields after it.
+func appendJSONFields(dst []byte, fields []KV) ([]byte, error) {
+	var err error
+	open := 0
+	first := true
+	for _, field := range fields {
+		mark, sep := len(dst), !first
+		if sep {
+			dst = append(dst, ',')
+		}
+		dst = appendJSONString(dst, field.Key)
+		dst = append(dst, ':')
+
+		if field.Type == constant.FieldTypeNamespace {
+			dst = append(dst, '{')
+			open++
+			first = true
+			continue
+		}
+		first = false
+
+		if dst, err = appendJSONField(dst, field); err != nil {
+			if !isMarshaler(field) {
+				return dst, err
+			}
+			// Replace what the marshaler wrote by its error
+			dst = dst[:mark]
+			if sep {
+				dst = append(dst, ',')
+			}
+			dst = appendJSONString(dst, field.Key+"Error")
+			dst = append(dst, ':')
+			dst = appendJSONString(dst, err.Error())
+		}
+	}
+	for range open {
+		dst = append(dst, '}')
+	}
+	return dst, nil
+}
+
+// hasTopLevelKey reports whether a field outside any namespace has key
+func hasTopLevelKey(fields []KV, key string) bool {
+	for _, field := range fields {
+		if field.Key == key {
+			return true
+		}
+		if field.Type == constant.FieldTypeNamespace {
+			return false
+		}
+	}
+	return false
+}
+
+// appendJSONObject appends obj as a JSON object
+func appendJSONObject(dst []byte, obj ObjectMarshaler) ([]byte, error) {
+	enc := &jsonEncoder{buf: dst}
+	err := enc.object(obj)
+	return enc.buf, err
+}
+
+// appendJSONArray appends arr as a JSON array
+func appendJSONArray(dst []byte, arr ArrayMarshaler) ([]byte, error) {
+	enc := &jsonEncoder{buf: dst}
+	err := enc.array(arr)
+	return enc.buf, err
+}
+
+// jsonEncoder is the ObjectEncoder and ArrayEncoder of JSONFormatter
+type jsonEncoder struct {
+	buf   []byte
+	first bool // nothing written yet in the current object or array
+}
+
+// sep separates a member or element from the previous one
+func (e *jsonEncoder) sep() {
+	if !e.first {
+		e.buf = append(e.buf, ',')
+	}
+	e.first = false
+}
+
+// object writes obj as a nested object, reusing the encoder
+func (e *jsonEncoder) object(obj ObjectMarshaler) error {
+	e.buf = append(e.buf, '{')
+	e.first = true
+	err := obj.MarshalLogObject(e)
+	e.buf = append(e.buf, '}')
+	e.first = false
+	return err
+}
+
+// array writes arr as a nested array, reusing the encoder
+func (e *jsonEncoder) array(arr ArrayMarshaler) error {
+	e.buf = append(e.buf, '[')
+	e.first = true
+	err := arr.MarshalLogArray(e)
+	e.buf = append(e.buf, ']')
+	e.first = false
+	return err
+}
+
+// appendTime writes t as RFC 3339 with nanoseconds
+func (e *jsonEncoder) appendTime(t time.Time) {
+	e.buf = append(e.buf, '"')
+	e.buf = t.AppendFormat(e.buf, time.RFC3339Nano)
+	e.buf = append(e.buf, '"')
+}
+
+// key starts a member
+func (e *jsonEncoder) key(key string) {
+	e.sep()
+	e.buf = appendJSONString(e.buf, key)
+	e.buf = append(e.buf, ':')
+}
+
+// AddString implements ObjectEncoder
+func (e *jsonEncoder) AddString(key, val string) {
+	e.key(key)
+	e.buf = appendJSONString(e.buf, val)
+}
+
+// AddInt implements ObjectEncoder
+func (e *jsonEncoder) AddInt(key string, val int) {
+	e.key(key)
+	e.buf = strconv.AppendInt(e.buf, int64(val), 10)
+}
+
+// AddInt64 implements ObjectEncoder
+func (e *jsonEncoder) AddInt64(key string, val int64) {
+	e.key(key)
+	e.buf = strconv.AppendInt(e.buf, val, 10)
+}
+
+// AddFloat64 implements ObjectEncoder
+func (e *jsonEncoder) AddFloat64(key string, val float64) {
+	e.key(key)
+	e.buf = appendJSONFloat(e.buf, val, 64)
+}
+
+// AddBool implements ObjectEncoder
+func (e *jsonEncoder) AddBool(key string, val bool) {
+	e.key(key)
+	e.buf = strconv.AppendBool(e.buf, val)
+}
+
+// AddDuration implements ObjectEncoder, in nanoseconds
+func (e *jsonEncoder) AddDuration(key string, val time.Duration) {
+	e.key(key)
+	e.buf = strconv.AppendInt(e.buf, int64(val), 10)
+}
+
+// AddTime implements ObjectEncoder, as RFC 3339 with nanoseconds
+func (e *jsonEncoder) AddTime(key string, val time.Time) {
+	e.key(key)
+	e.appendTime(val)
+}
+
+// AddObject implements ObjectEncoder
+func (e *jsonEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	e.key(key)
+	return e.object(obj)
+}
+
+// AddArray implements ObjectEncoder
+func (e *jsonEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	e.key(key)
+	return e.array(arr)
+}
+
+// AddAny implements ObjectEncoder. Nothing is added when val can't be encoded.
+func (e *jsonEncoder) AddAny(key string, val interface{}) (err error) {
+	mark, first := len(e.buf), e.first
+	e.key(key)
+	if e.buf, err = appendJSONValue(e.buf, val); err != nil {
+		e.buf, e.first = e.buf[:mark], first
+	}
+	return err
+}
+
+// AppendString implements ArrayEncoder
+func (e *jsonEncoder) AppendString(val string) {
+	e.sep()
+	e.buf = appendJSONString(e.buf, val)
+}
+
+// AppendInt implements ArrayEncoder
+func (e *jsonEncoder) AppendInt(val int) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, int64(val), 10)
+}
+
+// AppendInt64 implements ArrayEncoder
+func (e *jsonEncoder) AppendInt64(val int64) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, val, 10)
+}
+
+// AppendFloat64 implements ArrayEncoder
+func (e *jsonEncoder) AppendFloat64(val float64) {
+	e.sep()
+	e.buf = appendJSONFloat(e.buf, val, 64)
+}
+
+// AppendBool implements ArrayEncoder
+func (e *jsonEncoder) AppendBool(val bool) {
+	e.sep()
+	e.buf = strconv.AppendBool(e.buf, val)
+}
+
+// AppendDuration implements ArrayEncoder, in nanoseconds
+func (e *jsonEncoder) AppendDuration(val time.Duration) {
+	e.sep()
+	e.buf = strconv.AppendInt(e.buf, int64(val), 10)
+}
+
+// AppendTime implements ArrayEncoder, as RFC 3339 with nanoseconds
+func (e *jsonEncoder) AppendTime(val time.Time) {
+	e.sep()
+	e.appendTime(val)
+}
+
+// AppendObject implements ArrayEncoder
+func (e *jsonEncoder) AppendObject(obj ObjectMarshaler) error {
+	e.sep()
+	return e.object(obj)
+}
+
+// AppendArray implements ArrayEncoder
+func (e *jsonEncoder) AppendArray(arr ArrayMarshaler) error {
+	e.sep()
+	return e.array(arr)
+}
+
+// AppendAny implements ArrayEncoder. Nothing is appended when val can't be encoded.
+func (e *jsonEncoder) AppendAny(val interface{}) (err error) {
+	mark, first := len(e.buf), e.first
+	e.sep()
+	if e.buf, err = appendJSONValue(e.buf, val); err != nil {
+		e.buf, e.first = e.buf[:mark], first
+	}
+	return err
+}
@@ -0,0 +1,260 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAppendJSONString(t *testing.T) {
+	inputs := []string{
+		"",
+		"plain",
+		`quote " and \ backslash`,
+		"new\nline\r\ttab",
+		"\x00\x01\x1f\x7f",
+		"中文 ünïcödé 🚀",
+		"<html>&amp;",
+		"bad utf8 \xff end",
+		"separators \u2028 \u2029",
+	}
+
+	for _, in := range inputs {
+		out := appendJSONString(nil, in)
+
+		var got string
+		if err := json.Unmarshal(out, &got); err != nil {
+			t.Fatalf("appendJSONString(%q) = %s is not valid JSON: %v", in, out, err)
+		}
+		want := strings.ToValidUTF8(in, "\ufffd")
+		if got != want {
+			t.Errorf("appendJSONString(%q) round-trips to %q, want %q", in, got, want)
+		}
+	}
+
+	if got := string(appendJSONString(nil, "a\u2028b")); got != `"a\u2028b"` {
+		t.Errorf("line separator should be escaped, got %s", got)
+	}
+}
+
+type jsonMarshalerErr struct{}
+
+func (jsonMarshalerErr) Error() string { return "as error" }
+
+func (jsonMarshalerErr) MarshalJSON() ([]byte, error) { return []byte(`{"custom":true}`), nil }
+
+func TestAppendJSONValue(t *testing.T) {
+	ts := time.Date(2024, 1, 2, 3, 4, 5, 6, time.UTC)
+	tests := []struct {
+		in   interface{}
+		want string
+	}{
+		{nil, `null`},
+		{"s", `"s"`},
+		{[]byte("hi"), `"aGk="`},
+		{-42, `-42`},
+		{int8(-8), `-8`},
+		{int16(16), `16`},
+		{int32(32), `32`},
+		{int64(math.MinInt64), `-9223372036854775808`},
+		{uint(7), `7`},
+		{uint8(8), `8`},
+		{uint16(16), `16`},
+		{uint32(32), `32`},
+		{uint64(math.MaxUint64), `18446744073709551615`},
+		{1.5, `1.5`},
+		{float32(0.1), `0.1`},
+		{1e21, `1e+21`},
+		{1e-7, `1e-7`},
+		{math.NaN(), `"NaN"`},
+		{math.Inf(1), `"+Inf"`},
+		{math.Inf(-1), `"-Inf"`},
+		{true, `true`},
+		{time.Second, `1000000000`},
+		{ts, `"2024-01-02T03:04:05.000000006Z"`},
+		{errors.New("boom"), `"boom"`},
+		{jsonMarshalerErr{}, `{"custom":true}`},
+		{map[string]int{"b": 2, "a": 1}, `{"a":1,"b":2}`},
+		{[]int{1, 2}, `[1,2]`},
+	}
+
+	for _, tt := range tests {
+		got, err := appendJSONValue(nil, tt.in)
+		if err != nil {
+			t.Errorf("appendJSONValue(%#v) error: %v", tt.in, err)
+			continue
+		}
+		if string(got) != tt.want {
+			t.Errorf("appendJSONValue(%#v) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+
+	if _, err := appendJSONValue(nil, make(chan int)); err == nil {
+		t.Error("unsupported values should return the encoding/json error")
+	}
+}
+
+func TestJSONFormatter_KeyOrder(t *testing.T) {
+	entry := &Entry{
+		Level:      WarnLevel,
+		Message:    "msg",
+		Pid:        1,
+		Gid:        2,
+		TraceId:    "trace",
+		File:       "/src/app/main.go",
+		CallerLine: 3,
+		CallerFunc: "main",
+		CallerDir:  "app",
+		TimeStr:    "2024-01-02T03:04:05Z",
+		TimeStrSet: true,
+		PrefixMsg:  []byte("[p]"),
+		SuffixMsg:  []byte("[s]"),
+		Fields:     []KV{{Key: "z", Value: 1}, {Key: "a", Value: "x"}},
+	}
+
+	want := `{"time":"2024-01-02T03:04:05Z","level":"warn","message":"msg","pid":1,"gid":2,"trace_id":"trace",` +
+		`"caller_file":"/src/app/main.go","caller_line":3,"caller_func":"main","caller_dir":"app",` +
+		`"prefix_msg":"[p]","suffix_msg":"[s]","fields":{"z":1,"a":"x"}}` + "\n"
+	for range 3 {
+		if got := string((&JSONFormatter{}).Format(entry)); got != want {
+			t.Fatalf("Format =\n%s\nwant\n%s", got, want)
+		}
+	}
+
+	got := string((&JSONFormatter{DisableCaller: true, DisableTrace: true}).Format(entry))
+	if strings.Contains(got, "caller_") || strings.Contains(got, "trace_id") || strings.Contains(got, `"gid"`) {
+		t.Errorf("disabled caller and trace should be omitted, got %s", got)
+	}
+}
+
+func TestJSONFormatter_MatchesMarshalJSON(t *testing.T) {
+	entry := &Entry{
+		Level:      InfoLevel,
+		Message:    "line \"one\"\n<two>",
+		Pid:        12,
+		Gid:        34,
+		TraceId:    "t",
+		File:       "f.go",
+		CallerLine: 5,
+		CallerFunc: "fn",
+		CallerName: "pkg.fn",
+		TimeStr:    "now",
+		TimeStrSet: true,
+		Fields:     []KV{{Key: "n", Value: 1.25}, {Key: "s", Value: "v"}, {Key: "m", Value: map[string]int{"k": 1}}},
+	}
+
+	var got, want map[string]interface{}
+	if err := json.Unmarshal((&JSONFormatter{}).Format(entry), &got); err != nil {
+		t.Fatal(err)
+	}
+	legacy, err := entry.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(legacy, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	gotJSON, _ := json.Marshal(got)
+	wantJSON, _ := json.Marshal(want)
+	if !bytes.Equal(gotJSON, wantJSON) {
+		t.Errorf("Format decodes to\n%s\nwant\n%s", gotJSON, wantJSON)
+	}
+}
+
+func TestJSONFormatter_EncodedFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	child := logger.With("service", "api", "port", 8080)
+	if len(child.config().fieldsEncoded) == 0 {
+		t.Fatal("bound fields should be pre-encoded by the JSON formatter")
+	}
+
+	child.Infow("request", "status", 200)
+	child.Info("bare")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected 2 lines, got %q", buf.String())
+	}
+	if !strings.Contains(lines[0], `"fields":{"service":"api","port":8080,"status":200}`) {
+		t.Errorf("unexpected fields in %s", lines[0])
+	}
+	if !strings.Contains(lines[1], `"fields":{"service":"api","port":8080}`) {
+		t.Errorf("unexpected fields in %s", lines[1])
+	}
+
+	if (&JSONFormatter{}).EncodeFields([]KV{{Key: "ch", Value: make(chan int)}}) != nil {
+		t.Error("EncodeFields should give up on values it can't encode")
+	}
+}
+
+func TestJSONFormatter_PrettyPrint(t *testing.T) {
+	entry := &Entry{Level: InfoLevel, Message: "pretty", Fields: []KV{{Key: "k", Value: "v"}}}
+	got := string((&JSONFormatter{EnablePrettyPrint: true}).Format(entry))
+	want := "{\n  \"level\": \"info\",\n  \"message\": \"pretty\",\n  \"pid\": 0,\n  \"fields\": {\n    \"k\": \"v\"\n  }\n}\n"
+	if got != want {
+		t.Errorf("pretty output =\n%s\nwant\n%s", got, want)
+	}
+}
+
+// benchJSONEntry is a typical entry for the JSON benchmarks
+func benchJSONEntry() *Entry {
+	return &Entry{
+		Level:      InfoLevel,
+		Message:    "request handled",
+		Pid:        12345,
+		Gid:        67,
+		TraceId:    "4bf92f3577b34da6a3ce929d0e0e4736",
+		File:       "/src/app/server/handler.go",
+		CallerLine: 120,
+		CallerFunc: "(*Server).handle",
+		CallerDir:  "app/server",
+		TimeStr:    "2024-01-02T03:04:05.123456789Z",
+		TimeStrSet: true,
+		Fields: []KV{
+			{Key: "method", Value: "GET"},
+			{Key: "path", Value: "/api/v1/users"},
+			{Key: "status", Value: 200},
+			{Key: "latency_ms", Value: 1.25},
+			{Key: "cached", Value: true},
+		},
+	}
+}
+
+func BenchmarkJSONEncoder_Format(b *testing.B) {
+	f := &JSONFormatter{}
+	entry := benchJSONEntry()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = f.Format(entry)
+	}
+}
+
+// BenchmarkJSONEncoder_MarshalJSON is the map and reflection based
+// encoding JSONFormatter used before
+func BenchmarkJSONEncoder_MarshalJSON(b *testing.B) {
+	entry := benchJSONEntry()
+
+	b.ReportAllocs()
+	for b.Loop() {
+		serializeEntry := *entry
+		data, _ := json.Marshal(&serializeEntry)
+		_ = append(data, '\n')
+	}
+}
+
+func BenchmarkJSONEncoder_EscapeString(b *testing.B) {
+	s := "user \"admin\" logged in from 10.0.0.1\n\tagent: 中文"
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = appendJSONString(make([]byte, 0, 64), s)
+	}
+}
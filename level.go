@@ -15,3 +15,9 @@
 	DebugLevel = constant.DebugLevel
 	TraceLevel = constant.TraceLevel
 )
+
+// ParseLevel converts a level name, alias (warning, err, crit) or number to Level.
+// See constant.ParseLevel.
+func ParseLevel(text string) (Level, error) {
+	return constant.ParseLevel(text)
+}
@@ -0,0 +1,229 @@
+package log
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// AtomicLevel is a log level that can be changed at runtime and shared by
+// several loggers. Every Logger holds one; bind the same handle to several
+// loggers with Logger.SetAtomicLevel so a single change applies to all of them.
+//
+// AtomicLevel implements http.Handler (see ServeHTTP) so the level of a running
+// service can be inspected and changed over HTTP:
+//
+//	level := log.NewAtomicLevel(log.InfoLevel)
+//	log.SetAtomicLevel(level)
+//	http.Handle("/log/level", level)
+//
+// Create it with NewAtomicLevel; the zero value is at PanicLevel.
+type AtomicLevel struct {
+	level atomic.Uint32
+
+	// mu guards the pending revert
+	mu       sync.Mutex
+	revert   *time.Timer
+	revertTo Level
+}
+
+// NewAtomicLevel creates an AtomicLevel set to level
+func NewAtomicLevel(level Level) *AtomicLevel {
+	a := &AtomicLevel{}
+	a.level.Store(uint32(level))
+	return a
+}
+
+// Level returns the current level
+//
+//go:inline
+func (a *AtomicLevel) Level() Level {
+	return Level(a.level.Load())
+}
+
+// Enabled reports whether entries at level should be logged
+//
+//go:inline
+func (a *AtomicLevel) Enabled(level Level) bool {
+	return a.Level() >= level
+}
+
+// SetLevel changes the level and cancels any pending revert
+func (a *AtomicLevel) SetLevel(level Level) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.stopRevert()
+	a.level.Store(uint32(level))
+}
+
+// SetLevelFor changes the level for d, then restores the level in effect
+// before the first of consecutive timed changes. A d <= 0 behaves like SetLevel.
+//
+//	level.SetLevelFor(log.DebugLevel, 10*time.Minute)
+func (a *AtomicLevel) SetLevelFor(level Level, d time.Duration) {
+	if d <= 0 {
+		a.SetLevel(level)
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.revert == nil {
+		a.revertTo = a.Level()
+	} else {
+		a.revert.Stop()
+	}
+	a.level.Store(uint32(level))
+
+	var t *time.Timer
+	t = time.AfterFunc(d, func() {
+		a.mu.Lock()
+		defer a.mu.Unlock()
+
+		// A later change has replaced this revert
+		if a.revert != t {
+			return
+		}
+		a.revert = nil
+		a.level.Store(uint32(a.revertTo))
+	})
+	a.revert = t
+}
+
+// stopRevert cancels the pending revert, a.mu must be held
+func (a *AtomicLevel) stopRevert() {
+	if a.revert != nil {
+		a.revert.Stop()
+		a.revert = nil
+	}
+}
+
+// String implements fmt.Stringer
+func (a *AtomicLevel) String() string {
+	return a.Level().String()
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (a *AtomicLevel) MarshalText() ([]byte, error) {
+	return a.Level().MarshalText()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, so an AtomicLevel can be
+// read directly from configuration files
+func (a *AtomicLevel) UnmarshalText(text []byte) error {
+	level, err := ParseLevel(string(text))
+	if err != nil {
+		return err
+	}
+	a.SetLevel(level)
+	return nil
+}
+
+// levelPayload is the JSON body exchanged by ServeHTTP
+type levelPayload struct {
+	Level    string `json:"level"`
+	Duration string `json:"duration,omitempty"`
+}
+
+// ServeHTTP implements http.Handler
+//
+// GET returns the current level. PUT changes it; the new level is read from
+// a JSON body ({"level":"debug"}), a text/plain body (debug) or a form field
+// (level=debug). An optional duration ({"duration":"5m"}, ?duration=5m or a
+// duration form field) reverts the change once it elapses.
+//
+// Responses are JSON unless the request only accepts text/plain.
+func (a *AtomicLevel) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		req, err := decodeLevelRequest(r)
+		if err != nil {
+			writeLevelError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		level, err := ParseLevel(req.Level)
+		if err != nil {
+			writeLevelError(w, r, err, http.StatusBadRequest)
+			return
+		}
+
+		var d time.Duration
+		if req.Duration != "" {
+			d, err = time.ParseDuration(req.Duration)
+			if err != nil || d < 0 {
+				writeLevelError(w, r, fmt.Errorf("log: invalid duration %q", req.Duration), http.StatusBadRequest)
+				return
+			}
+		}
+		a.SetLevelFor(level, d)
+	default:
+		w.Header().Set("Allow", "GET, PUT")
+		writeLevelError(w, r, fmt.Errorf("log: method %s not allowed", r.Method), http.StatusMethodNotAllowed)
+		return
+	}
+
+	if acceptsText(r) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, a.String()+"\n")
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(levelPayload{Level: a.String()})
+}
+
+// decodeLevelRequest reads the level and optional duration of a PUT request
+func decodeLevelRequest(r *http.Request) (levelPayload, error) {
+	var req levelPayload
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	switch mediaType {
+	case "application/x-www-form-urlencoded":
+		if err := r.ParseForm(); err != nil {
+			return req, err
+		}
+		req.Level = r.Form.Get("level")
+		req.Duration = r.Form.Get("duration")
+	case "text/plain":
+		body, err := io.ReadAll(io.LimitReader(r.Body, 1024))
+		if err != nil {
+			return req, err
+		}
+		req.Level = string(body)
+	default:
+		if err := json.NewDecoder(io.LimitReader(r.Body, 1024)).Decode(&req); err != nil {
+			return req, fmt.Errorf("log: invalid request body: %w", err)
+		}
+	}
+
+	if req.Duration == "" {
+		req.Duration = r.URL.Query().Get("duration")
+	}
+	return req, nil
+}
+
+// acceptsText reports whether the client asked for a text/plain response only
+func acceptsText(r *http.Request) bool {
+	accept := r.Header.Get("Accept")
+	return strings.Contains(accept, "text/plain") && !strings.Contains(accept, "json")
+}
+
+// writeLevelError reports err to the client with status
+func writeLevelError(w http.ResponseWriter, r *http.Request, err error, status int) {
+	if acceptsText(r) {
+		http.Error(w, err.Error(), status)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
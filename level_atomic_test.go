@@ -0,0 +1,240 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAtomicLevel_Shared(t *testing.T) {
+	level := NewAtomicLevel(InfoLevel)
+
+	var buf bytes.Buffer
+	a := New().SetOutput(&buf).SetAtomicLevel(level)
+	b := New().SetOutput(&buf).SetAtomicLevel(level)
+
+	a.Debug("hidden")
+	if buf.Len() != 0 {
+		t.Fatalf("debug should be disabled, got %q", buf.String())
+	}
+
+	// Changing the level through one logger affects every logger sharing it
+	a.SetLevel(DebugLevel)
+	if b.Level() != DebugLevel {
+		t.Errorf("shared level should be DebugLevel, got %v", b.Level())
+	}
+	b.Debug("visible")
+	if !strings.Contains(buf.String(), "visible") {
+		t.Errorf("debug should be enabled, got %q", buf.String())
+	}
+
+	if a.AtomicLevel() != level {
+		t.Error("AtomicLevel should return the bound handle")
+	}
+	if a.SetAtomicLevel(nil).AtomicLevel() != level {
+		t.Error("SetAtomicLevel(nil) should keep the current handle")
+	}
+}
+
+func TestAtomicLevel_CloneIsIndependent(t *testing.T) {
+	logger := New().SetLevel(WarnLevel)
+	cloned := logger.Clone()
+
+	logger.SetLevel(TraceLevel)
+	if cloned.Level() != WarnLevel {
+		t.Errorf("clones should keep their own level, got %v", cloned.Level())
+	}
+}
+
+func TestAtomicLevel_ChildrenShareLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).SetLevel(InfoLevel)
+	children := []*Logger{
+		logger.With("k", "v"),
+		logger.With(),
+		logger.WithFields(map[string]interface{}{"k": "v"}),
+		logger.WithFields(nil),
+	}
+	slogChild := NewSlogLogger(logger).With("k", "v")
+
+	logger.SetLevel(DebugLevel)
+	for i, child := range children {
+		buf.Reset()
+		child.Debug("visible")
+		if !strings.Contains(buf.String(), "visible") {
+			t.Errorf("child %d should follow SetLevel on its parent, got %q", i, buf.String())
+		}
+	}
+	if !slogChild.Enabled(context.Background(), slog.LevelDebug) {
+		t.Error("slog handlers from WithAttrs should follow SetLevel on the logger")
+	}
+
+	// Changes through the shared handle, as the HTTP handler makes, reach them too
+	logger.AtomicLevel().SetLevel(ErrorLevel)
+	for i, child := range children {
+		if child.Level() != ErrorLevel {
+			t.Errorf("child %d should be at ErrorLevel, got %v", i, child.Level())
+		}
+	}
+}
+
+func TestAtomicLevel_Global(t *testing.T) {
+	original := GetAtomicLevel()
+	defer SetAtomicLevel(original)
+
+	level := NewAtomicLevel(ErrorLevel)
+	SetAtomicLevel(level)
+
+	SetLevel(InfoLevel)
+	if level.Level() != InfoLevel {
+		t.Errorf("SetLevel should update the bound AtomicLevel, got %v", level.Level())
+	}
+	if GetAtomicLevel() != level {
+		t.Error("GetAtomicLevel should return the bound handle")
+	}
+}
+
+func TestAtomicLevel_Text(t *testing.T) {
+	level := NewAtomicLevel(InfoLevel)
+
+	if err := level.UnmarshalText([]byte(" WARN ")); err != nil {
+		t.Fatalf("UnmarshalText failed: %v", err)
+	}
+	if level.Level() != WarnLevel {
+		t.Errorf("expected WarnLevel, got %v", level.Level())
+	}
+	if err := level.UnmarshalText([]byte("verbose")); err == nil {
+		t.Error("expected an error for an unknown level")
+	}
+
+	text, err := level.MarshalText()
+	if err != nil || string(text) != "warn" {
+		t.Errorf("MarshalText = %q, %v", text, err)
+	}
+	if level.String() != "warn" {
+		t.Errorf("String = %q", level.String())
+	}
+}
+
+func TestAtomicLevel_SetLevelFor(t *testing.T) {
+	level := NewAtomicLevel(InfoLevel)
+
+	level.SetLevelFor(DebugLevel, 20*time.Millisecond)
+	level.SetLevelFor(TraceLevel, 20*time.Millisecond)
+	if level.Level() != TraceLevel {
+		t.Fatalf("expected TraceLevel, got %v", level.Level())
+	}
+
+	waitForLevel(t, level, InfoLevel)
+
+	// A plain SetLevel cancels the pending revert
+	level.SetLevelFor(DebugLevel, 20*time.Millisecond)
+	level.SetLevel(ErrorLevel)
+	time.Sleep(50 * time.Millisecond)
+	if level.Level() != ErrorLevel {
+		t.Errorf("SetLevel should cancel the revert, got %v", level.Level())
+	}
+}
+
+func waitForLevel(t *testing.T, level *AtomicLevel, want Level) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for level.Level() != want {
+		if time.Now().After(deadline) {
+			t.Fatalf("level did not revert to %v, got %v", want, level.Level())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestAtomicLevel_ServeHTTP(t *testing.T) {
+	level := NewAtomicLevel(InfoLevel)
+
+	serve := func(method, target, contentType, accept, body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if contentType != "" {
+			req.Header.Set("Content-Type", contentType)
+		}
+		if accept != "" {
+			req.Header.Set("Accept", accept)
+		}
+		rec := httptest.NewRecorder()
+		level.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("get_json", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/", "", "", "")
+		var resp levelPayload
+		if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Level != "info" {
+			t.Errorf("unexpected response %d %q", rec.Code, rec.Body.String())
+		}
+	})
+
+	t.Run("get_text", func(t *testing.T) {
+		rec := serve(http.MethodGet, "/", "", "text/plain", "")
+		if rec.Body.String() != "info\n" {
+			t.Errorf("unexpected response %q", rec.Body.String())
+		}
+	})
+
+	t.Run("put_json", func(t *testing.T) {
+		rec := serve(http.MethodPut, "/", "application/json", "", `{"level":"debug"}`)
+		if rec.Code != http.StatusOK || level.Level() != DebugLevel {
+			t.Errorf("unexpected response %d %q, level %v", rec.Code, rec.Body.String(), level.Level())
+		}
+	})
+
+	t.Run("put_text", func(t *testing.T) {
+		rec := serve(http.MethodPut, "/", "text/plain", "text/plain", "warn")
+		if rec.Body.String() != "warn\n" || level.Level() != WarnLevel {
+			t.Errorf("unexpected response %q, level %v", rec.Body.String(), level.Level())
+		}
+	})
+
+	t.Run("put_form_with_duration", func(t *testing.T) {
+		rec := serve(http.MethodPut, "/", "application/x-www-form-urlencoded", "", "level=trace&duration=20ms")
+		if rec.Code != http.StatusOK || level.Level() != TraceLevel {
+			t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+		}
+		waitForLevel(t, level, WarnLevel)
+	})
+
+	t.Run("put_json_duration_query", func(t *testing.T) {
+		rec := serve(http.MethodPut, "/?duration=20ms", "application/json", "", `{"level":"error"}`)
+		if rec.Code != http.StatusOK || level.Level() != ErrorLevel {
+			t.Fatalf("unexpected response %d %q", rec.Code, rec.Body.String())
+		}
+		waitForLevel(t, level, WarnLevel)
+	})
+
+	t.Run("bad_requests", func(t *testing.T) {
+		for _, tc := range []struct{ contentType, body string }{
+			{"application/json", `{"level":"loud"}`},
+			{"application/json", `not json`},
+			{"application/json", `{"level":"info","duration":"soon"}`},
+			{"text/plain", ""},
+		} {
+			rec := serve(http.MethodPut, "/", tc.contentType, "", tc.body)
+			if rec.Code != http.StatusBadRequest || !strings.Contains(rec.Body.String(), "error") {
+				t.Errorf("%q: expected 400 with an error, got %d %q", tc.body, rec.Code, rec.Body.String())
+			}
+		}
+		if level.Level() != WarnLevel {
+			t.Errorf("bad requests must not change the level, got %v", level.Level())
+		}
+	})
+
+	t.Run("method_not_allowed", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/", "", "", "")
+		if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PUT" {
+			t.Errorf("unexpected response %d %v", rec.Code, rec.Header())
+		}
+	})
+}
@@ -0,0 +1,199 @@
+package log
+
+import (
+	"fmt"
+	"path"
+	"runtime"
+	"strings"
+	"sync"
+)
+
+// ModuleLevels overrides the logger level for selected packages.
+//
+// It is built from a comma-separated list of pattern=level rules, in the style
+// of GODEBUG:
+//
+//	ourapp/storage/*=trace,ourapp/http=warn,*=info
+//
+// Patterns are matched against the caller's package as reported in
+// Entry.CallerDir (the import path without "github.com/"), using path.Match
+// syntax. A pattern also matches every sub-package of a matching path, so
+// "ourapp/storage" covers "ourapp/storage/sql" too, and "*" matches everything.
+// A pattern ending in "/*" matches the package it names as well:
+// "ourapp/storage/*" covers "ourapp/storage" and all its sub-packages.
+// Rules are tried in order and the first match wins; packages matching no rule
+// use the logger level. A rule without a pattern ("debug") is the same as "*=debug".
+//
+// The decision is cached per call site, so disabled packages skip message
+// formatting and only pay for locating the caller.
+type ModuleLevels struct {
+	rules []moduleRule
+
+	// maxLevel is the most verbose level enabled by any rule
+	maxLevel Level
+
+	// cache maps a caller PC to the index of its rule, -1 when none matches
+	cache sync.Map
+}
+
+type moduleRule struct {
+	pattern string
+	level   Level
+}
+
+// ParseModuleLevels parses a pattern=level list such as "ourapp/storage/*=trace,*=info".
+// Levels accept everything ParseLevel does.
+func ParseModuleLevels(spec string) (*ModuleLevels, error) {
+	m := &ModuleLevels{}
+
+	for _, item := range strings.Split(spec, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		pattern, levelText := "*", item
+		if i := strings.LastIndexByte(item, '='); i >= 0 {
+			pattern, levelText = strings.TrimSpace(item[:i]), item[i+1:]
+		}
+		pattern = strings.Trim(pattern, "/")
+		if pattern == "" {
+			return nil, fmt.Errorf("log: empty module pattern in %q", item)
+		}
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("log: invalid module pattern %q: %w", pattern, err)
+		}
+
+		level, err := ParseLevel(levelText)
+		if err != nil {
+			return nil, err
+		}
+
+		m.rules = append(m.rules, moduleRule{pattern: pattern, level: level})
+		if level > m.maxLevel {
+			m.maxLevel = level
+		}
+	}
+
+	return m, nil
+}
+
+// String returns the rules in the form accepted by ParseModuleLevels
+func (m *ModuleLevels) String() string {
+	if m == nil {
+		return ""
+	}
+
+	var b strings.Builder
+	for i, rule := range m.rules {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(rule.pattern)
+		b.WriteByte('=')
+		b.WriteString(rule.level.String())
+	}
+	return b.String()
+}
+
+// Level returns the level for the package dir (as in Entry.CallerDir),
+// or fallback when no rule matches
+func (m *ModuleLevels) Level(dir string, fallback Level) Level {
+	if i := m.match(dir); i >= 0 {
+		return m.rules[i].level
+	}
+	return fallback
+}
+
+// match returns the index of the first rule matching dir, or -1
+func (m *ModuleLevels) match(dir string) int {
+	for i, rule := range m.rules {
+		if matchModule(rule.pattern, dir) {
+			return i
+		}
+	}
+	return -1
+}
+
+// matchModule reports whether pattern matches dir or one of its parent paths.
+// "pkg/*" also matches "pkg" itself.
+func matchModule(pattern, dir string) bool {
+	parent, tree := strings.CutSuffix(pattern, "/*")
+	for {
+		if ok, _ := path.Match(pattern, dir); ok {
+			return true
+		}
+		if tree {
+			if ok, _ := path.Match(parent, dir); ok {
+				return true
+			}
+		}
+		i := strings.LastIndexByte(dir, '/')
+		if i < 0 {
+			return false
+		}
+		dir = dir[:i]
+	}
+}
+
+// mayEnable reports whether level can be enabled for some package,
+// letting most disabled calls return before the caller is looked up
+//
+//go:inline
+func (m *ModuleLevels) mayEnable(level Level, fallback *AtomicLevel) bool {
+	return m.maxLevel >= level || fallback.Enabled(level)
+}
+
+// enabledAt reports whether level is enabled for the function containing pc
+func (m *ModuleLevels) enabledAt(pc uintptr, level Level, fallback *AtomicLevel) bool {
+	i, ok := m.cache.Load(pc)
+	if !ok {
+		dir := ""
+		if fn := runtime.FuncForPC(pc); fn != nil {
+			dir, _ = SplitPackageName(fn.Name())
+		}
+		i, _ = m.cache.LoadOrStore(pc, m.match(dir))
+	}
+
+	if idx := i.(int); idx >= 0 {
+		return m.rules[idx].level >= level
+	}
+	return fallback.Enabled(level)
+}
+
+// moduleEnabled checks level against the module rules for the caller.
+// It must stay at the same stack depth as fillCallerInfo, see levelEnabled.
+func (c *loggerConfig) moduleEnabled(level Level) bool {
+	if !c.modules.mayEnable(level, c.level) {
+		return false
+	}
+
+	pc, _, _, ok := runtime.Caller(c.callerDepth)
+	if !ok {
+		return c.level.Enabled(level)
+	}
+	return c.modules.enabledAt(pc, level, c.level)
+}
+
+// SetModuleLevels sets per-package level overrides from a spec such as
+// "ourapp/storage/*=trace,*=info" (see ModuleLevels). An empty spec removes them.
+func (p *Logger) SetModuleLevels(spec string) error {
+	var modules *ModuleLevels
+	if strings.TrimSpace(spec) != "" {
+		var err error
+		modules, err = ParseModuleLevels(spec)
+		if err != nil {
+			return err
+		}
+	}
+
+	p.update(func(c *loggerConfig) {
+		c.modules = modules
+	})
+	return nil
+}
+
+// ModuleLevels returns the per-package level overrides, nil if there are none
+func (p *Logger) ModuleLevels() *ModuleLevels {
+	return p.config().modules
+}
@@ -0,0 +1,176 @@
+package log
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestParseModuleLevels(t *testing.T) {
+	m, err := ParseModuleLevels(" ourapp/storage/*=TRACE, ourapp/http = warning ,debug,")
+	if err != nil {
+		t.Fatalf("ParseModuleLevels failed: %v", err)
+	}
+	if got := m.String(); got != "ourapp/storage/*=trace,ourapp/http=warn,*=debug" {
+		t.Errorf("String = %q", got)
+	}
+	if m.maxLevel != TraceLevel {
+		t.Errorf("maxLevel = %v", m.maxLevel)
+	}
+
+	for _, spec := range []string{"=info", "ourapp=loud", "[=info"} {
+		if _, err := ParseModuleLevels(spec); err == nil {
+			t.Errorf("ParseModuleLevels(%q) should fail", spec)
+		}
+	}
+}
+
+func TestModuleLevels_Level(t *testing.T) {
+	m, err := ParseModuleLevels("ourapp/storage/*=trace,ourapp/http=warn,other/*/internal=error")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		dir  string
+		want Level
+	}{
+		{"ourapp/storage/sql", TraceLevel},
+		{"ourapp/storage/sql/driver", TraceLevel},
+		{"ourapp/storage", TraceLevel},
+		{"ourapp/storagex", InfoLevel},
+		{"ourapp", InfoLevel},
+		{"ourapp/http", WarnLevel},
+		{"ourapp/http/middleware", WarnLevel},
+		{"ourapp/httpx", InfoLevel},
+		{"other/pkg/internal", ErrorLevel},
+		{"other/pkg/internal/x", ErrorLevel},
+		{"other/pkg", InfoLevel},
+		{"", InfoLevel},
+	}
+	for _, tt := range tests {
+		if got := m.Level(tt.dir, InfoLevel); got != tt.want {
+			t.Errorf("Level(%q) = %v, want %v", tt.dir, got, tt.want)
+		}
+	}
+
+	first, _ := ParseModuleLevels("ourapp/storage/*=trace,*=info")
+	if got := first.Level("ourapp/storage/sql", ErrorLevel); got != TraceLevel {
+		t.Errorf("first matching rule should win, got %v", got)
+	}
+	if got := first.Level("ourapp/api", ErrorLevel); got != InfoLevel {
+		t.Errorf("* should match every package, got %v", got)
+	}
+}
+
+func TestLogger_SetModuleLevels(t *testing.T) {
+	var buf bytes.Buffer
+	// Depth 3 points at the test function when logger methods are called directly
+	logger := newTestLogger(&buf).EnableCaller(true).SetCallerDepth(3).SetLevel(ErrorLevel)
+
+	// Test functions live in package "log"
+	if err := logger.SetModuleLevels("other/*=trace,log=debug"); err != nil {
+		t.Fatal(err)
+	}
+
+	logger.Debugw("enabled for this package", "k", "v")
+	logger.Trace("still above the package level")
+	if out := buf.String(); !strings.Contains(out, "enabled for this package") || strings.Contains(out, "still above") {
+		t.Errorf("unexpected output %q", out)
+	}
+
+	buf.Reset()
+	if err := logger.SetModuleLevels("other/*=trace"); err != nil {
+		t.Fatal(err)
+	}
+	logger.Debug("falls back to the logger level")
+	logger.Error("error passes")
+	if out := buf.String(); strings.Contains(out, "falls back") || !strings.Contains(out, "error passes") {
+		t.Errorf("unmatched packages should use the logger level, got %q", out)
+	}
+
+	if logger.ModuleLevels().String() != "other/*=trace" {
+		t.Errorf("ModuleLevels = %q", logger.ModuleLevels())
+	}
+
+	if err := logger.SetModuleLevels("log=loud"); err == nil {
+		t.Error("expected an error for an invalid spec")
+	}
+	if logger.ModuleLevels().String() != "other/*=trace" {
+		t.Error("an invalid spec must keep the previous rules")
+	}
+
+	if err := logger.SetModuleLevels(""); err != nil || logger.ModuleLevels() != nil {
+		t.Errorf("an empty spec should remove the rules, got %v, %v", logger.ModuleLevels(), err)
+	}
+}
+
+func TestLogger_ModuleLevels_SkipsFormatting(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).EnableCaller(true).SetCallerDepth(3).SetLevel(TraceLevel)
+	if err := logger.SetModuleLevels("log=error"); err != nil {
+		t.Fatal(err)
+	}
+
+	formatted := false
+	logger.Debugf("%v", stringerFunc(func() string {
+		formatted = true
+		return "x"
+	}))
+	if formatted || buf.Len() != 0 {
+		t.Errorf("disabled packages should not format the message, output %q", buf.String())
+	}
+}
+
+type stringerFunc func() string
+
+func (f stringerFunc) String() string { return f() }
+
+func TestLogger_ModuleLevels_Context(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).EnableCaller(true).SetCallerDepth(3).SetLevel(ErrorLevel)
+	if err := logger.SetModuleLevels("log=info"); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := ContextWithFields(t.Context(), "req", 1)
+	logger.InfoContext(ctx, "context info")
+	logger.DebugContext(ctx, "context debug")
+	if out := buf.String(); !strings.Contains(out, "context info") || strings.Contains(out, "context debug") {
+		t.Errorf("unexpected output %q", out)
+	}
+}
+
+func TestLogger_ModuleLevels_Slog(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).EnableCaller(true).SetCallerDepth(3).SetLevel(ErrorLevel)
+	if err := logger.SetModuleLevels("log=debug"); err != nil {
+		t.Fatal(err)
+	}
+
+	sl := NewSlogLogger(logger)
+	sl.Debug("slog debug")
+	if !strings.Contains(buf.String(), "slog debug") {
+		t.Errorf("slog records should use the module level, got %q", buf.String())
+	}
+
+	buf.Reset()
+	if err := logger.SetModuleLevels("other=trace,log=error"); err != nil {
+		t.Fatal(err)
+	}
+	sl.Warn("slog warn")
+	if buf.Len() != 0 {
+		t.Errorf("slog record should be dropped, got %q", buf.String())
+	}
+}
+
+func TestSetModuleLevels_Global(t *testing.T) {
+	defer SetModuleLevels("")
+
+	if err := SetModuleLevels("log=trace"); err != nil {
+		t.Fatal(err)
+	}
+	if std.ModuleLevels().String() != "log=trace" {
+		t.Errorf("standard logger rules = %q", std.ModuleLevels())
+	}
+}
@@ -1,3 +1,15 @@
 module github.com/lazygophers/log/logctx
 
-go 1.19
+go 1.26.2
+
+require github.com/lazygophers/log v0.0.0-00010101000000-000000000000
+
+require (
+	github.com/lazygophers/log/constant v0.0.0-20260505024342-2c291363de69 // indirect
+	github.com/petermattis/goid v0.0.0-20260113132338-7c7de50cc741 // indirect
+)
+
+replace (
+	github.com/lazygophers/log => ../
+	github.com/lazygophers/log/constant => ../constant
+)
@@ -4,6 +4,8 @@
 	"bytes"
 	"context"
 	"testing"
+
+	"github.com/lazygophers/log"
 )
 
 func TestNewLogger(t *testing.T) {
@@ -21,8 +23,8 @@ func TestLogger_SetLevel(t *testing.T) {
 	logger.Info(context.Background(), "test") // should not output
 	
 	// Verify level was set
-	if logger.level != ErrorLevel {
-		t.Errorf("Expected level ErrorLevel, got %v", logger.level)
+	if logger.Level() != ErrorLevel {
+		t.Errorf("Expected level ErrorLevel, got %v", logger.Level())
 	}
 }
 
@@ -43,8 +45,8 @@ func TestLogger_SetPrefixMsg(t *testing.T) {
 	prefix := "TEST: "
 	logger.SetPrefixMsg(prefix)
 	
-	if logger.prefixMsg != prefix {
-		t.Errorf("Expected prefix %q, got %q", prefix, logger.prefixMsg)
+	if string(logger.Logger().Prefix()) != prefix {
+		t.Errorf("Expected prefix %q, got %q", prefix, logger.Logger().Prefix())
 	}
 }
 
@@ -53,8 +55,8 @@ func TestLogger_SetSuffixMsg(t *testing.T) {
 	suffix := "[END]"
 	logger.SetSuffixMsg(suffix)
 	
-	if logger.suffixMsg != suffix {
-		t.Errorf("Expected suffix %q, got %q", suffix, logger.suffixMsg)
+	if string(logger.Logger().Suffix()) != suffix {
+		t.Errorf("Expected suffix %q, got %q", suffix, logger.Logger().Suffix())
 	}
 }
 
@@ -69,15 +71,27 @@ func TestLogger_Clone(t *testing.T) {
 		t.Error("Clone should return a different instance")
 	}
 	
-	if clone.level != ErrorLevel {
+	if clone.Level() != ErrorLevel {
 		t.Errorf("Cloned logger should have same level")
 	}
 	
-	if clone.prefixMsg != "ORIGINAL: " {
+	if string(clone.Logger().Prefix()) != "ORIGINAL: " {
 		t.Errorf("Cloned logger should have same prefix")
 	}
 }
 
+func TestLogger_WithSharesLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf)
+	child := logger.With("k", "v")
+
+	logger.SetLevel(DebugLevel)
+	child.Debug(context.Background(), "visible")
+	if buf.Len() == 0 {
+		t.Error("child from With should follow SetLevel on its parent")
+	}
+}
+
 func TestLogger_LogWithContext(t *testing.T) {
 	var buf bytes.Buffer
 	logger := New()
@@ -134,8 +148,8 @@ func TestLogger_Levels(t *testing.T) {
 	logger.Error(ctx, "error")
 	
 	output := buf.String()
-	if !contains(output, "TRACE") || !contains(output, "DEBUG") || 
-	   !contains(output, "INFO") || !contains(output, "WARN") || !contains(output, "ERROR") {
+	if !contains(output, "[trace]") || !contains(output, "[debug]") ||
+	   !contains(output, "[info]") || !contains(output, "[warn]") || !contains(output, "[error]") {
 		t.Error("Expected all log levels in output")
 	}
 }
@@ -155,3 +169,34 @@ func indexOf(s, substr string) int {
 	}
 	return -1
 }
+
+func TestLogger_SharesCoreFeatures(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.EnableCaller(false)
+
+	ctx := log.ContextWithTrace(context.Background(), "ctx-trace-id")
+	ctx = log.ContextWithFields(ctx, "tenant", "acme")
+	logger.With("svc", "api").Info(ctx, "shared")
+
+	output := buf.String()
+	if !contains(output, "svc=api tenant=acme") {
+		t.Errorf("Expected bound and context fields, got %q", output)
+	}
+	if !contains(output, "ctx-trace-id") {
+		t.Errorf("Expected trace id from context, got %q", output)
+	}
+}
+
+func TestWrapAndFromContext(t *testing.T) {
+	core := log.New()
+	if Wrap(core).Logger() != core {
+		t.Error("Wrap should forward to the given core logger")
+	}
+
+	ctx := log.NewContext(context.Background(), core)
+	if FromContext(ctx).Logger() != core {
+		t.Error("FromContext should wrap the logger stored in the context")
+	}
+}
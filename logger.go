@@ -1,10 +1,12 @@
 package log
 
 import (
-	"fmt"
 	"io"
 	"os"
+	"reflect"
 	"runtime"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lazygophers/log/constant"
@@ -12,13 +14,61 @@
 )
 
 // Logger is the core logging structure
+//
+// The configuration is kept in an immutable loggerConfig snapshot swapped
+// atomically by the setters, so a Logger can be reconfigured from any goroutine
+// while others are logging. The logging path only loads the current snapshot
+// and never takes a lock.
 type Logger struct {
-	level       Level
+	// Format is the formatter the logger used to expose.
+	//
+	// Deprecated: Use SetFormatter and Formatter. A formatter assigned here
+	// still takes precedence until it is reset to nil, but it isn't safe to
+	// assign while the logger is in use.
+	Format constant.Format
+
+	// PrefixMsg is the message prefix the logger used to expose.
+	//
+	// Deprecated: Use SetPrefixMsg and Prefix. A prefix assigned here still
+	// takes precedence until it is reset to nil, with the same caveats as Format.
+	PrefixMsg []byte
+
+	// SuffixMsg is the message suffix the logger used to expose.
+	//
+	// Deprecated: Use SetSuffixMsg and Suffix, see PrefixMsg.
+	SuffixMsg []byte
+
+	// mu serializes configuration updates
+	mu sync.Mutex
+
+	cfg atomic.Pointer[loggerConfig]
+
+	// legacy caches cfg with the deprecated fields applied
+	legacy atomic.Pointer[legacyConfig]
+}
+
+// legacyConfig is a snapshot with the deprecated exported fields applied,
+// valid while the base snapshot and the fields are unchanged
+type legacyConfig struct {
+	base   *loggerConfig
+	format constant.Format
+	prefix []byte
+	suffix []byte
+
+	cfg *loggerConfig
+}
+
+// loggerConfig is a configuration snapshot of a Logger.
+// A published snapshot is never modified; updates copy it first.
+type loggerConfig struct {
+	// level is shared by reference, changing it does not publish a new snapshot
+	level       *AtomicLevel
 	out         constant.WriteSyncer
-	Format      constant.Format
+	levelOut    LevelWriter // out when it takes entry levels, nil otherwise
+	format      constant.Format
 	callerDepth int
-	PrefixMsg   []byte
-	SuffixMsg   []byte
+	prefixMsg   []byte
+	suffixMsg   []byte
 
 	// Performance optimization fields
 	enableCaller bool
@@ -26,105 +76,246 @@ type Logger struct {
 
 	// Hooks for log processing
 	hooks []constant.Hook
+
+	// Fields bound by With, merged into every entry. Their keys are unique
+	// within each object, fieldKeys holds those of the last one.
+	fields    []KV
+	fieldKeys map[string]struct{}
+
+	// fieldsEncoded caches fields pre-encoded by format
+	fieldsEncoded []byte
+
+	// Extractors pulling values out of the context for *Context methods
+	ctxExtractors []ContextExtractor
+
+	// Per-package level overrides, nil when not configured
+	modules *ModuleLevels
+
+	// Sampler dropping repeated entries, nil when not configured
+	sampler *Sampler
+
+	// Deduper collapsing repeated entries, nil when not configured
+	dedup *Deduper
+
+	// Clock timestamping entries, nil reads the wall clock
+	clock Clock
+
+	// Worker formatting and writing entries, nil when logging synchronously
+	async *asyncEmitter
 }
 
 // newLogger creates a new Logger instance with default values
 func newLogger() *Logger {
 	var out io.Writer = os.Stdout
 
-	logger := &Logger{
-		level: DebugLevel,
+	logger := &Logger{}
+	logger.cfg.Store(&loggerConfig{
+		level: NewAtomicLevel(DebugLevel),
 		out:   constant.AddSync(out),
-		Format: &Formatter{
+		format: &Formatter{
 			DisableParsingAndEscaping: true,
 		},
 		callerDepth:  4,
 		enableCaller: true,
 		enableTrace:  true,
-	}
+	})
 
 	return logger
 }
 
+// config returns the current configuration snapshot
+//
+//go:inline
+func (p *Logger) config() *loggerConfig {
+	c := p.cfg.Load()
+	if p.Format != nil || p.PrefixMsg != nil || p.SuffixMsg != nil {
+		return p.applyLegacy(c)
+	}
+	return c
+}
+
+// applyLegacy returns c with the deprecated exported fields applied.
+// The result is cached until c or the fields change.
+func (p *Logger) applyLegacy(c *loggerConfig) *loggerConfig {
+	format, prefix, suffix := p.Format, p.PrefixMsg, p.SuffixMsg
+	if l := p.legacy.Load(); l != nil && l.base == c && sameFormat(l.format, format) &&
+		sameBytes(l.prefix, prefix) && sameBytes(l.suffix, suffix) {
+		return l.cfg
+	}
+
+	n := *c
+	if format != nil {
+		n.format = format
+		// Bound fields were encoded for the other formatter
+		n.encodeFields()
+	}
+	if prefix != nil {
+		n.prefixMsg = prefix
+	}
+	if suffix != nil {
+		n.suffixMsg = suffix
+	}
+
+	p.legacy.Store(&legacyConfig{base: c, format: format, prefix: prefix, suffix: suffix, cfg: &n})
+	return &n
+}
+
+// sameFormat reports whether a and b are the same formatter.
+// Formatters that can't be compared are never the same.
+func sameFormat(a, b constant.Format) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	t := reflect.TypeOf(a)
+	return t == reflect.TypeOf(b) && t.Comparable() && a == b
+}
+
+// sameBytes reports whether a and b are the same slice of the same memory
+func sameBytes(a, b []byte) bool {
+	if (a == nil) != (b == nil) || len(a) != len(b) {
+		return false
+	}
+	return len(a) == 0 || &a[0] == &b[0]
+}
+
+// update applies fn to a copy of the current configuration and publishes it
+func (p *Logger) update(fn func(c *loggerConfig)) *Logger {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	c := *p.cfg.Load()
+	fn(&c)
+	p.cfg.Store(&c)
+
+	return p
+}
+
 // SetCallerDepth sets the caller stack depth
 func (p *Logger) SetCallerDepth(callerDepth int) *Logger {
-	p.callerDepth = callerDepth
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.callerDepth = callerDepth
+	})
 }
 
 // SetPrefixMsg sets the log message prefix
 func (p *Logger) SetPrefixMsg(prefixMsg string) *Logger {
-	p.PrefixMsg = []byte(prefixMsg)
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.prefixMsg = []byte(prefixMsg)
+	})
 }
 
 // AppendPrefixMsg appends to the log message prefix
 func (p *Logger) AppendPrefixMsg(prefixMsg string) *Logger {
-	p.PrefixMsg = []byte(string(p.PrefixMsg) + prefixMsg)
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.prefixMsg = []byte(string(c.prefixMsg) + prefixMsg)
+	})
+}
+
+// Prefix returns the log message prefix
+func (p *Logger) Prefix() []byte {
+	return p.config().prefixMsg
 }
 
 // SetSuffixMsg sets the log message suffix
 func (p *Logger) SetSuffixMsg(suffixMsg string) *Logger {
-	p.SuffixMsg = []byte(suffixMsg)
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.suffixMsg = []byte(suffixMsg)
+	})
 }
 
 // AppendSuffixMsg appends to the log message suffix
 func (p *Logger) AppendSuffixMsg(suffixMsg string) *Logger {
-	p.SuffixMsg = []byte(string(p.SuffixMsg) + suffixMsg)
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.suffixMsg = []byte(string(c.suffixMsg) + suffixMsg)
+	})
+}
+
+// Suffix returns the log message suffix
+func (p *Logger) Suffix() []byte {
+	return p.config().suffixMsg
 }
 
 // EnableCaller controls caller information
 func (p *Logger) EnableCaller(enable bool) *Logger {
-	p.enableCaller = enable
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.enableCaller = enable
+	})
 }
 
 // EnableTrace controls trace information
 func (p *Logger) EnableTrace(enable bool) *Logger {
-	p.enableTrace = enable
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.enableTrace = enable
+	})
+}
+
+// SetFormatter sets the formatter used to render entries.
+// Bound fields are re-encoded for the new formatter.
+func (p *Logger) SetFormatter(format constant.Format) *Logger {
+	return p.update(func(c *loggerConfig) {
+		c.format = format
+		c.encodeFields()
+	})
 }
 
-// Clone creates a deep copy of current Logger
+// Formatter returns the formatter currently in use
+func (p *Logger) Formatter() constant.Format {
+	return p.config().format
+}
+
+// Clone creates a deep copy of current Logger, with a level of its own
 func (p *Logger) Clone() *Logger {
-	l := Logger{
-		level:        p.level,
-		out:          p.out,
-		callerDepth:  p.callerDepth,
-		PrefixMsg:    p.PrefixMsg,
-		SuffixMsg:    p.SuffixMsg,
-		enableCaller: p.enableCaller,
-		enableTrace:  p.enableTrace,
-	}
+	c := p.config().clone()
+	c.level = NewAtomicLevel(c.level.Level())
+	c.encodeFields()
 
-	switch f := p.Format.(type) {
-	case constant.FormatFull:
-		l.Format = f.Clone()
-	default:
-		l.Format = f
-	}
+	return newLoggerWith(c)
+}
+
+// newLoggerWith creates a Logger publishing c as its first snapshot
+func newLoggerWith(c *loggerConfig) *Logger {
+	l := &Logger{}
+	l.cfg.Store(c)
+	return l
+}
+
+// clone copies the snapshot with its own formatter. The level and slices are
+// shared, slices are never modified in place.
+func (c *loggerConfig) clone() *loggerConfig {
+	n := *c
 
-	// Copy hooks
-	if len(p.hooks) > 0 {
-		l.hooks = make([]constant.Hook, len(p.hooks))
-		copy(l.hooks, p.hooks)
+	if f, ok := c.format.(constant.FormatFull); ok {
+		n.format = f.Clone()
 	}
 
-	return &l
+	return &n
 }
 
-// SetLevel sets the logging level
+// SetLevel sets the logging level.
+// Loggers sharing the same AtomicLevel all see the change.
 func (p *Logger) SetLevel(level Level) *Logger {
-	p.level = level
+	p.config().level.SetLevel(level)
 	return p
 }
 
 // Level returns the current logging level
 func (p *Logger) Level() Level {
-	return p.level
+	return p.config().level.Level()
+}
+
+// SetAtomicLevel makes the logger use level, which may be shared with other loggers
+func (p *Logger) SetAtomicLevel(level *AtomicLevel) *Logger {
+	if level == nil {
+		return p
+	}
+	return p.update(func(c *loggerConfig) {
+		c.level = level
+	})
+}
+
+// AtomicLevel returns the level handle used by the logger
+func (p *Logger) AtomicLevel() *AtomicLevel {
+	return p.config().level
 }
 
 // SetOutput sets the log output targets
@@ -134,18 +325,21 @@ func (p *Logger) SetOutput(writes ...io.Writer) *Logger {
 		if write == nil {
 			continue
 		}
-		ws = append(ws, constant.AddSync(write))
+		ws = append(ws, addLevelSync(write))
 	}
 
-	if len(ws) == 0 {
-		p.out = nil
-	} else if len(ws) == 1 {
-		p.out = ws[0]
-	} else {
-		p.out = constant.NewMultiWriteSyncer(ws...)
+	var out constant.WriteSyncer
+	if len(ws) == 1 {
+		out = ws[0]
+	} else if len(ws) > 1 {
+		out = newMultiWriteSyncer(ws...)
 	}
 
-	return p
+	levelOut, _ := out.(LevelWriter)
+	return p.update(func(c *loggerConfig) {
+		c.out = out
+		c.levelOut = levelOut
+	})
 }
 
 // Log records a log with specified level
@@ -164,50 +358,48 @@ func (p *Logger) Logf(level Level, format string, args ...interface{}) {
 	p.log(level, fastSprintf(format, args...))
 }
 
-// populateEntry sets basic fields on the log entry
+// populateEntry sets basic fields on the log entry, the time read from the logger's clock
 //
 //go:inline
-func (p *Logger) populateEntry(entry *Entry, level Level, msg string) {
+func (c *loggerConfig) populateEntry(entry *Entry, level Level, msg string) {
 	entry.Level = level
 	entry.Message = msg
-	entry.Time = time.Now()
+	entry.Time = c.now()
 	entry.TimeStr = entry.Time.Format(time.RFC3339Nano)
 	entry.TimeStrSet = true
 }
 
-// populateFields sets structured fields on the log entry
+// populateFields sets structured fields on the log entry, bound fields first
 // args: key1, value1, key2, value2, ...
-func (p *Logger) populateFields(entry *Entry, args ...interface{}) {
+func (c *loggerConfig) populateFields(entry *Entry, args ...interface{}) {
 	if len(args) == 0 {
-		return
+		if len(c.fields) == 0 {
+			return
+		}
+		if len(c.hooks) == 0 {
+			// Nothing can modify the entry, share the bound fields as is.
+			// The capacity is clipped so any append copies first.
+			entry.Fields = c.fields[:len(c.fields):len(c.fields)]
+			c.attachEncodedFields(entry)
+			return
+		}
 	}
 
 	// Pre-allocate for efficiency
-	entry.Fields = make([]KV, 0, len(args)/2)
-
-	// Parse key-value pairs (odd=key, even=value)
-	for i := 0; i < len(args); i += 2 {
-		if i+1 >= len(args) {
-			// Odd number of args, last key without value
-			// Store as string key with nil value
-			entry.Fields = append(entry.Fields, KV{
-				Key:   fmt.Sprintf("%v", args[i]),
-				Value: nil,
-			})
-			break
-		}
+	entry.Fields = make([]KV, 0, len(c.fields)+(len(args)+1)/2)
+	entry.Fields = append(entry.Fields, c.fields...)
+	entry.Fields = appendKVs(entry.Fields, args...)
 
-		key := fmt.Sprintf("%v", args[i])
-		value := args[i+1]
-		entry.Fields = append(entry.Fields, KV{Key: key, Value: value})
+	if len(c.hooks) == 0 {
+		c.attachEncodedFields(entry)
 	}
 }
 
 // fillTraceInfo conditionally sets trace information
 //
 //go:inline
-func (p *Logger) fillTraceInfo(entry *Entry) {
-	if p.enableTrace {
+func (c *loggerConfig) fillTraceInfo(entry *Entry) {
+	if c.enableTrace {
 		entry.Gid = goid.Get()
 		entry.TraceId = getTrace(entry.Gid)
 	}
@@ -216,14 +408,14 @@ func (p *Logger) fillTraceInfo(entry *Entry) {
 // fillCallerInfo conditionally sets caller information
 //
 //go:inline
-func (p *Logger) fillCallerInfo(entry *Entry) {
-	if !p.enableCaller {
+func (c *loggerConfig) fillCallerInfo(entry *Entry) {
+	if !c.enableCaller {
 		return
 	}
 
 	var pc uintptr
 	var ok bool
-	pc, entry.File, entry.CallerLine, ok = runtime.Caller(p.callerDepth)
+	pc, entry.File, entry.CallerLine, ok = runtime.Caller(c.callerDepth)
 	if ok && pc != 0 {
 		if fn := runtime.FuncForPC(pc); fn != nil {
 			entry.CallerName = fn.Name()
@@ -232,15 +424,32 @@ func (p *Logger) fillCallerInfo(entry *Entry) {
 	}
 }
 
+// fillCallerFrame sets caller information from an already captured program counter.
+// It is used when the call site is known upfront (e.g. slog.Record.PC) instead of
+// being derived from callerDepth.
+func (c *loggerConfig) fillCallerFrame(entry *Entry, pc uintptr) {
+	if !c.enableCaller || pc == 0 {
+		return
+	}
+
+	frame, _ := runtime.CallersFrames([]uintptr{pc}).Next()
+	entry.File = frame.File
+	entry.CallerLine = frame.Line
+	if frame.Function != "" {
+		entry.CallerName = frame.Function
+		entry.CallerDir, entry.CallerFunc = SplitPackageName(entry.CallerName)
+	}
+}
+
 // fillPrefixSuffix sets prefix and suffix messages
 //
 //go:inline
-func (p *Logger) fillPrefixSuffix(entry *Entry) {
-	if len(p.PrefixMsg) > 0 {
-		entry.PrefixMsg = p.PrefixMsg
+func (c *loggerConfig) fillPrefixSuffix(entry *Entry) {
+	if len(c.prefixMsg) > 0 {
+		entry.PrefixMsg = c.prefixMsg
 	}
-	if len(p.SuffixMsg) > 0 {
-		entry.SuffixMsg = p.SuffixMsg
+	if len(c.suffixMsg) > 0 {
+		entry.SuffixMsg = c.suffixMsg
 	}
 }
 
@@ -248,45 +457,101 @@ func (p *Logger) fillPrefixSuffix(entry *Entry) {
 //
 //go:noinline
 func (p *Logger) log(level Level, msg string, args ...interface{}) {
+	c := p.config()
+	if c.sampler != nil && !c.sampler.allow(level, msg, c.now().UnixNano()) {
+		return
+	}
+
 	entry := getEntry()
 
-	p.populateEntry(entry, level, msg)
-	p.populateFields(entry, args...)
-	p.fillTraceInfo(entry)
-	p.fillCallerInfo(entry)
-	p.fillPrefixSuffix(entry)
+	c.populateEntry(entry, level, msg)
+	c.populateFields(entry, args...)
+	c.fillTraceInfo(entry)
+	c.fillPrefixSuffix(entry)
+
+	if c.async != nil && c.async.enqueue(c, entry, c.callerPC()) {
+		return
+	}
+
+	c.fillCallerInfo(entry)
+	c.emit(entry)
+}
+
+// emit applies hooks to a fully populated entry, then formats and writes it.
+// The entry is returned to the pool afterwards.
+func (c *loggerConfig) emit(entry *Entry) {
+	level := entry.Level
 
 	// Apply hooks
-	entry = p.applyHooks(entry)
-	if entry == nil {
+	hooked := c.applyHooks(entry)
+	if hooked == nil {
 		// Hook filtered out this log entry
 		putEntry(entry)
 		return
 	}
 
+	c.uniqueFields(hooked)
+
+	if c.dedup != nil && !c.dedup.admit(c, hooked) {
+		// Held back as a duplicate
+		putEntry(entry)
+		return
+	}
+
 	// Format and write
-	formatted := p.Format.Format(entry)
-	p.write(level, formatted)
+	formatted := c.format.Format(hooked)
+	c.write(level, formatted)
 
 	putEntry(entry)
 }
 
+// writeEntry formats and writes an entry that already went through hooks and the deduper
+func (c *loggerConfig) writeEntry(entry *Entry) {
+	c.write(entry.Level, c.format.Format(entry))
+}
+
 // write writes formatted log bytes to output
-func (p *Logger) write(level Level, buf []byte) {
-	_, _ = p.out.Write(buf)
+func (c *loggerConfig) write(level Level, buf []byte) {
+	if c.levelOut != nil {
+		_, _ = c.levelOut.WriteLevel(level, buf)
+	} else if c.out != nil {
+		_, _ = c.out.Write(buf)
+	}
 
 	if level == PanicLevel {
-		p.Sync()
+		c.sync()
 		panic(buf)
 	} else if level == FatalLevel {
-		p.Sync()
+		c.sync()
 		os.Exit(1)
 	}
 }
 
+// sync waits for queued entries and writes entries held back by the deduper,
+// then flushes the output
+func (c *loggerConfig) sync() {
+	if c.async != nil {
+		c.async.flush()
+	}
+	if c.dedup != nil {
+		c.dedup.Flush()
+	}
+	if c.out != nil {
+		_ = c.out.Sync()
+	}
+}
+
 // levelEnabled checks if the level should be logged
+//
+// With module levels the caller is looked up here, before the message is
+// formatted. It must be called directly by the public logging methods so that
+// moduleEnabled sees the caller at the same depth as fillCallerInfo.
 func (p *Logger) levelEnabled(level Level) bool {
-	return p.level >= level
+	c := p.config()
+	if c.modules == nil {
+		return c.level.Enabled(level)
+	}
+	return c.moduleEnabled(level)
 }
 
 // Trace logs at TRACE level
@@ -435,29 +700,40 @@ func (p *Logger) Panicf(format string, args ...interface{}) {
 
 // Sync flushes buffered logs to disk
 func (p *Logger) Sync() {
-	_ = p.out.Sync()
+	p.config().sync()
 }
 
 // ParsingAndEscaping controls log content parsing and escaping
 func (p *Logger) ParsingAndEscaping(disable bool) *Logger {
-	switch f := p.Format.(type) {
-	case constant.FormatFull:
+	return p.updateFormat(func(f constant.FormatFull) {
 		f.ParsingAndEscaping(disable)
-	default:
-		Panicf("%v is not interface constant.FormatFull", f)
-	}
-	return p
+	})
 }
 
 // Caller controls caller information in logs
 func (p *Logger) Caller(disable bool) *Logger {
-	switch f := p.Format.(type) {
-	case constant.FormatFull:
+	return p.updateFormat(func(f constant.FormatFull) {
 		f.Caller(disable)
-	default:
-		Panicf("%v is not interface constant.FormatFull", f)
-	}
-	return p
+	})
+}
+
+// updateFormat applies fn to a clone of the current formatter and swaps it in,
+// so entries being formatted concurrently never observe a half-updated formatter
+func (p *Logger) updateFormat(fn func(f constant.FormatFull)) *Logger {
+	return p.update(func(c *loggerConfig) {
+		f, ok := c.format.(constant.FormatFull)
+		if !ok {
+			Panicf("%v is not interface constant.FormatFull", c.format)
+			return
+		}
+
+		if nf, ok := f.Clone().(constant.FormatFull); ok {
+			f = nf
+		}
+		fn(f)
+		c.format = f
+		c.encodeFields()
+	})
 }
 
 // StartMsg logs a new log start message
@@ -522,12 +798,23 @@ func (p *Logger) Panicw(msg string, args ...interface{}) {
 }
 
 // applyHooks executes all hooks in order
-func (p *Logger) applyHooks(entry *Entry) *Entry {
-	if len(p.hooks) == 0 {
+// Hooks implementing constant.EntryHook are called through the typed OnEntry
+func (c *loggerConfig) applyHooks(entry *Entry) *Entry {
+	if len(c.hooks) == 0 {
 		return entry
 	}
 
-	for _, hook := range p.hooks {
+	for _, hook := range c.hooks {
+		if h, ok := hook.(constant.EntryHook); ok {
+			e, keep := h.OnEntry(entry)
+			if !keep || e == nil {
+				// Hook filtered this log
+				return nil
+			}
+			entry = e
+			continue
+		}
+
 		result := hook.OnWrite(entry)
 		if result == nil {
 			// Hook filtered this log
@@ -544,18 +831,20 @@ func (p *Logger) applyHooks(entry *Entry) *Entry {
 
 // AddHook adds a single hook to the logger
 func (p *Logger) AddHook(hook constant.Hook) *Logger {
-	p.hooks = append(p.hooks, hook)
-	return p
+	return p.AddHooks(hook)
 }
 
 // AddHooks adds multiple hooks to the logger
 func (p *Logger) AddHooks(hooks ...constant.Hook) *Logger {
-	p.hooks = append(p.hooks, hooks...)
-	return p
+	return p.update(func(c *loggerConfig) {
+		// Clip so the previous snapshot's backing array is never written to
+		c.hooks = append(c.hooks[:len(c.hooks):len(c.hooks)], hooks...)
+	})
 }
 
 // RemoveHooks removes all hooks from the logger
 func (p *Logger) RemoveHooks() *Logger {
-	p.hooks = nil
-	return p
+	return p.update(func(c *loggerConfig) {
+		c.hooks = nil
+	})
 }
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/petermattis/goid"
+)
+
+// asyncRecord is an entry captured on the caller goroutine, waiting to be
+// formatted and written by the worker
+type asyncRecord struct {
+	cfg   *loggerConfig
+	entry *Entry
+	pc    uintptr // caller, resolved by the worker
+
+	// summary marks a collapsed line of the deduper, written without hooks
+	// and owned by the deduper
+	summary bool
+
+	// flushed, when set, is closed once the worker reaches this record
+	flushed chan struct{}
+}
+
+// asyncEmitter formats and writes the entries of a logger on its own goroutine.
+//
+// Entries leave the caller fully captured (time, fields, trace, context and
+// the caller's program counter) and are owned by the emitter until the worker
+// returns them to the entry pool, so the pool never hands out an entry still
+// in the queue. A single worker keeps the order in which entries were logged.
+//
+// Hooks and the deduper run on the worker; entries they log through the same
+// logger are emitted right away, since the worker cannot wait on its own queue.
+type asyncEmitter struct {
+	mu      sync.RWMutex
+	closed  bool
+	records chan asyncRecord
+	done    chan struct{}
+
+	// worker is the goroutine id of run
+	worker atomic.Int64
+}
+
+// newAsyncEmitter starts a worker with room for size queued entries
+func newAsyncEmitter(size int) *asyncEmitter {
+	e := &asyncEmitter{
+		records: make(chan asyncRecord, size),
+		done:    make(chan struct{}),
+	}
+	registerEmitter(e)
+	go e.run()
+	return e
+}
+
+// run formats and writes queued entries until close
+func (e *asyncEmitter) run() {
+	defer close(e.done)
+	e.worker.Store(goid.Get())
+
+	for r := range e.records {
+		if r.flushed != nil {
+			close(r.flushed)
+			continue
+		}
+		if r.summary {
+			r.cfg.writeEntry(r.entry)
+			continue
+		}
+		r.cfg.fillCallerFrame(r.entry, r.pc)
+		r.cfg.emit(r.entry)
+	}
+}
+
+// enqueue hands entry over to the worker, waiting for room when the queue is
+// full. It reports false when the caller must emit the entry itself: panic
+// and fatal entries are emitted synchronously once the queue is flushed, and
+// entries logged by the worker itself are never queued.
+func (e *asyncEmitter) enqueue(c *loggerConfig, entry *Entry, pc uintptr) bool {
+	if e.onWorker() {
+		return false
+	}
+	if entry.Level <= FatalLevel {
+		e.flush()
+		return false
+	}
+	return e.push(asyncRecord{cfg: c, entry: entry, pc: pc})
+}
+
+// enqueueSummary hands a collapsed line of the deduper over to the worker,
+// behind the entries queued before. It reports false when the caller must
+// write the line itself.
+func (e *asyncEmitter) enqueueSummary(c *loggerConfig, entry *Entry) bool {
+	if e.onWorker() {
+		return false
+	}
+	return e.push(asyncRecord{cfg: c, entry: entry, summary: true})
+}
+
+// push queues r unless the emitter is closed
+func (e *asyncEmitter) push(r asyncRecord) bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.closed {
+		return false
+	}
+	e.records <- r
+	return true
+}
+
+// flush waits until every entry queued before the call is written.
+// On the worker, entries queued before are already written.
+func (e *asyncEmitter) flush() {
+	if e.onWorker() {
+		return
+	}
+
+	e.mu.RLock()
+	if e.closed {
+		e.mu.RUnlock()
+		return
+	}
+	flushed := make(chan struct{})
+	e.records <- asyncRecord{flushed: flushed}
+	e.mu.RUnlock()
+
+	<-flushed
+}
+
+// onWorker reports whether the calling goroutine is the worker
+func (e *asyncEmitter) onWorker() bool {
+	return e.worker.Load() == goid.Get()
+}
+
+// close writes out the queue and stops the worker.
+// Loggers still holding the emitter go back to writing synchronously.
+func (e *asyncEmitter) close() {
+	e.mu.Lock()
+	if !e.closed {
+		e.closed = true
+		close(e.records)
+	}
+	e.mu.Unlock()
+	unregisterEmitter(e)
+
+	// The worker drains the rest once it returns
+	if e.onWorker() {
+		return
+	}
+	<-e.done
+}
+
+// callerPC returns the program counter of the caller fillCallerInfo would
+// report. Like fillCallerInfo it must be called directly by log or logCtx.
+//
+//go:noinline
+func (c *loggerConfig) callerPC() uintptr {
+	if !c.enableCaller {
+		return 0
+	}
+
+	var pcs [1]uintptr
+	// Callers counts itself, Caller does not
+	if runtime.Callers(c.callerDepth+1, pcs[:]) == 0 {
+		return 0
+	}
+	return pcs[0]
+}
+
+// SetAsync makes the logger format and write entries on a background worker
+// with room for size queued entries; the caller only captures the entry.
+// Logging blocks while the queue is full. A size of 0 or less writes out the
+// queue and returns to synchronous logging.
+//
+// Entries keep the order they were logged in. Panic and fatal entries are
+// written synchronously after the queue, and Sync waits for the queue too.
+// Hooks and the deduper run on the worker, entries hooks log through the
+// same logger are written synchronously. Field values are formatted
+// later, so values that are mutated after logging must be copied first.
+//
+// Loggers derived with Clone or With share the worker.
+func (p *Logger) SetAsync(size int) *Logger {
+	var old *asyncEmitter
+	p.update(func(c *loggerConfig) {
+		old = c.async
+		c.async = nil
+		if size > 0 {
+			c.async = newAsyncEmitter(size)
+		}
+	})
+
+	if old != nil {
+		old.close()
+	}
+	return p
+}
+
+// Async reports whether the logger formats and writes on a background worker
+func (p *Logger) Async() bool {
+	return p.config().async != nil
+}
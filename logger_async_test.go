@@ -0,0 +1,240 @@
+package log
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// callerFormat formats entries as "file:line message" to check caller info
+type callerFormat struct{}
+
+func (callerFormat) Format(entry interface{}) []byte {
+	e := entry.(*Entry)
+	return fmt.Appendf(nil, "%s:%d %s\n", filepath.Base(e.File), e.CallerLine, e.Message)
+}
+
+// here returns file:line of its caller in callerFormat form
+func here(offset int) string {
+	_, file, line, _ := runtime.Caller(1)
+	return filepath.Base(file) + ":" + strconv.Itoa(line+offset)
+}
+
+func TestLogger_SetAsyncOrdering(t *testing.T) {
+	w := &recordingWriter{}
+	logger := New().SetOutput(w).EnableTrace(false).SetAsync(4)
+	defer logger.SetAsync(0)
+
+	if !logger.Async() {
+		t.Fatal("logger should be async")
+	}
+
+	for i := range 100 {
+		logger.Infof("entry %d", i)
+	}
+	logger.Sync()
+
+	writes := w.Writes()
+	if len(writes) != 100 {
+		t.Fatalf("expected 100 writes after Sync, got %d", len(writes))
+	}
+	for i, line := range writes {
+		if !strings.Contains(line, fmt.Sprintf("entry %d", i)) {
+			t.Fatalf("write %d out of order: %q", i, line)
+		}
+	}
+}
+
+func TestLogger_SetAsyncCaller(t *testing.T) {
+	w := &recordingWriter{}
+	// Depth 3 reports the caller of a Logger method rather than of the package functions
+	logger := New().SetOutput(w).EnableTrace(false).SetCallerDepth(3).SetFormatter(callerFormat{}).SetAsync(8)
+	defer logger.SetAsync(0)
+
+	logger.Info("plain")
+	want := here(-1)
+	logger.InfowContext(context.Background(), "ctx", "k", "v")
+	wantCtx := here(-1)
+	NewSlogLogger(logger).Info("slog")
+	wantSlog := here(-1)
+	logger.Sync()
+
+	writes := w.Writes()
+	expected := []string{want + " plain\n", wantCtx + " ctx\n", wantSlog + " slog\n"}
+	if len(writes) != len(expected) {
+		t.Fatalf("expected %d writes, got %q", len(expected), writes)
+	}
+	for i := range expected {
+		if writes[i] != expected[i] {
+			t.Errorf("write %d = %q, want %q", i, writes[i], expected[i])
+		}
+	}
+}
+
+func TestLogger_SetAsyncDisable(t *testing.T) {
+	hold := make(chan struct{})
+	w := &recordingWriter{hold: hold}
+	logger := New().SetOutput(w).EnableTrace(false).SetAsync(8)
+
+	logger.Info("queued")
+	if got := len(w.Writes()); got != 0 {
+		t.Fatalf("entry should still be queued, got %d writes", got)
+	}
+
+	close(hold)
+	logger.SetAsync(0)
+	if logger.Async() {
+		t.Fatal("SetAsync(0) should disable async logging")
+	}
+	if got := len(w.Writes()); got != 1 {
+		t.Fatalf("disabling should write out the queue, got %d writes", got)
+	}
+
+	logger.Info("direct")
+	if got := len(w.Writes()); got != 2 {
+		t.Fatalf("sync logging should write immediately, got %d writes", got)
+	}
+}
+
+func TestLogger_SetAsyncPanicIsSynchronous(t *testing.T) {
+	w := &recordingWriter{}
+	logger := New().SetOutput(w).EnableTrace(false).SetAsync(8)
+	defer logger.SetAsync(0)
+
+	logger.Info("before")
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Panic should panic on the caller")
+			}
+		}()
+		logger.Panic("boom")
+	}()
+
+	writes := w.Writes()
+	if len(writes) != 2 || !strings.Contains(writes[0], "before") || !strings.Contains(writes[1], "boom") {
+		t.Fatalf("panic entry should follow the queue, got %q", writes)
+	}
+}
+
+func TestLogger_SetAsyncCloneShares(t *testing.T) {
+	w := &recordingWriter{}
+	logger := New().SetOutput(w).EnableTrace(false).SetAsync(8)
+	defer logger.SetAsync(0)
+
+	child := logger.With("child", true)
+	if !child.Async() {
+		t.Fatal("With should keep async logging")
+	}
+	child.Info("from child")
+	logger.Info("from parent")
+	logger.Sync()
+
+	writes := w.Writes()
+	if len(writes) != 2 || !strings.Contains(writes[0], "from child") || !strings.Contains(writes[1], "from parent") {
+		t.Fatalf("derived loggers should share the queue, got %q", writes)
+	}
+}
+
+func TestLogger_SetAsyncReentrant(t *testing.T) {
+	w := &recordingWriter{}
+	// Room for one entry, so the worker would wait on itself
+	logger := New().SetOutput(w).EnableTrace(false).SetAsync(1)
+	defer logger.SetAsync(0)
+
+	logger.AddHook(constant.HookFunc(func(entry interface{}) interface{} {
+		if e := entry.(*Entry); e.Message == "outer" {
+			logger.Info("from hook 1")
+			logger.Info("from hook 2")
+			logger.Sync()
+		}
+		return entry
+	}))
+
+	done := make(chan struct{})
+	go func() {
+		for range 4 {
+			logger.Info("outer")
+		}
+		logger.Sync()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("logging from a hook on the worker should not deadlock")
+	}
+
+	writes := w.Writes()
+	if len(writes) != 12 || !strings.Contains(writes[0], "from hook 1") || !strings.Contains(writes[2], "outer") {
+		t.Fatalf("hook entries should be written before the entry that logged them, got %q", writes)
+	}
+}
+
+func TestLogger_SetAsyncConcurrent(t *testing.T) {
+	w := &recordingWriter{}
+	logger := New().SetOutput(w).EnableTrace(false).SetAsync(16)
+	defer logger.SetAsync(0)
+
+	var wg sync.WaitGroup
+	for g := range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range 200 {
+				logger.Infow("concurrent", "g", g, "i", i)
+				if i == 100 && g == 0 {
+					// Replace the worker while others log
+					logger.SetAsync(16)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	logger.Sync()
+
+	// Every goroutine's entries keep their order
+	last := make(map[int]int)
+	for _, line := range w.Writes() {
+		var g, i int
+		if _, err := fmt.Sscanf(line[strings.Index(line, "g=")+2:], "%d i=%d", &g, &i); err != nil {
+			continue
+		}
+		if prev, ok := last[g]; ok && i <= prev {
+			t.Fatalf("goroutine %d wrote %d after %d", g, i, prev)
+		}
+		last[g] = i
+	}
+	if got := len(w.Writes()); got != 8*200 {
+		t.Fatalf("expected %d writes, got %d", 8*200, got)
+	}
+}
+
+func TestSetAsync(t *testing.T) {
+	defer SetAsync(0)
+
+	if !SetAsync(4).Async() || !std.Async() {
+		t.Fatal("SetAsync should make the standard logger async")
+	}
+}
+
+func BenchmarkLogger_Async(b *testing.B) {
+	logger := New().SetOutput(io.Discard).EnableTrace(false).SetAsync(1024)
+	defer logger.SetAsync(0)
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Infow("benchmark", "i", i)
+	}
+	logger.Sync()
+}
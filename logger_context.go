@@ -0,0 +1,213 @@
+package log
+
+import "context"
+
+// logCtx is the context-aware counterpart of log.
+// It must stay at the same stack depth as log so callerDepth applies to both.
+//
+//go:noinline
+func (p *Logger) logCtx(ctx context.Context, level Level, msg string, args ...interface{}) {
+	c := p.config()
+	if c.sampler != nil && !c.sampler.allow(level, msg, c.now().UnixNano()) {
+		return
+	}
+
+	entry := getEntry()
+
+	c.populateEntry(entry, level, msg)
+	c.populateFields(entry, args...)
+	c.fillTraceInfo(entry)
+	c.fillPrefixSuffix(entry)
+	c.fillContext(ctx, entry)
+
+	if c.async != nil && c.async.enqueue(c, entry, c.callerPC()) {
+		return
+	}
+
+	c.fillCallerInfo(entry)
+	c.emit(entry)
+}
+
+// LogContext records a log with specified level and context
+func (p *Logger) LogContext(ctx context.Context, level Level, args ...interface{}) {
+	if !p.levelEnabled(level) {
+		return
+	}
+	p.logCtx(ctx, level, fastSprint(args...))
+}
+
+// LogfContext records a formatted log with context
+func (p *Logger) LogfContext(ctx context.Context, level Level, format string, args ...interface{}) {
+	if !p.levelEnabled(level) {
+		return
+	}
+	p.logCtx(ctx, level, fastSprintf(format, args...))
+}
+
+// TraceContext logs at TRACE level with context
+func (p *Logger) TraceContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(TraceLevel) {
+		return
+	}
+	p.logCtx(ctx, TraceLevel, fastSprint(args...))
+}
+
+// DebugContext logs at DEBUG level with context
+func (p *Logger) DebugContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(DebugLevel) {
+		return
+	}
+	p.logCtx(ctx, DebugLevel, fastSprint(args...))
+}
+
+// InfoContext logs at INFO level with context
+func (p *Logger) InfoContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(InfoLevel) {
+		return
+	}
+	p.logCtx(ctx, InfoLevel, fastSprint(args...))
+}
+
+// WarnContext logs at WARN level with context
+func (p *Logger) WarnContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(WarnLevel) {
+		return
+	}
+	p.logCtx(ctx, WarnLevel, fastSprint(args...))
+}
+
+// ErrorContext logs at ERROR level with context
+func (p *Logger) ErrorContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(ErrorLevel) {
+		return
+	}
+	p.logCtx(ctx, ErrorLevel, fastSprint(args...))
+}
+
+// PanicContext logs at PANIC level with context and panics
+func (p *Logger) PanicContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(PanicLevel) {
+		return
+	}
+	p.logCtx(ctx, PanicLevel, fastSprint(args...))
+}
+
+// FatalContext logs at FATAL level with context and exits
+func (p *Logger) FatalContext(ctx context.Context, args ...interface{}) {
+	if !p.levelEnabled(FatalLevel) {
+		return
+	}
+	p.logCtx(ctx, FatalLevel, fastSprint(args...))
+}
+
+// TracefContext logs formatted TRACE level message with context
+func (p *Logger) TracefContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(TraceLevel) {
+		return
+	}
+	p.logCtx(ctx, TraceLevel, fastSprintf(format, args...))
+}
+
+// DebugfContext logs formatted DEBUG level message with context
+func (p *Logger) DebugfContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(DebugLevel) {
+		return
+	}
+	p.logCtx(ctx, DebugLevel, fastSprintf(format, args...))
+}
+
+// InfofContext logs formatted INFO level message with context
+func (p *Logger) InfofContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(InfoLevel) {
+		return
+	}
+	p.logCtx(ctx, InfoLevel, fastSprintf(format, args...))
+}
+
+// WarnfContext logs formatted WARN level message with context
+func (p *Logger) WarnfContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(WarnLevel) {
+		return
+	}
+	p.logCtx(ctx, WarnLevel, fastSprintf(format, args...))
+}
+
+// ErrorfContext logs formatted ERROR level message with context
+func (p *Logger) ErrorfContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(ErrorLevel) {
+		return
+	}
+	p.logCtx(ctx, ErrorLevel, fastSprintf(format, args...))
+}
+
+// PanicfContext logs formatted PANIC level message with context and panics
+func (p *Logger) PanicfContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(PanicLevel) {
+		return
+	}
+	p.logCtx(ctx, PanicLevel, fastSprintf(format, args...))
+}
+
+// FatalfContext logs formatted FATAL level message with context and exits
+func (p *Logger) FatalfContext(ctx context.Context, format string, args ...interface{}) {
+	if !p.levelEnabled(FatalLevel) {
+		return
+	}
+	p.logCtx(ctx, FatalLevel, fastSprintf(format, args...))
+}
+
+// TracewContext logs TRACE level with structured fields and context
+func (p *Logger) TracewContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(TraceLevel) {
+		return
+	}
+	p.logCtx(ctx, TraceLevel, msg, args...)
+}
+
+// DebugwContext logs DEBUG level with structured fields and context
+func (p *Logger) DebugwContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(DebugLevel) {
+		return
+	}
+	p.logCtx(ctx, DebugLevel, msg, args...)
+}
+
+// InfowContext logs INFO level with structured fields and context
+func (p *Logger) InfowContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(InfoLevel) {
+		return
+	}
+	p.logCtx(ctx, InfoLevel, msg, args...)
+}
+
+// WarnwContext logs WARN level with structured fields and context
+func (p *Logger) WarnwContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(WarnLevel) {
+		return
+	}
+	p.logCtx(ctx, WarnLevel, msg, args...)
+}
+
+// ErrorwContext logs ERROR level with structured fields and context
+func (p *Logger) ErrorwContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(ErrorLevel) {
+		return
+	}
+	p.logCtx(ctx, ErrorLevel, msg, args...)
+}
+
+// PanicwContext logs PANIC level with structured fields and context and panics
+func (p *Logger) PanicwContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(PanicLevel) {
+		return
+	}
+	p.logCtx(ctx, PanicLevel, msg, args...)
+}
+
+// FatalwContext logs FATAL level with structured fields and context and exits
+func (p *Logger) FatalwContext(ctx context.Context, msg string, args ...interface{}) {
+	if !p.levelEnabled(FatalLevel) {
+		return
+	}
+	p.logCtx(ctx, FatalLevel, msg, args...)
+}
@@ -0,0 +1,131 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestLoggerContextMethods(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(TraceLevel)
+	logger.EnableCaller(false)
+	logger.EnableTrace(false)
+	ctx := ContextWithFields(context.Background(), "ctx", "yes")
+
+	tests := []struct {
+		name  string
+		call  func()
+		level string
+		msg   string
+	}{
+		{"TraceContext", func() { logger.TraceContext(ctx, "m1") }, "trace", "m1"},
+		{"DebugContext", func() { logger.DebugContext(ctx, "m2") }, "debug", "m2"},
+		{"InfoContext", func() { logger.InfoContext(ctx, "m3") }, "info", "m3"},
+		{"WarnContext", func() { logger.WarnContext(ctx, "m4") }, "warn", "m4"},
+		{"ErrorContext", func() { logger.ErrorContext(ctx, "m5") }, "error", "m5"},
+		{"TracefContext", func() { logger.TracefContext(ctx, "f%d", 1) }, "trace", "f1"},
+		{"DebugfContext", func() { logger.DebugfContext(ctx, "f%d", 2) }, "debug", "f2"},
+		{"InfofContext", func() { logger.InfofContext(ctx, "f%d", 3) }, "info", "f3"},
+		{"WarnfContext", func() { logger.WarnfContext(ctx, "f%d", 4) }, "warn", "f4"},
+		{"ErrorfContext", func() { logger.ErrorfContext(ctx, "f%d", 5) }, "error", "f5"},
+		{"TracewContext", func() { logger.TracewContext(ctx, "w1", "k", 1) }, "trace", "w1"},
+		{"DebugwContext", func() { logger.DebugwContext(ctx, "w2", "k", 2) }, "debug", "w2"},
+		{"InfowContext", func() { logger.InfowContext(ctx, "w3", "k", 3) }, "info", "w3"},
+		{"WarnwContext", func() { logger.WarnwContext(ctx, "w4", "k", 4) }, "warn", "w4"},
+		{"ErrorwContext", func() { logger.ErrorwContext(ctx, "w5", "k", 5) }, "error", "w5"},
+		{"LogContext", func() { logger.LogContext(ctx, InfoLevel, "l1") }, "info", "l1"},
+		{"LogfContext", func() { logger.LogfContext(ctx, WarnLevel, "l%d", 2) }, "warn", "l2"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf.Reset()
+			tt.call()
+			out := buf.String()
+			if !strings.Contains(out, "["+tt.level+"]") || !strings.Contains(out, tt.msg) {
+				t.Errorf("expected [%s] %s, got %q", tt.level, tt.msg, out)
+			}
+			if !strings.Contains(out, "ctx=yes") {
+				t.Errorf("expected context fields, got %q", out)
+			}
+		})
+	}
+}
+
+func TestLoggerContextMethodsDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(ErrorLevel)
+	ctx := context.Background()
+
+	logger.DebugContext(ctx, "no")
+	logger.InfofContext(ctx, "no %d", 1)
+	logger.WarnwContext(ctx, "no", "k", "v")
+	logger.LogContext(ctx, TraceLevel, "no")
+	logger.LogfContext(ctx, TraceLevel, "no")
+
+	if buf.Len() != 0 {
+		t.Errorf("disabled levels should not be written, got %q", buf.String())
+	}
+}
+
+func TestPackageContextFunctions(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.SetLevel(TraceLevel)
+	logger.EnableTrace(false)
+	ctx := NewContext(context.Background(), logger)
+
+	TraceContext(ctx, "a")
+	DebugfContext(ctx, "b%d", 1)
+	InfowContext(ctx, "c", "k", "v")
+	WarnContext(ctx, "d")
+	ErrorfContext(ctx, "e%d", 2)
+	LogContext(ctx, InfoLevel, "f")
+	LogfContext(ctx, InfoLevel, "g%d", 3)
+
+	out := buf.String()
+	for _, want := range []string{"a", "b1", "c k=v", "d", "e2", "f", "g3"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in output of logger from context, got %q", want, out)
+		}
+	}
+
+	// Package level functions report the caller of the package function
+	if !strings.Contains(out, "logger_context_test.go") {
+		t.Errorf("expected caller in this file, got %q", out)
+	}
+}
+
+func TestPanicContext(t *testing.T) {
+	logger := New()
+	logger.SetOutput(&bytes.Buffer{})
+
+	defer func() {
+		if recover() == nil {
+			t.Error("PanicContext should panic")
+		}
+	}()
+	logger.PanicwContext(context.Background(), "boom", "k", "v")
+}
+
+func TestFatalContext(t *testing.T) {
+	if os.Getenv("TEST_FATAL_CONTEXT_EXIT") == "1" {
+		New().FatalfContext(context.Background(), "fatal %s", "ctx")
+		return
+	}
+
+	cmd := exec.Command(os.Args[0], "-test.run=TestFatalContext")
+	cmd.Env = append(os.Environ(), "TEST_FATAL_CONTEXT_EXIT=1")
+	if err := cmd.Run(); err == nil {
+		t.Error("expected non-zero exit status")
+	}
+}
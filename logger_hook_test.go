@@ -1,6 +1,7 @@
 package log
 
 import (
+	"bytes"
 	"strings"
 	"testing"
 
@@ -106,3 +107,44 @@ func TestHook_RemoveHooks(t *testing.T) {
 		t.Error("hook should not be called after removal")
 	}
 }
+
+func TestHook_EntryHook(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New()
+	logger.SetOutput(&buf)
+	logger.EnableCaller(false)
+	logger.EnableTrace(false)
+
+	legacyCalled := false
+	logger.AddHooks(
+		constant.EntryHookFunc(func(entry *Entry) (*Entry, bool) {
+			if entry.Message == "drop" {
+				return nil, false
+			}
+			for i := range entry.Fields {
+				if entry.Fields[i].Key == "password" {
+					entry.Fields[i].Value = "***"
+				}
+			}
+			return entry, true
+		}),
+		constant.HookFunc(func(entry interface{}) interface{} {
+			legacyCalled = true
+			return entry
+		}),
+	)
+
+	logger.Infow("login", "user", "alice", "password", "hunter2")
+	if out := buf.String(); !strings.Contains(out, "password=***") || strings.Contains(out, "hunter2") {
+		t.Errorf("typed hook should modify fields, got %q", out)
+	}
+	if !legacyCalled {
+		t.Error("legacy hooks should still run after typed hooks")
+	}
+
+	buf.Reset()
+	logger.Info("drop")
+	if buf.Len() != 0 {
+		t.Errorf("typed hook should filter entries, got %q", buf.String())
+	}
+}
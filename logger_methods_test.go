@@ -210,7 +210,7 @@ func TestLoggerPopulateFieldsCoverage(t *testing.T) {
 
 	t.Run("populateFields_even_args", func(t *testing.T) {
 		entry := &Entry{}
-		logger.populateFields(entry, "k1", "v1", "k2", "v2")
+		logger.config().populateFields(entry, "k1", "v1", "k2", "v2")
 		if len(entry.Fields) != 2 {
 			t.Errorf("Expected 2 fields, got %d", len(entry.Fields))
 		}
@@ -218,7 +218,7 @@ func TestLoggerPopulateFieldsCoverage(t *testing.T) {
 
 	t.Run("populateFields_odd_args", func(t *testing.T) {
 		entry := &Entry{}
-		logger.populateFields(entry, "k1", "v1", "k2")
+		logger.config().populateFields(entry, "k1", "v1", "k2")
 		if len(entry.Fields) != 2 {
 			t.Errorf("Expected 2 fields (last with nil), got %d", len(entry.Fields))
 		}
@@ -229,7 +229,7 @@ func TestLoggerPopulateFieldsCoverage(t *testing.T) {
 
 	t.Run("populateFields_no_args", func(t *testing.T) {
 		entry := &Entry{}
-		logger.populateFields(entry)
+		logger.config().populateFields(entry)
 		if len(entry.Fields) != 0 {
 			t.Errorf("Expected 0 fields, got %d", len(entry.Fields))
 		}
@@ -237,7 +237,7 @@ func TestLoggerPopulateFieldsCoverage(t *testing.T) {
 
 	t.Run("populateFields_many_fields", func(t *testing.T) {
 		entry := &Entry{}
-		logger.populateFields(entry,
+		logger.config().populateFields(entry,
 			"k1", "v1",
 			"k2", "v2",
 			"k3", "v3",
@@ -261,7 +261,7 @@ func TestLoggerWriteCoverage(t *testing.T) {
 
 	t.Run("write_normal", func(t *testing.T) {
 		buf.Reset()
-		logger.write(InfoLevel, []byte("test message\n"))
+		logger.config().write(InfoLevel, []byte("test message\n"))
 		if buf.Len() == 0 {
 			t.Error("write should write to output")
 		}
@@ -272,7 +272,7 @@ func TestLoggerWriteCoverage(t *testing.T) {
 
 	t.Run("write_empty", func(t *testing.T) {
 		buf.Reset()
-		logger.write(InfoLevel, []byte{})
+		logger.config().write(InfoLevel, []byte{})
 		// Should not panic
 	})
 }
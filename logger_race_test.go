@@ -0,0 +1,154 @@
+package log
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// lockedBuffer is a bytes.Buffer safe for concurrent writes
+type lockedBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *lockedBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *lockedBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+func TestLogger_ConcurrentReconfigure(t *testing.T) {
+	out := &lockedBuffer{}
+	logger := New().SetOutput(out)
+	child := logger.With("svc", "api")
+
+	const loggers = 8
+	const iterations = 200
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	for i := 0; i < loggers; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < iterations; j++ {
+				logger.Infow("logging", "worker", i, "n", j)
+				logger.Debugf("debug %d", j)
+				child.Warn("child")
+				_ = logger.Level()
+				_ = logger.Formatter()
+				_ = logger.Prefix()
+			}
+		}(i)
+	}
+
+	var admin sync.WaitGroup
+	admin.Add(1)
+	go func() {
+		defer admin.Done()
+		levels := []Level{TraceLevel, InfoLevel, DebugLevel, WarnLevel}
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+
+			logger.SetLevel(levels[i%len(levels)])
+			logger.SetOutput(out, io.Discard)
+			logger.SetPrefixMsg("[p]").AppendSuffixMsg("")
+			logger.EnableCaller(i%2 == 0).EnableTrace(i%3 == 0)
+			logger.AddHook(constant.HookFunc(func(entry interface{}) interface{} { return entry }))
+			if i%10 == 0 {
+				logger.RemoveHooks()
+			}
+			if i%2 == 0 {
+				logger.SetFormatter(&JSONFormatter{})
+			} else {
+				logger.SetFormatter(&Formatter{})
+				logger.Caller(i%4 == 1)
+			}
+			child.SetLevel(levels[i%len(levels)])
+		}
+	}()
+
+	wg.Wait()
+	close(stop)
+	admin.Wait()
+
+	if out.String() == "" {
+		t.Error("expected some output while reconfiguring")
+	}
+}
+
+func TestLogger_ConcurrentAddHook(t *testing.T) {
+	logger := New().SetOutput(io.Discard)
+
+	const n = 50
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			logger.AddHook(constant.HookFunc(func(entry interface{}) interface{} { return entry }))
+		}()
+		go func() {
+			defer wg.Done()
+			logger.Info("concurrent")
+		}()
+	}
+	wg.Wait()
+
+	if got := len(logger.config().hooks); got != n {
+		t.Errorf("expected %d hooks, got %d", n, got)
+	}
+}
+
+func TestLogger_SnapshotIsolation(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false)
+
+	before := logger.config()
+	logger.SetPrefixMsg("[new]").SetFormatter(&JSONFormatter{})
+
+	if len(before.prefixMsg) != 0 {
+		t.Error("published snapshots must not be modified by setters")
+	}
+	if _, ok := before.format.(*Formatter); !ok {
+		t.Errorf("old snapshot should keep its formatter, got %T", before.format)
+	}
+
+	logger.Error("json")
+	if !strings.HasPrefix(buf.String(), "{") || !strings.Contains(buf.String(), "[new]") {
+		t.Errorf("new configuration should apply to later entries, got %q", buf.String())
+	}
+}
+
+func TestLogger_CallerDoesNotMutateSharedFormatter(t *testing.T) {
+	logger := New()
+	f, ok := logger.Formatter().(*Formatter)
+	if !ok {
+		t.Fatalf("unexpected default formatter %T", logger.Formatter())
+	}
+
+	logger.Caller(true)
+
+	if f.DisableCaller {
+		t.Error("Caller should swap in a modified clone instead of mutating the formatter in use")
+	}
+	if nf := logger.Formatter().(*Formatter); !nf.DisableCaller {
+		t.Error("Caller(true) should disable caller on the new formatter")
+	}
+}
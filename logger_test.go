@@ -11,6 +11,12 @@
 	"github.com/lazygophers/log/constant"
 )
 
+// newTestLogger returns a logger writing every entry to out, without caller
+// and trace information
+func newTestLogger(out io.Writer) *Logger {
+	return New().SetOutput(out).EnableCaller(false).EnableTrace(false).SetLevel(TraceLevel)
+}
+
 // Additional tests for edge cases and remaining coverage
 
 func TestLoggerWithFieldsEdgeCases(t *testing.T) {
@@ -306,12 +312,12 @@ func TestCloneWithFormatFull(t *testing.T) {
 			DisableCaller:             true,
 			DisableParsingAndEscaping: true,
 		}
-		logger.Format = formatter
+		logger.SetFormatter(formatter)
 
 		cloned := logger.Clone()
 
 		// Verify Format was cloned, not just copied
-		clonedFormatter, ok := cloned.Format.(*Formatter)
+		clonedFormatter, ok := cloned.Formatter().(*Formatter)
 		if !ok {
 			t.Fatal("Cloned format should be *Formatter")
 		}
@@ -341,13 +347,13 @@ func TestLoggerCloneBranches(t *testing.T) {
 		cloned := logger.Clone()
 
 		// Verify cloned has its own hooks slice
-		if len(cloned.hooks) != len(logger.hooks) {
-			t.Errorf("Clone should copy hooks, got %d want %d", len(cloned.hooks), len(logger.hooks))
+		if len(cloned.config().hooks) != len(logger.config().hooks) {
+			t.Errorf("Clone should copy hooks, got %d want %d", len(cloned.config().hooks), len(logger.config().hooks))
 		}
 
 		// Verify hooks are independent
 		cloned.RemoveHooks()
-		if len(logger.hooks) == 0 {
+		if len(logger.config().hooks) == 0 {
 			t.Error("Original logger hooks should not be affected by clone")
 		}
 	})
@@ -361,8 +367,8 @@ func TestLoggerCloneBranches(t *testing.T) {
 		if cloned == nil {
 			t.Error("Clone should return non-nil logger")
 		}
-		if cloned.level != logger.level {
-			t.Errorf("Clone should copy level, got %v want %v", cloned.level, logger.level)
+		if cloned.Level() != logger.Level() {
+			t.Errorf("Clone should copy level, got %v want %v", cloned.Level(), logger.Level())
 		}
 	})
 }
@@ -390,7 +396,7 @@ func TestLoggerCloneWithHooksFull(t *testing.T) {
 	}
 
 	cloned.RemoveHooks()
-	if len(logger.hooks) == 0 {
+	if len(logger.config().hooks) == 0 {
 		t.Error("Original logger should still have hooks")
 	}
 }
@@ -408,14 +414,14 @@ func (h *testCloneHook) Levels() []constant.Level {
 func TestLoggerSetOutputVariations(t *testing.T) {
 	logger := New()
 
-	original := logger.out
+	original := logger.config().out
 	logger.SetOutput(nil)
-	if logger.out != nil {
+	if logger.config().out != nil {
 		t.Error("output should be nil")
 	}
 
 	logger.SetOutput(original)
-	if logger.out != original {
+	if logger.config().out != original {
 		t.Error("output should be restored")
 	}
 }
@@ -431,14 +437,14 @@ func TestLoggerCloneAllFormats(t *testing.T) {
 
 	for _, fmt := range formats {
 		logger := New()
-		logger.Format = fmt
+		logger.SetFormatter(fmt)
 
 		cloned := logger.Clone()
 
 		if cloned == nil {
 			t.Error("Clone should return non-nil")
 		}
-		if cloned.level != logger.level {
+		if cloned.Level() != logger.Level() {
 			t.Error("Clone should copy level")
 		}
 	}
@@ -470,16 +476,67 @@ func TestLoggerCloneComprehensive(t *testing.T) {
 
 	cloned := logger.Clone()
 
-	if cloned.level != logger.level {
+	if cloned.Level() != logger.Level() {
 		t.Error("level not cloned")
 	}
-	if cloned.callerDepth != logger.callerDepth {
+	if cloned.config().callerDepth != logger.config().callerDepth {
 		t.Error("callerDepth not cloned")
 	}
-	if cloned.enableCaller != logger.enableCaller {
+	if cloned.config().enableCaller != logger.config().enableCaller {
 		t.Error("enableCaller not cloned")
 	}
-	if cloned.enableTrace != logger.enableTrace {
+	if cloned.config().enableTrace != logger.config().enableTrace {
 		t.Error("enableTrace not cloned")
 	}
 }
+
+func TestLoggerDeprecatedFields(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false)
+	child := logger.With("k", "v")
+
+	logger.Format = &JSONFormatter{}
+	logger.PrefixMsg = []byte("[legacy]")
+	logger.SuffixMsg = []byte("[end]")
+	logger.Info("assigned")
+
+	out := buf.String()
+	if !strings.Contains(out, `"prefix_msg":"[legacy]"`) || !strings.Contains(out, `"suffix_msg":"[end]"`) {
+		t.Errorf("assigned fields should still take effect, got %q", out)
+	}
+	if logger.Formatter() != logger.Format || string(logger.Prefix()) != "[legacy]" {
+		t.Error("getters should report the assigned values")
+	}
+
+	// They are applied once per snapshot, not on every call
+	applied := logger.config()
+	if logger.config() != applied {
+		t.Error("the assigned fields should be applied once")
+	}
+	logger.SetCallerDepth(4)
+	if c := logger.config(); c == applied || c.format != logger.Format {
+		t.Error("a new snapshot should have the assigned fields applied again")
+	}
+
+	// Derived loggers inherit them like Clone used to copy them
+	buf.Reset()
+	logger.With("n", 1).Info("derived")
+	if !strings.Contains(buf.String(), `"prefix_msg":"[legacy]"`) || !strings.Contains(buf.String(), `"n":1`) {
+		t.Errorf("derived logger should inherit the assigned fields, got %q", buf.String())
+	}
+
+	// Loggers derived before keep their own configuration
+	buf.Reset()
+	child.Info("child")
+	if strings.Contains(buf.String(), "legacy") {
+		t.Errorf("earlier children should not see the assigned fields, got %q", buf.String())
+	}
+
+	// Resetting them falls back to the setters
+	buf.Reset()
+	logger.Format, logger.PrefixMsg, logger.SuffixMsg = nil, nil, nil
+	logger.Info("reset")
+	if strings.Contains(buf.String(), "legacy") || strings.HasPrefix(buf.String(), "{") {
+		t.Errorf("reset fields should no longer apply, got %q", buf.String())
+	}
+}
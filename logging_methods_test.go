@@ -174,11 +174,11 @@ func TestLogger_Fatalf(t *testing.T) {
 
 func TestGlobalLoggingMethods(t *testing.T) {
 	var buf bytes.Buffer
-	originalOut := std.out
+	originalOut := std.config().out
 	std.SetOutput(&buf)
 	std.SetLevel(TraceLevel)
 	defer func() {
-		std.out = originalOut
+		std.SetOutput(originalOut)
 	}()
 
 	// 测试全局日志方法
@@ -207,11 +207,11 @@ func TestGlobalLoggingMethods(t *testing.T) {
 
 func TestGlobalFormattedLoggingMethods(t *testing.T) {
 	var buf bytes.Buffer
-	originalOut := std.out
+	originalOut := std.config().out
 	std.SetOutput(&buf)
 	std.SetLevel(TraceLevel)
 	defer func() {
-		std.out = originalOut
+		std.SetOutput(originalOut)
 	}()
 
 	// 测试全局格式化日志方法
@@ -238,11 +238,11 @@ func TestGlobalFormattedLoggingMethods(t *testing.T) {
 
 func TestGlobalPanicMethods(t *testing.T) {
 	var buf bytes.Buffer
-	originalOut := std.out
+	originalOut := std.config().out
 	std.SetOutput(&buf)
 	std.SetLevel(TraceLevel)
 	defer func() {
-		std.out = originalOut
+		std.SetOutput(originalOut)
 	}()
 
 	t.Run("GlobalPanic", func(t *testing.T) {
@@ -261,11 +261,11 @@ func TestGlobalPanicMethods(t *testing.T) {
 
 func TestGlobalPanicf(t *testing.T) {
 	var buf bytes.Buffer
-	originalOut := std.out
+	originalOut := std.config().out
 	std.SetOutput(&buf)
 	std.SetLevel(TraceLevel)
 	defer func() {
-		std.out = originalOut
+		std.SetOutput(originalOut)
 	}()
 
 	t.Run("GlobalPanicf", func(t *testing.T) {
@@ -392,11 +392,11 @@ func TestLogger_FatalMethod(t *testing.T) {
 
 func TestGlobalStartMsg(t *testing.T) {
 	var buf bytes.Buffer
-	originalOut := std.out
+	originalOut := std.config().out
 	std.SetOutput(&buf)
 	std.SetLevel(TraceLevel)
 	defer func() {
-		std.out = originalOut
+		std.SetOutput(originalOut)
 	}()
 
 	// 测试全局 StartMsg
@@ -431,7 +431,7 @@ func TestLogger_ParsingAndEscaping_NotFormatFull(t *testing.T) {
 	logger := newLogger()
 
 	// 设置一个不实现 FormatFull 的格式化器
-	logger.Format = &SimpleFormat{}
+	logger.SetFormatter(&SimpleFormat{})
 
 	// 这应该会 panic，因为格式化器不是 FormatFull 类型
 	defer func() {
@@ -447,7 +447,7 @@ func TestLogger_Caller_NotFormatFull(t *testing.T) {
 	logger := newLogger()
 
 	// 设置一个不实现 FormatFull 的格式化器
-	logger.Format = &SimpleFormat{}
+	logger.SetFormatter(&SimpleFormat{})
 
 	// 这应该会 panic
 	defer func() {
@@ -484,8 +484,8 @@ func TestLogger_Write_Method(t *testing.T) {
 	}
 
 	// 这将测试 write 方法的实现
-	formatted := logger.Format.Format(entry)
-	logger.write(entry.Level, formatted)
+	formatted := logger.Formatter().Format(entry)
+	logger.config().write(entry.Level, formatted)
 
 	output := buf.String()
 	if !strings.Contains(output, "test write method") {
@@ -537,7 +537,7 @@ func TestLogger_SetOutputNil_Coverage(t *testing.T) {
 	}
 
 	// out应该被设置为nil
-	if logger.out != nil {
+	if logger.config().out != nil {
 		t.Error("Output should be nil when no writers provided")
 	}
 }
@@ -554,7 +554,7 @@ func TestLogger_SetOutputEmpty_Coverage(t *testing.T) {
 	}
 
 	// out应该被设置为nil
-	if logger.out != nil {
+	if logger.config().out != nil {
 		t.Error("Output should be nil when no writers provided")
 	}
 }
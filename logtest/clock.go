@@ -0,0 +1,48 @@
+// Package logtest provides helpers for testing code that logs.
+package logtest
+
+import (
+	"sync"
+	"time"
+)
+
+// FakeClock is a clock that only moves when told to.
+// It implements log.Clock and is safe for concurrent use.
+//
+//	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 59, 59, 0, time.UTC))
+//	logger.SetClock(clock)
+//	clock.Add(time.Second)
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock creates a FakeClock reading now
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+// Now returns the current time of the clock
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.now
+}
+
+// Set moves the clock to now
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = now
+}
+
+// Add moves the clock forward by d and returns the new time
+func (c *FakeClock) Add(d time.Duration) time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.now = c.now.Add(d)
+	return c.now
+}
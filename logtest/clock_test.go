@@ -0,0 +1,23 @@
+package logtest
+
+import (
+	"testing"
+	"time"
+)
+
+func TestFakeClock(t *testing.T) {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	clock := NewFakeClock(start)
+
+	if !clock.Now().Equal(start) {
+		t.Errorf("Now = %v", clock.Now())
+	}
+	if got := clock.Add(90 * time.Minute); !got.Equal(start.Add(90*time.Minute)) || !clock.Now().Equal(got) {
+		t.Errorf("Add = %v, Now = %v", got, clock.Now())
+	}
+
+	clock.Set(start)
+	if !clock.Now().Equal(start) {
+		t.Errorf("Set did not move the clock, Now = %v", clock.Now())
+	}
+}
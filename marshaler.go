@@ -0,0 +1,93 @@
+package log
+
+import (
+	"time"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// ObjectMarshaler is implemented by types choosing which of their fields
+// get logged. JSONFormatter renders them as nested objects and Formatter as
+// key.sub=value pairs, without reflection:
+//
+//	func (o *Order) MarshalLogObject(enc log.ObjectEncoder) error {
+//		enc.AddString("id", o.ID)
+//		enc.AddFloat64("total", o.Total)
+//		return enc.AddObject("customer", o.Customer)
+//	}
+//
+// A marshaler returning an error is logged as keyError=message instead.
+type ObjectMarshaler interface {
+	MarshalLogObject(enc ObjectEncoder) error
+}
+
+// ArrayMarshaler is implemented by collections logging their elements
+type ArrayMarshaler interface {
+	MarshalLogArray(enc ArrayEncoder) error
+}
+
+// ObjectMarshalerFunc adapts a function to ObjectMarshaler
+type ObjectMarshalerFunc func(enc ObjectEncoder) error
+
+// MarshalLogObject implements ObjectMarshaler
+func (f ObjectMarshalerFunc) MarshalLogObject(enc ObjectEncoder) error {
+	return f(enc)
+}
+
+// ArrayMarshalerFunc adapts a function to ArrayMarshaler
+type ArrayMarshalerFunc func(enc ArrayEncoder) error
+
+// MarshalLogArray implements ArrayMarshaler
+func (f ArrayMarshalerFunc) MarshalLogArray(enc ArrayEncoder) error {
+	return f(enc)
+}
+
+// ObjectEncoder adds the members of an object, implemented by each formatter
+type ObjectEncoder interface {
+	AddString(key, val string)
+	AddInt(key string, val int)
+	AddInt64(key string, val int64)
+	AddFloat64(key string, val float64)
+	AddBool(key string, val bool)
+	AddDuration(key string, val time.Duration)
+	AddTime(key string, val time.Time)
+	AddObject(key string, obj ObjectMarshaler) error
+	AddArray(key string, arr ArrayMarshaler) error
+
+	// AddAny adds a value of any type, falling back to reflection for
+	// types the encoder doesn't know
+	AddAny(key string, val interface{}) error
+}
+
+// ArrayEncoder appends the elements of an array, implemented by each formatter
+type ArrayEncoder interface {
+	AppendString(val string)
+	AppendInt(val int)
+	AppendInt64(val int64)
+	AppendFloat64(val float64)
+	AppendBool(val bool)
+	AppendDuration(val time.Duration)
+	AppendTime(val time.Time)
+	AppendObject(obj ObjectMarshaler) error
+	AppendArray(arr ArrayMarshaler) error
+	AppendAny(val interface{}) error
+}
+
+// Object constructs a field logging obj through its MarshalLogObject method
+func Object(key string, obj ObjectMarshaler) Field {
+	return Field{Key: key, Type: constant.FieldTypeObject, Value: obj}
+}
+
+// Array constructs a field logging arr through its MarshalLogArray method
+func Array(key string, arr ArrayMarshaler) Field {
+	return Field{Key: key, Type: constant.FieldTypeArray, Value: arr}
+}
+
+// isMarshaler reports whether the value of field marshals itself
+func isMarshaler(field KV) bool {
+	switch field.Value.(type) {
+	case ObjectMarshaler, ArrayMarshaler:
+		return true
+	}
+	return false
+}
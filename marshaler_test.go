@@ -0,0 +1,189 @@
+package log
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type testCustomer struct {
+	Name     string
+	Password string // never logged
+}
+
+func (c *testCustomer) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("name", c.Name)
+	return nil
+}
+
+type testItem struct {
+	SKU string
+	Qty int
+}
+
+func (i testItem) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("sku", i.SKU)
+	enc.AddInt("qty", i.Qty)
+	return nil
+}
+
+type testItems []testItem
+
+func (items testItems) MarshalLogArray(enc ArrayEncoder) error {
+	for _, item := range items {
+		if err := enc.AppendObject(item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type testOrder struct {
+	ID       string
+	Total    float64
+	Paid     bool
+	Created  time.Time
+	Took     time.Duration
+	Customer *testCustomer
+	Items    testItems
+	Tags     []string
+}
+
+func (o *testOrder) MarshalLogObject(enc ObjectEncoder) error {
+	enc.AddString("id", o.ID)
+	enc.AddFloat64("total", o.Total)
+	enc.AddBool("paid", o.Paid)
+	enc.AddInt64("count", int64(len(o.Items)))
+	enc.AddTime("created", o.Created)
+	enc.AddDuration("took", o.Took)
+	if err := enc.AddObject("customer", o.Customer); err != nil {
+		return err
+	}
+	if err := enc.AddArray("items", o.Items); err != nil {
+		return err
+	}
+	return enc.AddArray("tags", ArrayMarshalerFunc(func(enc ArrayEncoder) error {
+		for _, tag := range o.Tags {
+			enc.AppendString(tag)
+		}
+		return nil
+	}))
+}
+
+func newTestOrder() *testOrder {
+	return &testOrder{
+		ID:       "o-1",
+		Total:    9.5,
+		Paid:     true,
+		Created:  time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Took:     2 * time.Second,
+		Customer: &testCustomer{Name: "bob", Password: "hunter2"},
+		Items:    testItems{{SKU: "a", Qty: 1}, {SKU: "b", Qty: 2}},
+		Tags:     []string{"new", "gift"},
+	}
+}
+
+func TestObjectMarshaler_JSON(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	logger.Infow("order", Object("order", newTestOrder()), "loose", newTestOrder().Customer)
+
+	want := `"fields":{"order":{"id":"o-1","total":9.5,"paid":true,"count":2,"created":"2024-01-02T03:04:05Z",` +
+		`"took":2000000000,"customer":{"name":"bob"},"items":[{"sku":"a","qty":1},{"sku":"b","qty":2}],` +
+		`"tags":["new","gift"]},"loose":{"name":"bob"}}`
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("JSON output %s should contain %s", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Error("fields left out by the marshaler must not be logged")
+	}
+	if !json.Valid(buf.Bytes()) {
+		t.Errorf("output should be valid JSON: %s", buf.String())
+	}
+}
+
+func TestObjectMarshaler_Text(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).EnableCaller(false).EnableTrace(false)
+
+	logger.Infow("order", Object("order", newTestOrder()), Any("items", testItems{{SKU: "c", Qty: 3}}), "done", true)
+
+	want := "order.id=o-1 order.total=9.5 order.paid=true order.count=2 order.created=2024-01-02T03:04:05Z " +
+		"order.took=2s order.customer.name=bob order.items.0.sku=a order.items.0.qty=1 order.items.1.sku=b " +
+		"order.items.1.qty=2 order.tags.0=new order.tags.1=gift items.0.sku=c items.0.qty=3 done=true"
+	if !strings.Contains(buf.String(), want) {
+		t.Errorf("text output %q should contain %q", buf.String(), want)
+	}
+	if strings.Contains(buf.String(), "hunter2") {
+		t.Error("fields left out by the marshaler must not be logged")
+	}
+}
+
+func TestObjectMarshaler_Error(t *testing.T) {
+	failing := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddString("partial", "x")
+		return errors.New("broken")
+	})
+
+	var jsonBuf, textBuf bytes.Buffer
+	New().SetOutput(&jsonBuf).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{}).
+		Infow("m", "a", 1, Object("obj", failing), "b", 2)
+	New().SetOutput(&textBuf).EnableCaller(false).EnableTrace(false).
+		Infow("m", "a", 1, Object("obj", failing), "b", 2)
+
+	if want := `"fields":{"a":1,"objError":"broken","b":2}`; !strings.Contains(jsonBuf.String(), want) {
+		t.Errorf("JSON output %s should contain %s", jsonBuf.String(), want)
+	}
+	if want := "a=1 objError=broken b=2"; !strings.Contains(textBuf.String(), want) {
+		t.Errorf("text output %q should contain %q", textBuf.String(), want)
+	}
+}
+
+func TestJSONEncoder_AddAnyUnsupported(t *testing.T) {
+	obj := ObjectMarshalerFunc(func(enc ObjectEncoder) error {
+		enc.AddString("a", "1")
+		if err := enc.AddAny("ch", make(chan int)); err == nil {
+			t.Error("AddAny should report values it can't encode")
+		}
+		return enc.AddAny("b", []int{2})
+	})
+
+	got, err := appendJSONObject(nil, obj)
+	if err != nil || string(got) != `{"a":"1","b":[2]}` {
+		t.Errorf("appendJSONObject = %s, %v", got, err)
+	}
+}
+
+func TestAny_Marshalers(t *testing.T) {
+	if f := Any("o", newTestOrder()); f.Type != Object("o", nil).Type {
+		t.Errorf("Any should recognise object marshalers, got type %d", f.Type)
+	}
+	if f := Any("a", testItems{}); f.Type != Array("a", nil).Type {
+		t.Errorf("Any should recognise array marshalers, got type %d", f.Type)
+	}
+}
+
+func BenchmarkObjectMarshaler_JSON(b *testing.B) {
+	f := &JSONFormatter{}
+	entry := &Entry{Level: InfoLevel, Message: "order", Fields: []KV{Object("order", newTestOrder())}}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = f.Format(entry)
+	}
+}
+
+func BenchmarkObjectMarshaler_JSONReflection(b *testing.B) {
+	f := &JSONFormatter{}
+	order := newTestOrder()
+	entry := &Entry{Level: InfoLevel, Message: "order", Fields: []KV{{Key: "order", Value: *order}}}
+
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = f.Format(entry)
+	}
+}
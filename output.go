@@ -12,20 +12,57 @@ func SetOutput(writes ...io.Writer) *Logger {
 	return std.SetOutput(writes...)
 }
 
-// GetOutputWriter creates a basic file log writer
+// GetOutputWriter creates a basic file log writer.
+// Calls for the same path share the file until it is closed.
 func GetOutputWriter(filename string) io.Writer {
+	rotatorMutex.Lock()
+	defer rotatorMutex.Unlock()
+
+	key := fileKey(filename)
+	if file, ok := fileInstances[key].(*os.File); ok {
+		if _, err := file.Stat(); err == nil {
+			return file
+		}
+	}
+
 	// Ensure log file directory exists
 	ensureDir(filepath.Dir(filename))
 
 	// Open file for writing
-	file, err := os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
+	file, err := openLogFile(filename)
 	if err != nil {
 		// Panic if creating log writer fails (critical functionality)
 		std.Panicf("failed to create log file writer %s: %v", filename, err)
 	}
+
+	fileInstances[key] = file
 	return file
 }
 
+// openLogFile opens filename for appending, creating it if needed
+func openLogFile(filename string) (*os.File, error) {
+	return os.OpenFile(filename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
+}
+
+// fileKey returns the registry key of filename, its absolute path
+func fileKey(filename string) string {
+	if abs, err := filepath.Abs(filename); err == nil {
+		return abs
+	}
+	return filename
+}
+
+// unregisterFile removes a closed output from fileInstances
+func unregisterFile(filename string, file syncCloser) {
+	rotatorMutex.Lock()
+	defer rotatorMutex.Unlock()
+
+	key := fileKey(filename)
+	if fileInstances[key] == file {
+		delete(fileInstances, key)
+	}
+}
+
 // ensureDir ensures the specified directory exists
 func ensureDir(dir string) {
 	// Create directory if not current dir and path doesn't exist as directory
@@ -56,10 +93,30 @@ type Output interface {
 	// rotatorInstances stores created rotator instances to avoid duplicates
 	rotatorInstances = make(map[string]*HourlyRotator)
 
-	// rotatorMutex protects concurrent access to rotatorInstances
+	// fileInstances stores files opened by GetOutputWriter and
+	// GetOutputWriterReopen by absolute path, closed by Shutdown
+	fileInstances = make(map[string]syncCloser)
+
+	// rotatorMutex protects concurrent access to rotatorInstances and fileInstances
 	rotatorMutex = &sync.Mutex{}
 )
 
+// syncCloser is an output file Shutdown syncs and closes
+type syncCloser interface {
+	Sync() error
+	Close() error
+}
+
+// unregisterRotator removes a closed rotator from rotatorInstances
+func unregisterRotator(r *Rotator) {
+	rotatorMutex.Lock()
+	defer rotatorMutex.Unlock()
+
+	if rotatorInstances[r.logDir] == r {
+		delete(rotatorInstances, r.logDir)
+	}
+}
+
 // GetOutputWriterHourly creates an hourly rotating log writer with auto-cleanup
 func GetOutputWriterHourly(logDir string) Writer {
 	rotatorMutex.Lock()
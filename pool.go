@@ -26,3 +26,9 @@ func PutBuffer(buf *bytes.Buffer) {
 	buf.Reset()
 	bufPool.Put(buf)
 }
+
+// bufferCopy returns a copy of the contents of buf, for buffers going back to
+// the pool once the caller returns
+func bufferCopy(buf *bytes.Buffer) []byte {
+	return bytes.Clone(buf.Bytes())
+}
@@ -0,0 +1,121 @@
+package log
+
+import "context"
+
+// The package level *Context functions log through FromContext(ctx),
+// falling back to the standard logger.
+
+// LogContext logs a message at the specified level with context.
+func LogContext(ctx context.Context, level Level, args ...interface{}) {
+	FromContext(ctx).LogContext(ctx, level, args...)
+}
+
+// LogfContext logs a formatted message at the specified level with context.
+func LogfContext(ctx context.Context, level Level, format string, args ...interface{}) {
+	FromContext(ctx).LogfContext(ctx, level, format, args...)
+}
+
+// TraceContext logs a message at Trace level with context.
+func TraceContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).TraceContext(ctx, args...)
+}
+
+// TracefContext logs a formatted message at Trace level with context.
+func TracefContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).TracefContext(ctx, format, args...)
+}
+
+// TracewContext logs a message with structured fields at Trace level with context.
+func TracewContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).TracewContext(ctx, msg, args...)
+}
+
+// DebugContext logs a message at Debug level with context.
+func DebugContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).DebugContext(ctx, args...)
+}
+
+// DebugfContext logs a formatted message at Debug level with context.
+func DebugfContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).DebugfContext(ctx, format, args...)
+}
+
+// DebugwContext logs a message with structured fields at Debug level with context.
+func DebugwContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).DebugwContext(ctx, msg, args...)
+}
+
+// InfoContext logs a message at Info level with context.
+func InfoContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).InfoContext(ctx, args...)
+}
+
+// InfofContext logs a formatted message at Info level with context.
+func InfofContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).InfofContext(ctx, format, args...)
+}
+
+// InfowContext logs a message with structured fields at Info level with context.
+func InfowContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).InfowContext(ctx, msg, args...)
+}
+
+// WarnContext logs a message at Warn level with context.
+func WarnContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).WarnContext(ctx, args...)
+}
+
+// WarnfContext logs a formatted message at Warn level with context.
+func WarnfContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).WarnfContext(ctx, format, args...)
+}
+
+// WarnwContext logs a message with structured fields at Warn level with context.
+func WarnwContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).WarnwContext(ctx, msg, args...)
+}
+
+// ErrorContext logs a message at Error level with context.
+func ErrorContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).ErrorContext(ctx, args...)
+}
+
+// ErrorfContext logs a formatted message at Error level with context.
+func ErrorfContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).ErrorfContext(ctx, format, args...)
+}
+
+// ErrorwContext logs a message with structured fields at Error level with context.
+func ErrorwContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).ErrorwContext(ctx, msg, args...)
+}
+
+// PanicContext logs a message at Panic level with context, then panics.
+func PanicContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).PanicContext(ctx, args...)
+}
+
+// PanicfContext logs a formatted message at Panic level with context, then panics.
+func PanicfContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).PanicfContext(ctx, format, args...)
+}
+
+// PanicwContext logs a message with structured fields at Panic level with context, then panics.
+func PanicwContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).PanicwContext(ctx, msg, args...)
+}
+
+// FatalContext logs a message at Fatal level with context, then calls os.Exit(1).
+func FatalContext(ctx context.Context, args ...interface{}) {
+	FromContext(ctx).FatalContext(ctx, args...)
+}
+
+// FatalfContext logs a formatted message at Fatal level with context, then calls os.Exit(1).
+func FatalfContext(ctx context.Context, format string, args ...interface{}) {
+	FromContext(ctx).FatalfContext(ctx, format, args...)
+}
+
+// FatalwContext logs a message with structured fields at Fatal level with context, then calls os.Exit(1).
+func FatalwContext(ctx context.Context, msg string, args ...interface{}) {
+	FromContext(ctx).FatalwContext(ctx, msg, args...)
+}
@@ -5,56 +5,174 @@
 	"os"
 	"path/filepath"
 	"sort"
-	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-var (
-	cleanupHourlyRotatorOnce sync.Once
-)
+// RotatorConfig configures a Rotator. The zero value rotates hourly with the
+// historical YYYYMMDDHH.log naming in the local timezone.
+type RotatorConfig struct {
+	// Policy decides when a new file is started, defaults to RotateHourly
+	Policy RotationPolicy
+
+	// Template names the files, unset fields take their defaults
+	Template FileTemplate
+
+	// Location is the timezone periods and file names are computed in,
+	// defaults to time.Local
+	Location *time.Location
+
+	// MaxSize splits a period into shards once a file reaches it, 0 disables sharding
+	MaxSize int64
+
+	// MaxFiles is the number of files kept by cleanup, 0 keeps everything
+	MaxFiles int
+
+	// MaxAge removes files whose period ended longer ago, 0 disables it
+	MaxAge time.Duration
+
+	// MaxTotalSize removes the oldest files once the files of the rotator
+	// take more space, 0 disables it
+	MaxTotalSize int64
+
+	// CleanupInterval is how often retention runs besides after each
+	// rotation, defaults to DefaultCleanupInterval
+	CleanupInterval time.Duration
+
+	// LinkName is the symlink pointing at the current file, defaults to current.log
+	LinkName string
+
+	// Compression compresses closed files in the background, nil keeps them as is
+	Compression Codec
 
-// HourlyRotator implements hourly log rotation with size-based sharding support
-type HourlyRotator struct {
-	mu           sync.Mutex
-	logDir       string
-	linkName     string
+	// Sync decides when files are synced to disk, defaults to SyncOnRotate
+	Sync SyncPolicy
+
+	// Clock decides rotation and retention, defaults to SystemClock
+	Clock Clock
+
+	// PreOpen opens the next period's file in the background this long
+	// before the boundary, defaults to DefaultPreOpen, negative disables it
+	PreOpen time.Duration
+}
+
+// Rotator is a log writer starting a new file every period of its policy,
+// with size-based sharding within a period
+type Rotator struct {
+	mu       sync.Mutex
+	logDir   string
+	linkName string
+	policy   RotationPolicy
+	template FileTemplate
+	location *time.Location
+	maxSize  int64 // Maximum file size in bytes
+
+	// Retention limits, guarded by mu
+	maxFiles     int // Maximum number of files to keep
+	maxAge       time.Duration
+	maxTotalSize int64
+
+	cleanupInterval time.Duration
+	cleanupOnce     sync.Once
+	cleanupKick     chan struct{}
+	cleanupStop     sync.Once
+	cleanupDone     chan struct{}
+
+	codec      atomic.Pointer[Codec]
+	compressMu sync.Mutex
+
+	// background tracks closing, compressing and pre-opening files
+	background sync.WaitGroup
+	done       chan struct{}
+
+	syncPolicy SyncPolicy
+	syncLoop   bool
+	unsynced   int64 // Bytes written since the last sync
+
+	preOpen      time.Duration
+	preOpenTimer *time.Timer
+	nextFile     *os.File // Pre-opened file of the next period
+	nextName     string
+
+	clock Clock
+
+	closed       bool
 	currentFile  *os.File
-	currentHour  string
+	currentName  string
+	periodStart  time.Time
+	periodEnd    time.Time
 	currentShard int   // Current shard number (0 = no shard, 1+ = sharded)
 	currentSize  int64 // Tracked file size to avoid Stat() calls
-	maxSize      int64 // Maximum file size in bytes
-	maxFiles     int   // Maximum number of files to keep
 }
 
-// NewHourlyRotator creates a new hourly rotating log writer
-func NewHourlyRotator(logDir string, maxSize int64, maxFiles int) *HourlyRotator {
-	r := &HourlyRotator{
+// HourlyRotator is a Rotator using the hourly policy
+type HourlyRotator = Rotator
+
+// NewRotator creates a rotating log writer in logDir
+func NewRotator(logDir string, config RotatorConfig) *Rotator {
+	if config.Policy == nil {
+		config.Policy = RotateHourly
+	}
+	if config.Location == nil {
+		config.Location = time.Local
+	}
+	if config.LinkName == "" {
+		config.LinkName = "current.log"
+	}
+	if config.CleanupInterval <= 0 {
+		config.CleanupInterval = DefaultCleanupInterval
+	}
+	if config.Clock == nil {
+		config.Clock = SystemClock
+	}
+	if config.PreOpen == 0 {
+		config.PreOpen = DefaultPreOpen
+	}
+
+	r := &Rotator{
 		logDir:   logDir,
-		linkName: filepath.Join(logDir, "current.log"),
-		maxSize:  maxSize,
-		maxFiles: maxFiles,
+		linkName: filepath.Join(logDir, config.LinkName),
+		policy:   config.Policy,
+		template: config.Template.withDefaults(config.Policy),
+		location: config.Location,
+		maxSize:  config.MaxSize,
+		maxFiles: config.MaxFiles,
+
+		maxAge:          config.MaxAge,
+		maxTotalSize:    config.MaxTotalSize,
+		cleanupInterval: config.CleanupInterval,
+		cleanupKick:     make(chan struct{}, 1),
+		cleanupDone:     make(chan struct{}),
+
+		done:       make(chan struct{}),
+		syncPolicy: config.Sync,
+		preOpen:    config.PreOpen,
+		clock:      config.Clock,
 	}
+	r.SetCompression(config.Compression)
 
-	cleanupHourlyRotatorOnce.Do(func() {
-		go func() {
-			ticker := time.NewTicker(10 * time.Minute)
-			defer ticker.Stop()
+	return r
+}
 
-			for _, rotator := range rotatorInstances {
-				rotator.cleanupOldFiles()
-			}
-		}()
+// NewHourlyRotator creates a new hourly rotating log writer
+func NewHourlyRotator(logDir string, maxSize int64, maxFiles int) *HourlyRotator {
+	return NewRotator(logDir, RotatorConfig{
+		Policy:   RotateHourly,
+		MaxSize:  maxSize,
+		MaxFiles: maxFiles,
 	})
-
-	return r
 }
 
 // Write implements io.Writer interface
-func (r *HourlyRotator) Write(p []byte) (n int, err error) {
+func (r *Rotator) Write(p []byte) (n int, err error) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
+	if r.closed {
+		return 0, ErrClosed
+	}
+
 	if err := r.rotate(); err != nil {
 		return 0, err
 	}
@@ -65,77 +183,102 @@ func (r *HourlyRotator) Write(p []byte) (n int, err error) {
 
 	n, err = r.currentFile.Write(p)
 	r.currentSize += int64(n)
+	r.unsynced += int64(n)
+	if r.syncPolicy.mode == syncEveryBytes && r.unsynced >= r.syncPolicy.bytes {
+		r.syncLocked()
+	}
 	return n, err
 }
 
 // rotate checks if rotation is needed and performs it
-func (r *HourlyRotator) rotate() error {
-	now := time.Now()
-	hour := now.Format("2006010215")
-
-	// Check if rotation needed
-	needRotate := r.currentHour != hour
-	if needRotate {
-		// Hour changed, reset shard and do normal rotation
+func (r *Rotator) rotate() error {
+	now := r.clock.Now().In(r.location)
+
+	// Period changed (or first write), reset shard and start a new file
+	if r.currentFile == nil || !now.Before(r.periodEnd) || now.Before(r.periodStart) {
 		r.currentShard = 0
-		return r.doRotate(hour, false)
+		return r.doRotate(r.policy.Start(now))
 	}
 
-	// Check size-based rotation within same hour
-	if r.currentFile != nil && r.currentSize >= r.maxSize {
-		// Size limit exceeded, create new shard within same hour
+	// Check size-based rotation within the same period
+	if r.maxSize > 0 && r.currentSize >= r.maxSize {
+		// Size limit exceeded, create new shard within same period
 		r.currentShard++
-		return r.doRotate(hour, true)
-	}
-
-	// No rotation needed
-	if r.currentFile == nil {
-		return r.doRotate(hour, false)
+		return r.doRotate(r.periodStart)
 	}
 
 	return nil
 }
 
-// doRotate performs actual rotation operation
-// shard indicates whether this is a size-based shard (true) or hour-based rotation (false)
-func (r *HourlyRotator) doRotate(hour string, shard bool) error {
-	// Close current file
-	if r.currentFile != nil {
-		_ = r.currentFile.Close()
-	}
-
+// doRotate switches to the file for start and the current shard.
+// The previous file is synced and closed in the background.
+func (r *Rotator) doRotate(start time.Time) error {
 	// Ensure directory exists
 	ensureDir(r.logDir)
 
-	// Generate filename: YYYYMMDDHH.log or YYYYMMDDHH.log.N for shards
-	var newFilename string
-	if shard {
-		newFilename = filepath.Join(r.logDir, fmt.Sprintf("%s.log.%d", hour, r.currentShard))
-	} else {
-		newFilename = filepath.Join(r.logDir, hour+".log")
-	}
+	name := r.template.Name(start, r.currentShard)
+	newFilename := filepath.Join(r.logDir, name)
 
-	// Open new file
-	file, err := os.OpenFile(newFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
-	if err != nil {
-		return err
+	// Use the pre-opened file when it is the one we need
+	file := r.takeNextFile(name)
+	if file == nil {
+		var err error
+		file, err = os.OpenFile(newFilename, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
+		if err != nil {
+			return err
+		}
 	}
 
+	old := r.currentFile
 	r.currentFile = file
-	r.currentHour = hour
+	r.currentName = name
+	r.periodStart = start
+	r.periodEnd = r.policy.Next(start)
 	r.currentSize = 0
 	if stat, err := file.Stat(); err == nil {
 		r.currentSize = stat.Size()
 	}
+	unsynced := r.unsynced
+	r.unsynced = 0
 
 	// Update soft link
 	r.updateLink(newFilename)
 
+	r.startSyncLoop()
+	r.schedulePreOpen()
+	r.afterRotate(old, unsynced > 0 && r.syncPolicy.mode != syncNever)
+
 	return nil
 }
 
+// afterRotate closes the previous file, compresses the closed files and
+// applies retention, in this order, off the write path.
+// Must be called with r.mu held.
+func (r *Rotator) afterRotate(old *os.File, sync bool) {
+	codec := r.compression()
+
+	r.background.Add(1)
+	go func() {
+		defer r.background.Done()
+
+		if old != nil {
+			if sync {
+				_ = old.Sync()
+			}
+			_ = old.Close()
+		} else {
+			// First file of the rotator, clear what an earlier process left
+			r.removeStaleTemp()
+		}
+		if codec != nil {
+			r.compressPending(codec)
+		}
+		r.triggerCleanup()
+	}()
+}
+
 // updateLink updates soft link pointing to the latest log file
-func (r *HourlyRotator) updateLink(target string) {
+func (r *Rotator) updateLink(target string) {
 	// Remove old link
 	_ = os.Remove(r.linkName)
 
@@ -143,92 +286,90 @@ func (r *HourlyRotator) updateLink(target string) {
 	_ = os.Symlink(filepath.Base(target), r.linkName)
 }
 
-// cleanupOldFiles cleans up expired log files
-func (r *HourlyRotator) cleanupOldFiles() {
-	dir := r.logDir
+// rotatedFile is a file of the rotator found in its directory
+type rotatedFile struct {
+	name       string
+	start      time.Time
+	shard      int
+	compressed bool
+}
 
-	// Read all files in directory
-	files, err := os.ReadDir(dir)
+// listFiles returns the files in the log directory matching the template, newest first
+func (r *Rotator) listFiles() ([]rotatedFile, error) {
+	entries, err := os.ReadDir(r.logDir)
 	if err != nil {
-		// 找不到的情况，没有必要输出信息
-		if os.IsNotExist(err) {
-			return
-		}
-
-		fmt.Printf("Error reading log directory %s for cleanup: %v\n", dir, err)
-		return
-	}
-
-	// Filter log files: base.log, base.log.1, base.log.2, etc.
-	var logFiles []string
-	for _, file := range files {
-		if !file.IsDir() {
-			name := file.Name()
-			// Match format: YYYYMMDDHH.log or YYYYMMDDHH.log.N
-			if strings.HasSuffix(name, ".log") {
-				// Extract base timestamp (remove .log or .log.N suffix)
-				base := strings.TrimSuffix(name, ".log")
-				// Handle shard suffix: check if base ends with .N pattern
-				var timestamp string
-				if idx := strings.LastIndex(base, "."); idx > 0 {
-					suffix := base[idx+1:]
-					if isAllDigits(suffix) {
-						timestamp = base[:idx]
-					} else {
-						timestamp = base
-					}
-				} else {
-					timestamp = base
-				}
-
-				// Verify timestamp is valid (10 digits: YYYYMMDDHH)
-				if len(timestamp) == 10 && isAllDigits(timestamp) {
-					logFiles = append(logFiles, name)
-				}
-			}
-		}
+		return nil, err
 	}
 
-	// Sort files by name descending (newest first)
-	sort.Slice(logFiles, func(i, j int) bool {
-		return logFiles[i] > logFiles[j]
-	})
-
-	// Delete old files exceeding retention count
-	for i, filename := range logFiles {
-		if i < r.maxFiles {
+	var files []rotatedFile
+	for _, entry := range entries {
+		if entry.IsDir() {
 			continue
 		}
-
-		err = os.Remove(filepath.Join(dir, filename))
-		if err != nil {
-			fmt.Printf("Failed to delete old log file %v\n", err)
+		base, compressed := r.trimCompressed(entry.Name())
+		if start, shard, ok := r.template.Parse(base, r.location); ok {
+			files = append(files, rotatedFile{name: entry.Name(), start: start, shard: shard, compressed: compressed})
 		}
 	}
+
+	sort.Slice(files, func(i, j int) bool {
+		if !files[i].start.Equal(files[j].start) {
+			return files[i].start.After(files[j].start)
+		}
+		return files[i].shard > files[j].shard
+	})
+	return files, nil
 }
 
 // Sync syncs current file content to disk
-func (r *HourlyRotator) Sync() error {
+func (r *Rotator) Sync() error {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	if r.currentFile != nil {
+		r.unsynced = 0
 		return r.currentFile.Sync()
 	}
 
 	return nil
 }
 
-// Close closes the rotator and cleans up resources
-func (r *HourlyRotator) Close() error {
+// Close closes the rotator, stops retention and waits for background work.
+// Later writes fail with ErrClosed; GetOutputWriterHourly creates a new rotator
+// for the directory.
+func (r *Rotator) Close() error {
+	unregisterRotator(r)
+
 	r.mu.Lock()
-	defer r.mu.Unlock()
+	if r.closed {
+		r.mu.Unlock()
+		return nil
+	}
+	r.closed = true
+	close(r.done)
+	if r.preOpenTimer != nil {
+		r.preOpenTimer.Stop()
+	}
+	r.discardNextFile()
 
+	var err error
 	if r.currentFile != nil {
-		return r.currentFile.Close()
+		if r.unsynced > 0 && r.syncPolicy.mode != syncNever {
+			_ = r.currentFile.Sync()
+		}
+		err = r.currentFile.Close()
+		r.currentFile = nil
 	}
+	r.mu.Unlock()
 
-	return nil
+	r.StopRetention()
+	r.waitBackground()
+	return err
+}
+
+// waitBackground waits for background closing, compression and pre-opening
+func (r *Rotator) waitBackground() {
+	r.background.Wait()
 }
 
 // isAllDigits checks if a string consists only of digits
@@ -0,0 +1,186 @@
+package log
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Codec compresses rotated log files.
+//
+// Gzip is built in; other formats plug in by implementing Codec, e.g. zstd
+// with github.com/klauspost/compress/zstd:
+//
+//	type zstdCodec struct{}
+//
+//	func (zstdCodec) Extension() string { return ".zst" }
+//
+//	func (zstdCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+//		return zstd.NewWriter(w)
+//	}
+type Codec interface {
+	// Extension is appended to compressed file names, e.g. ".gz"
+	Extension() string
+
+	// NewWriter returns a writer compressing into w, closing it flushes
+	// the compressed stream without closing w
+	NewWriter(w io.Writer) (io.WriteCloser, error)
+}
+
+// GzipCodec compresses files with gzip at the given level,
+// 0 meaning gzip.DefaultCompression
+type GzipCodec struct {
+	Level int
+}
+
+// Extension implements Codec
+func (GzipCodec) Extension() string { return ".gz" }
+
+// NewWriter implements Codec
+func (c GzipCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	level := c.Level
+	if level == 0 {
+		level = gzip.DefaultCompression
+	}
+	return gzip.NewWriterLevel(w, level)
+}
+
+// compressedExtensions returns the suffixes recognised on rotated files
+func (r *Rotator) compressedExtensions() []string {
+	if codec := r.compression(); codec != nil && codec.Extension() != ".gz" {
+		return []string{codec.Extension(), ".gz"}
+	}
+	return []string{".gz"}
+}
+
+// SetCompression compresses closed files with c in the background,
+// nil disables compression
+func (r *Rotator) SetCompression(c Codec) *Rotator {
+	if c == nil {
+		r.codec.Store(nil)
+	} else {
+		r.codec.Store(&c)
+	}
+	return r
+}
+
+// compression returns the codec of the rotator, nil when compression is off
+func (r *Rotator) compression() Codec {
+	if c := r.codec.Load(); c != nil {
+		return *c
+	}
+	return nil
+}
+
+// compressPending compresses every uncompressed file of the rotator except
+// the current one, the pre-opened next one and the target of the current link
+func (r *Rotator) compressPending(codec Codec) {
+	// One pass at a time, a file must not be compressed twice concurrently
+	r.compressMu.Lock()
+	defer r.compressMu.Unlock()
+
+	files, err := r.listFiles()
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	current, next := r.currentName, r.nextName
+	r.mu.Unlock()
+
+	linked, _ := os.Readlink(r.linkName)
+	for _, file := range files {
+		if file.compressed || file.name == current || file.name == next || file.name == linked {
+			continue
+		}
+
+		err = compressFile(codec, filepath.Join(r.logDir, file.name))
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Failed to compress log file %v\n", err)
+		}
+	}
+}
+
+// removeStaleTemp removes the temporary files of compressions interrupted by
+// the end of the process, which are never renamed into place
+func (r *Rotator) removeStaleTemp() {
+	// Wait for a compression in progress, its temporary file is not stale
+	r.compressMu.Lock()
+	defer r.compressMu.Unlock()
+
+	entries, err := os.ReadDir(r.logDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".tmp")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		base, compressed := r.trimCompressed(name)
+		if _, _, ok := r.template.Parse(base, r.location); !ok || !compressed {
+			continue
+		}
+		if err := os.Remove(filepath.Join(r.logDir, entry.Name())); err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Failed to remove stale log file %v\n", err)
+		}
+	}
+}
+
+// compressFile compresses name into name plus the codec extension, going
+// through a temporary file renamed into place, then removes name
+func compressFile(codec Codec, name string) (err error) {
+	src, err := os.Open(name) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst := name + codec.Extension()
+	tmp := dst + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600) // #nosec G304
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err != nil {
+			_ = out.Close()
+			_ = os.Remove(tmp)
+		}
+	}()
+
+	w, err := codec.NewWriter(out)
+	if err != nil {
+		return err
+	}
+	if _, err = io.Copy(w, src); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	if err = out.Sync(); err != nil {
+		return err
+	}
+	if err = out.Close(); err != nil {
+		return err
+	}
+	if err = os.Rename(tmp, dst); err != nil {
+		return err
+	}
+
+	return os.Remove(name)
+}
+
+// trimCompressed removes a compression extension from name
+func (r *Rotator) trimCompressed(name string) (string, bool) {
+	for _, ext := range r.compressedExtensions() {
+		if strings.HasSuffix(name, ext) {
+			return strings.TrimSuffix(name, ext), true
+		}
+	}
+	return name, false
+}
@@ -0,0 +1,136 @@
+package log
+
+import (
+	"compress/gzip"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/logtest"
+)
+
+func dirNames(t *testing.T, dir string) []string {
+	t.Helper()
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var names []string
+	for _, e := range entries {
+		names = append(names, e.Name())
+	}
+	sort.Strings(names)
+	return names
+}
+
+func TestRotator_CompressesClosedFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	old := filepath.Join(tmpDir, "2024010215.log")
+	if err := os.WriteFile(old, []byte("old line\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 16, 30, 0, 0, time.UTC))
+	rotator := NewRotator(tmpDir, RotatorConfig{MaxSize: 10, Compression: GzipCodec{}, Clock: clock, Location: time.UTC})
+	rotator.Write([]byte("0123456789\n"))
+	rotator.Write([]byte("next shard\n"))
+	rotator.waitBackground()
+	defer rotator.Close()
+
+	want := []string{"2024010215.log.gz", "2024010216.log.1", "2024010216.log.gz", "current.log"}
+	if got := dirNames(t, tmpDir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Fatalf("files = %v, want %v", got, want)
+	}
+
+	f, err := os.Open(old + ".gz")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	zr, err := gzip.NewReader(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := io.ReadAll(zr); string(data) != "old line\n" {
+		t.Errorf("decompressed %q", data)
+	}
+}
+
+func TestRotator_NeverCompressesLinkTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"2024010215.log", "2024010216.log"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Symlink("2024010216.log", filepath.Join(tmpDir, "current.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	rotator := NewRotator(tmpDir, RotatorConfig{Compression: GzipCodec{}})
+	rotator.compressPending(GzipCodec{})
+
+	got := dirNames(t, tmpDir)
+	if strings.Join(got, ",") != "2024010215.log.gz,2024010216.log,current.log" {
+		t.Errorf("files = %v", got)
+	}
+}
+
+// identityCodec stands in for an external codec such as zstd
+type identityCodec struct{}
+
+func (identityCodec) Extension() string { return ".zst" }
+
+func (identityCodec) NewWriter(w io.Writer) (io.WriteCloser, error) {
+	return nopWriteCloser{w}, nil
+}
+
+type nopWriteCloser struct{ io.Writer }
+
+func (nopWriteCloser) Close() error { return nil }
+
+func TestRotator_CustomCodecAndRetention(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"2024010212.log.gz", "2024010213.log.zst", "2024010214.log", "2024010215.log.gz.tmp"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rotator := NewRotator(tmpDir, RotatorConfig{MaxFiles: 2, Compression: identityCodec{}})
+	rotator.compressPending(identityCodec{})
+	if got := dirNames(t, tmpDir); strings.Join(got, ",") != "2024010212.log.gz,2024010213.log.zst,2024010214.log.zst,2024010215.log.gz.tmp" {
+		t.Fatalf("files = %v", got)
+	}
+
+	// Compressed files count in retention, temporary files are ignored
+	rotator.cleanupOldFiles()
+	if got := dirNames(t, tmpDir); strings.Join(got, ",") != "2024010213.log.zst,2024010214.log.zst,2024010215.log.gz.tmp" {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRotator_RemovesStaleTempFiles(t *testing.T) {
+	tmpDir := t.TempDir()
+	for _, name := range []string{"2024010214.log.gz.tmp", "2024010215.log.zst.tmp", "notes.tmp"} {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x\n"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 16, 30, 0, 0, time.UTC))
+	rotator := NewRotator(tmpDir, RotatorConfig{Compression: identityCodec{}, Clock: clock, Location: time.UTC, PreOpen: -1})
+	defer rotator.Close()
+	rotator.Write([]byte("line\n"))
+	rotator.waitBackground()
+
+	// Temporary files of other programs are left alone
+	want := []string{"2024010216.log", "current.log", "notes.tmp"}
+	if got := dirNames(t, tmpDir); strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("files = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// RotationPolicy decides which period a point in time belongs to.
+// A Rotator starts a new file each time the period changes.
+type RotationPolicy interface {
+	// Start returns the start of the period containing t, in t's location
+	Start(t time.Time) time.Time
+
+	// Next returns the start of the period following the one containing t
+	Next(t time.Time) time.Time
+
+	// Layout returns the default time layout used in file names
+	Layout() string
+}
+
+var (
+	// RotateMinutely starts a new file every minute
+	RotateMinutely RotationPolicy = RotateEvery(time.Minute)
+
+	// RotateHourly starts a new file every hour, named YYYYMMDDHH
+	RotateHourly RotationPolicy = RotateEvery(time.Hour)
+
+	// RotateDaily starts a new file at midnight, named YYYYMMDD
+	RotateDaily RotationPolicy = dailyPolicy{}
+
+	// RotateWeekly starts a new file at midnight on Monday, named after that day
+	RotateWeekly RotationPolicy = weeklyPolicy{}
+)
+
+// RotateEvery returns a policy starting a new file every d, with periods
+// aligned on the local wall clock (RotateEvery(6*time.Hour) rotates at
+// 00:00, 06:00, 12:00 and 18:00). Periods of a day or more should use
+// RotateDaily or RotateWeekly, which follow daylight saving changes.
+func RotateEvery(d time.Duration) RotationPolicy {
+	if d <= 0 {
+		d = time.Hour
+	}
+	return durationPolicy(d)
+}
+
+// durationPolicy rotates on multiples of a fixed duration of local time
+type durationPolicy time.Duration
+
+func (p durationPolicy) Start(t time.Time) time.Time {
+	// Truncate works on absolute time, shift by the zone offset so
+	// periods line up with the local clock
+	_, offset := t.Zone()
+	shift := time.Duration(offset) * time.Second
+	return t.Add(shift).Truncate(time.Duration(p)).Add(-shift)
+}
+
+func (p durationPolicy) Next(t time.Time) time.Time {
+	return p.Start(t).Add(time.Duration(p))
+}
+
+func (p durationPolicy) Layout() string {
+	switch d := time.Duration(p); {
+	case d%time.Hour == 0:
+		return "2006010215"
+	case d%time.Minute == 0:
+		return "200601021504"
+	default:
+		return "20060102150405"
+	}
+}
+
+func (p durationPolicy) String() string {
+	return "every " + time.Duration(p).String()
+}
+
+// dailyPolicy rotates at local midnight
+type dailyPolicy struct{}
+
+func (dailyPolicy) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+func (dailyPolicy) Next(t time.Time) time.Time {
+	y, m, d := t.Date()
+	return time.Date(y, m, d+1, 0, 0, 0, 0, t.Location())
+}
+
+func (dailyPolicy) Layout() string { return "20060102" }
+
+func (dailyPolicy) String() string { return "daily" }
+
+// weeklyPolicy rotates at local midnight on Mondays
+type weeklyPolicy struct{}
+
+func (weeklyPolicy) Start(t time.Time) time.Time {
+	y, m, d := t.Date()
+	// Days since Monday, Sunday counts as the 7th day
+	back := (int(t.Weekday()) + 6) % 7
+	return time.Date(y, m, d-back, 0, 0, 0, 0, t.Location())
+}
+
+func (p weeklyPolicy) Next(t time.Time) time.Time {
+	y, m, d := p.Start(t).Date()
+	return time.Date(y, m, d+7, 0, 0, 0, 0, t.Location())
+}
+
+func (weeklyPolicy) Layout() string { return "20060102" }
+
+func (weeklyPolicy) String() string { return "weekly" }
+
+// FileTemplate describes rotated file names:
+//
+//	<Prefix><time formatted with Layout><Extension>[<ShardSuffix with the shard number>]
+//
+// e.g. app-2024010215.log and app-2024010215.log.1 for the first size shard.
+type FileTemplate struct {
+	// Prefix is put before the timestamp, e.g. "app-"
+	Prefix string
+
+	// Layout formats the period start, defaults to the policy layout
+	Layout string
+
+	// Extension follows the timestamp, defaults to ".log"
+	Extension string
+
+	// ShardSuffix is a fmt format with a single %d for the shard number,
+	// appended when a period is split by size. Defaults to ".%d".
+	ShardSuffix string
+}
+
+// withDefaults fills unset fields from the policy and the historical naming
+func (t FileTemplate) withDefaults(policy RotationPolicy) FileTemplate {
+	if t.Layout == "" {
+		t.Layout = policy.Layout()
+	}
+	if t.Extension == "" {
+		t.Extension = ".log"
+	}
+	if t.ShardSuffix == "" || strings.Count(t.ShardSuffix, "%d") != 1 {
+		t.ShardSuffix = ".%d"
+	}
+	return t
+}
+
+// Name returns the file name for the period starting at start and the shard
+// number, 0 meaning the first, unsuffixed file
+func (t FileTemplate) Name(start time.Time, shard int) string {
+	name := t.Prefix + start.Format(t.Layout) + t.Extension
+	if shard > 0 {
+		name += fmt.Sprintf(t.ShardSuffix, shard)
+	}
+	return name
+}
+
+// Parse extracts the period start and shard number from a file name produced
+// by Name. ok is false for names that don't follow the template.
+func (t FileTemplate) Parse(name string, loc *time.Location) (start time.Time, shard int, ok bool) {
+	if !strings.HasPrefix(name, t.Prefix) {
+		return time.Time{}, 0, false
+	}
+	rest := name[len(t.Prefix):]
+
+	// The timestamp runs up to the extension
+	idx := strings.Index(rest, t.Extension)
+	if idx <= 0 {
+		return time.Time{}, 0, false
+	}
+	stamp, tail := rest[:idx], rest[idx+len(t.Extension):]
+
+	start, err := time.ParseInLocation(t.Layout, stamp, loc)
+	if err != nil || start.Format(t.Layout) != stamp {
+		return time.Time{}, 0, false
+	}
+
+	if tail == "" {
+		return start, 0, true
+	}
+	shard, ok = t.parseShard(tail)
+	return start, shard, ok
+}
+
+// parseShard parses a shard suffix such as ".3"
+func (t FileTemplate) parseShard(s string) (int, bool) {
+	i := strings.Index(t.ShardSuffix, "%d")
+	before, after := t.ShardSuffix[:i], t.ShardSuffix[i+2:]
+	if !strings.HasPrefix(s, before) || !strings.HasSuffix(s, after) || len(s) <= len(before)+len(after) {
+		return 0, false
+	}
+
+	digits := s[len(before) : len(s)-len(after)]
+	if !isAllDigits(digits) {
+		return 0, false
+	}
+
+	shard := 0
+	for _, c := range digits {
+		shard = shard*10 + int(c-'0')
+	}
+	return shard, shard > 0
+}
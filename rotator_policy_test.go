@@ -0,0 +1,173 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRotationPolicy_Boundaries(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	india := time.FixedZone("IST", 5*3600+1800)
+	at := func(loc *time.Location, s string) time.Time {
+		tm, err := time.ParseInLocation("2006-01-02 15:04:05", s, loc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	tests := []struct {
+		name   string
+		policy RotationPolicy
+		loc    *time.Location
+		now    string
+		start  string
+		next   string
+	}{
+		{"minutely", RotateMinutely, time.UTC, "2024-03-05 10:17:42", "2024-03-05 10:17:00", "2024-03-05 10:18:00"},
+		{"hourly", RotateHourly, shanghai, "2024-03-05 10:17:42", "2024-03-05 10:00:00", "2024-03-05 11:00:00"},
+		{"hourly half-hour zone", RotateHourly, india, "2024-03-05 10:17:42", "2024-03-05 10:00:00", "2024-03-05 11:00:00"},
+		{"six hours", RotateEvery(6 * time.Hour), shanghai, "2024-03-05 05:59:59", "2024-03-05 00:00:00", "2024-03-05 06:00:00"},
+		{"daily", RotateDaily, shanghai, "2024-03-05 23:59:59", "2024-03-05 00:00:00", "2024-03-06 00:00:00"},
+		{"daily month end", RotateDaily, time.UTC, "2024-02-29 12:00:00", "2024-02-29 00:00:00", "2024-03-01 00:00:00"},
+		{"weekly", RotateWeekly, time.UTC, "2024-03-07 08:00:00", "2024-03-04 00:00:00", "2024-03-11 00:00:00"},
+		{"weekly sunday", RotateWeekly, time.UTC, "2024-03-10 23:00:00", "2024-03-04 00:00:00", "2024-03-11 00:00:00"},
+		{"weekly monday", RotateWeekly, time.UTC, "2024-03-11 00:00:00", "2024-03-11 00:00:00", "2024-03-18 00:00:00"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := at(tt.loc, tt.now)
+			if got := tt.policy.Start(now); !got.Equal(at(tt.loc, tt.start)) {
+				t.Errorf("Start = %v, want %v", got, tt.start)
+			}
+			if got := tt.policy.Next(now); !got.Equal(at(tt.loc, tt.next)) {
+				t.Errorf("Next = %v, want %v", got, tt.next)
+			}
+		})
+	}
+}
+
+func TestRotationPolicy_DailyDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Skip("timezone database not available")
+	}
+
+	// 2024-03-10 is 23 hours long in New York
+	now := time.Date(2024, 3, 10, 12, 0, 0, 0, ny)
+	if got, want := RotateDaily.Next(now), time.Date(2024, 3, 11, 0, 0, 0, 0, ny); !got.Equal(want) {
+		t.Errorf("Next = %v, want %v", got, want)
+	}
+}
+
+func TestFileTemplate_NameParse(t *testing.T) {
+	start := time.Date(2024, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		template FileTemplate
+		shard    int
+		want     string
+	}{
+		{FileTemplate{}.withDefaults(RotateHourly), 0, "2024010215.log"},
+		{FileTemplate{}.withDefaults(RotateHourly), 3, "2024010215.log.3"},
+		{FileTemplate{Prefix: "app-"}.withDefaults(RotateDaily), 0, "app-20240102.log"},
+		{FileTemplate{Prefix: "api.", Layout: "2006-01-02T15", Extension: ".txt", ShardSuffix: "-part%d"}, 2, "api.2024-01-02T15.txt-part2"},
+	}
+	for _, tt := range tests {
+		name := tt.template.Name(start, tt.shard)
+		if name != tt.want {
+			t.Errorf("Name = %q, want %q", name, tt.want)
+		}
+
+		gotStart, gotShard, ok := tt.template.Parse(name, time.UTC)
+		if !ok || gotShard != tt.shard || gotStart.Format(tt.template.Layout) != start.Format(tt.template.Layout) {
+			t.Errorf("Parse(%q) = %v, %d, %v", name, gotStart, gotShard, ok)
+		}
+	}
+
+	tmpl := FileTemplate{Prefix: "app-"}.withDefaults(RotateHourly)
+	for _, name := range []string{
+		"2024010215.log",      // missing prefix
+		"app-2024013215.log",  // invalid day
+		"app-202401021.log",   // short timestamp
+		"app-2024010215.txt",  // other extension
+		"app-2024010215.log.", // empty shard
+		"app-2024010215.log.x",
+		"app-2024010215.log.0",
+		"current.log",
+	} {
+		if _, _, ok := tmpl.Parse(name, time.UTC); ok {
+			t.Errorf("Parse(%q) should not match", name)
+		}
+	}
+}
+
+func TestRotator_Template(t *testing.T) {
+	tmpDir := t.TempDir()
+	rotator := NewRotator(tmpDir, RotatorConfig{
+		Policy:   RotateDaily,
+		Template: FileTemplate{Prefix: "api-", Layout: "2006-01-02"},
+		Location: time.UTC,
+		MaxSize:  10,
+	})
+	defer rotator.Close()
+
+	for i := 0; i < 3; i++ {
+		if _, err := rotator.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	day := time.Now().UTC().Format("2006-01-02")
+	for _, name := range []string{"api-" + day + ".log", "api-" + day + ".log.1", "api-" + day + ".log.2"} {
+		if _, err := os.Stat(filepath.Join(tmpDir, name)); err != nil {
+			t.Errorf("expected %s: %v", name, err)
+		}
+	}
+
+	target, err := os.Readlink(filepath.Join(tmpDir, "current.log"))
+	if err != nil || target != "api-"+day+".log.2" {
+		t.Errorf("current.log -> %q, %v", target, err)
+	}
+}
+
+func TestRotator_CleanupUsesTemplate(t *testing.T) {
+	tmpDir := t.TempDir()
+	rotator := NewRotator(tmpDir, RotatorConfig{
+		Policy:   RotateDaily,
+		Template: FileTemplate{Prefix: "api-", Layout: "2006-01-02"},
+		Location: time.UTC,
+		MaxFiles: 3,
+	})
+
+	names := []string{
+		"api-2024-01-09.log", // sorts after the shards by name, but is older
+		"api-2024-01-10.log",
+		"api-2024-01-10.log.1",
+		"api-2024-01-10.log.2",
+		"api-2024-01-11.log",
+		"other-2024-01-01.log",
+		"api-2024-13-01.log",
+		"notes.txt",
+	}
+	for _, name := range names {
+		if err := os.WriteFile(filepath.Join(tmpDir, name), []byte("x"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	rotator.cleanupOldFiles()
+
+	entries, _ := os.ReadDir(tmpDir)
+	var left []string
+	for _, e := range entries {
+		left = append(left, e.Name())
+	}
+	want := "api-2024-01-10.log.1,api-2024-01-10.log.2,api-2024-01-11.log,api-2024-13-01.log,notes.txt,other-2024-01-01.log"
+	if got := strings.Join(left, ","); got != want {
+		t.Errorf("left %s\nwant %s", got, want)
+	}
+}
@@ -0,0 +1,111 @@
+package log
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// SetRetention sets the retention limits of the rotator: files whose period
+// ended more than maxAge ago, the oldest files beyond maxTotalSize bytes and
+// those beyond the newest maxFiles are removed. A zero limit is disabled.
+func (r *Rotator) SetRetention(maxAge time.Duration, maxTotalSize int64, maxFiles int) *Rotator {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.maxAge = maxAge
+	r.maxTotalSize = maxTotalSize
+	r.maxFiles = maxFiles
+	return r
+}
+
+// triggerCleanup asks the retention goroutine to run, starting it on first use
+func (r *Rotator) triggerCleanup() {
+	r.cleanupOnce.Do(r.startRetention)
+
+	select {
+	case r.cleanupKick <- struct{}{}:
+	default:
+		// A run is already pending
+	}
+}
+
+// startRetention runs cleanup every cleanupInterval and when kicked, until StopRetention
+func (r *Rotator) startRetention() {
+	go func() {
+		ticker := time.NewTicker(r.cleanupInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+			case <-r.cleanupKick:
+			case <-r.cleanupDone:
+				return
+			}
+			r.cleanupOldFiles()
+		}
+	}()
+}
+
+// StopRetention stops the background retention of the rotator.
+// Close stops it as well.
+func (r *Rotator) StopRetention() {
+	r.cleanupStop.Do(func() {
+		// Keep a later rotation from starting the goroutine again
+		r.cleanupOnce.Do(func() {})
+		close(r.cleanupDone)
+	})
+}
+
+// cleanupOldFiles removes the files beyond the retention limits, oldest first.
+// The current file and the target of the current link are always kept.
+func (r *Rotator) cleanupOldFiles() {
+	r.mu.Lock()
+	maxAge, maxTotalSize, maxFiles := r.maxAge, r.maxTotalSize, r.maxFiles
+	current := r.currentName
+	now := r.clock.Now()
+	r.mu.Unlock()
+
+	if maxAge <= 0 && maxTotalSize <= 0 && maxFiles <= 0 {
+		return
+	}
+
+	files, err := r.listFiles()
+	if err != nil {
+		// 找不到的情况，没有必要输出信息
+		if os.IsNotExist(err) {
+			return
+		}
+
+		fmt.Fprintf(os.Stderr, "Error reading log directory %s for cleanup: %v\n", r.logDir, err)
+		return
+	}
+
+	linked, _ := os.Readlink(r.linkName)
+
+	var total int64
+	for i, file := range files {
+		path := filepath.Join(r.logDir, file.name)
+		if info, err := os.Stat(path); err == nil {
+			total += info.Size()
+		}
+
+		if file.name == current || file.name == linked {
+			continue
+		}
+
+		expired := (maxFiles > 0 && i >= maxFiles) ||
+			(maxAge > 0 && now.Sub(r.policy.Next(file.start)) > maxAge) ||
+			(maxTotalSize > 0 && total > maxTotalSize)
+		if !expired {
+			continue
+		}
+
+		err = os.Remove(path)
+		if err != nil && !os.IsNotExist(err) {
+			fmt.Fprintf(os.Stderr, "Failed to delete old log file %v\n", err)
+		}
+	}
+}
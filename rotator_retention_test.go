@@ -0,0 +1,137 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/logtest"
+)
+
+// retentionClock reads 10:30 UTC, retention tests use UTC rotators
+func retentionClock() *logtest.FakeClock {
+	return logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC))
+}
+
+// writeHourlyFiles creates files for the hours ending at the one before now, oldest first
+func writeHourlyFiles(t *testing.T, dir string, now time.Time, hours int, size int) []string {
+	t.Helper()
+	last := now.Truncate(time.Hour).Add(-time.Hour)
+
+	names := make([]string, hours)
+	for i := 0; i < hours; i++ {
+		names[i] = last.Add(-time.Duration(hours-1-i)*time.Hour).Format("2006010215") + ".log"
+		if err := os.WriteFile(filepath.Join(dir, names[i]), []byte(strings.Repeat("x", size)), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return names
+}
+
+func TestRotator_RetentionLimits(t *testing.T) {
+	tests := []struct {
+		name         string
+		maxAge       time.Duration
+		maxTotalSize int64
+		maxFiles     int
+		kept         int
+	}{
+		{"max files", 0, 0, 4, 4},
+		{"max age", 4 * time.Hour, 0, 0, 4}, // the 4th newest hour ended 3h30 ago
+		{"max total size", 0, 350, 0, 3},
+		{"combined", 5 * time.Hour, 250, 4, 2},
+		{"disabled", 0, 0, 0, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			clock := retentionClock()
+			names := writeHourlyFiles(t, tmpDir, clock.Now(), 10, 100)
+
+			rotator := NewRotator(tmpDir, RotatorConfig{
+				Clock:        clock,
+				Location:     time.UTC,
+				MaxAge:       tt.maxAge,
+				MaxTotalSize: tt.maxTotalSize,
+				MaxFiles:     tt.maxFiles,
+			})
+			rotator.cleanupOldFiles()
+
+			got := dirNames(t, tmpDir)
+			if strings.Join(got, ",") != strings.Join(names[len(names)-tt.kept:], ",") {
+				t.Errorf("kept %v, want the newest %d", got, tt.kept)
+			}
+		})
+	}
+}
+
+func TestRotator_RetentionKeepsCurrent(t *testing.T) {
+	tmpDir := t.TempDir()
+	clock := retentionClock()
+	names := writeHourlyFiles(t, tmpDir, clock.Now(), 3, 100)
+	if err := os.Symlink(names[0], filepath.Join(tmpDir, "current.log")); err != nil {
+		t.Fatal(err)
+	}
+
+	rotator := NewRotator(tmpDir, RotatorConfig{MaxAge: time.Nanosecond, Clock: clock, Location: time.UTC})
+	rotator.cleanupOldFiles()
+
+	if got := dirNames(t, tmpDir); strings.Join(got, ",") != names[0]+",current.log" {
+		t.Errorf("files = %v", got)
+	}
+}
+
+func TestRotator_RetentionAfterRotation(t *testing.T) {
+	tmpDir := t.TempDir()
+	clock := retentionClock()
+	writeHourlyFiles(t, tmpDir, clock.Now(), 5, 10)
+
+	rotator := NewRotator(tmpDir, RotatorConfig{MaxFiles: 2, Clock: clock, Location: time.UTC})
+	defer rotator.Close()
+	rotator.Write([]byte("line\n"))
+
+	// The current file and the newest old one
+	deadline := time.Now().Add(2 * time.Second)
+	for len(dirNames(t, tmpDir)) != 3 {
+		if time.Now().After(deadline) {
+			t.Fatalf("retention did not run after rotation, files = %v", dirNames(t, tmpDir))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRotator_RetentionPeriodic(t *testing.T) {
+	tmpDir := t.TempDir()
+	clock := retentionClock()
+	rotator := NewRotator(tmpDir, RotatorConfig{MaxFiles: 1, CleanupInterval: 10 * time.Millisecond, Clock: clock, Location: time.UTC})
+	defer rotator.Close()
+	rotator.Write([]byte("line\n"))
+
+	// Files appearing later are removed by the ticker
+	writeHourlyFiles(t, tmpDir, clock.Now(), 3, 10)
+	deadline := time.Now().Add(2 * time.Second)
+	for len(dirNames(t, tmpDir)) != 2 {
+		if time.Now().After(deadline) {
+			t.Fatalf("periodic retention did not run, files = %v", dirNames(t, tmpDir))
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestRotator_StopRetention(t *testing.T) {
+	tmpDir := t.TempDir()
+	clock := retentionClock()
+	rotator := NewRotator(tmpDir, RotatorConfig{CleanupInterval: time.Millisecond, Clock: clock, Location: time.UTC}).SetRetention(0, 0, 1)
+	rotator.StopRetention()
+	rotator.StopRetention()
+
+	writeHourlyFiles(t, tmpDir, clock.Now(), 3, 10)
+	rotator.Write([]byte("line\n"))
+	time.Sleep(20 * time.Millisecond)
+	if got := dirNames(t, tmpDir); len(got) != 5 {
+		t.Errorf("retention should be stopped, files = %v", got)
+	}
+	rotator.Close()
+}
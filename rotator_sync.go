@@ -0,0 +1,172 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// syncMode selects when a SyncPolicy syncs
+type syncMode int
+
+const (
+	syncOnRotate syncMode = iota
+	syncNever
+	syncEveryBytes
+	syncEveryInterval
+)
+
+// SyncPolicy decides when a Rotator syncs its files to disk.
+// Every policy but SyncNever also syncs a file before it is closed.
+type SyncPolicy struct {
+	mode     syncMode
+	bytes    int64
+	interval time.Duration
+}
+
+var (
+	// SyncOnRotate syncs each file when it is closed, the default
+	SyncOnRotate = SyncPolicy{mode: syncOnRotate}
+
+	// SyncNever leaves syncing to the operating system
+	SyncNever = SyncPolicy{mode: syncNever}
+)
+
+// SyncEveryBytes syncs the current file once n bytes were written since the last sync
+func SyncEveryBytes(n int64) SyncPolicy {
+	if n <= 0 {
+		return SyncOnRotate
+	}
+	return SyncPolicy{mode: syncEveryBytes, bytes: n}
+}
+
+// SyncEvery syncs the current file every d in the background when it was written to
+func SyncEvery(d time.Duration) SyncPolicy {
+	if d <= 0 {
+		return SyncOnRotate
+	}
+	return SyncPolicy{mode: syncEveryInterval, interval: d}
+}
+
+// SetSyncPolicy sets when the rotator syncs its files
+func (r *Rotator) SetSyncPolicy(p SyncPolicy) *Rotator {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.syncPolicy = p
+	if r.currentFile != nil {
+		r.startSyncLoop()
+	}
+	return r
+}
+
+// syncLocked syncs the current file. Must be called with r.mu held.
+func (r *Rotator) syncLocked() {
+	if r.currentFile != nil && r.unsynced > 0 {
+		_ = r.currentFile.Sync()
+		r.unsynced = 0
+	}
+}
+
+// startSyncLoop syncs every interval of the sync policy until Close, unless
+// already running. Must be called with r.mu held.
+func (r *Rotator) startSyncLoop() {
+	if r.syncLoop || r.syncPolicy.mode != syncEveryInterval {
+		return
+	}
+	r.syncLoop = true
+
+	interval := r.syncPolicy.interval
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				r.mu.Lock()
+				if r.syncPolicy.mode == syncEveryInterval {
+					r.syncLocked()
+				}
+				r.mu.Unlock()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+}
+
+// schedulePreOpen arranges for the next period's file to be opened shortly
+// before the current period ends. Must be called with r.mu held.
+func (r *Rotator) schedulePreOpen() {
+	if r.preOpen < 0 || r.currentShard > 0 {
+		return
+	}
+	if r.preOpenTimer != nil {
+		r.preOpenTimer.Stop()
+	}
+
+	end := r.periodEnd
+	wait := end.Sub(r.clock.Now()) - r.preOpen
+	r.preOpenTimer = time.AfterFunc(max(wait, 0), func() {
+		r.preOpenNext(end)
+	})
+}
+
+// preOpenNext opens the file of the period starting at start, unless the
+// rotator moved on or already has it
+func (r *Rotator) preOpenNext(start time.Time) {
+	r.mu.Lock()
+	if r.closed || r.nextFile != nil || !r.periodEnd.Equal(start) {
+		r.mu.Unlock()
+		return
+	}
+	name := r.template.Name(start.In(r.location), 0)
+	r.mu.Unlock()
+
+	// Open outside the lock, writes carry on meanwhile
+	ensureDir(r.logDir)
+	file, err := os.OpenFile(filepath.Join(r.logDir, name), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304
+	if err != nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.closed || r.nextFile != nil || !r.periodEnd.Equal(start) {
+		closeUnused(file)
+		return
+	}
+	r.nextFile, r.nextName = file, name
+}
+
+// takeNextFile returns the pre-opened file if it is name, discarding it
+// otherwise. Must be called with r.mu held.
+func (r *Rotator) takeNextFile(name string) *os.File {
+	if r.nextFile == nil || r.nextName != name {
+		r.discardNextFile()
+		return nil
+	}
+
+	file := r.nextFile
+	r.nextFile, r.nextName = nil, ""
+	return file
+}
+
+// discardNextFile closes the pre-opened file. Must be called with r.mu held.
+func (r *Rotator) discardNextFile() {
+	if r.nextFile != nil {
+		closeUnused(r.nextFile)
+		r.nextFile, r.nextName = nil, ""
+	}
+}
+
+// closeUnused closes a pre-opened file that will not be written,
+// removing it again if it is still empty
+func closeUnused(file *os.File) {
+	info, err := file.Stat()
+	_ = file.Close()
+	if err == nil && info.Size() == 0 {
+		_ = os.Remove(file.Name())
+	}
+}
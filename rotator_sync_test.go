@@ -0,0 +1,148 @@
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/logtest"
+)
+
+// newClockedRotator returns a rotator in UTC reading the time from clock
+func newClockedRotator(t *testing.T, config RotatorConfig, clock *logtest.FakeClock) *Rotator {
+	t.Helper()
+	config.Location = time.UTC
+	config.Clock = clock
+	r := NewRotator(t.TempDir(), config)
+	t.Cleanup(func() { r.Close() })
+	return r
+}
+
+func (r *Rotator) lockedUnsynced() int64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.unsynced
+}
+
+func TestRotator_HourBoundary(t *testing.T) {
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 59, 59, 500e6, time.UTC))
+	r := newClockedRotator(t, RotatorConfig{PreOpen: time.Second}, clock)
+
+	r.Write([]byte("last line of 10\n"))
+	old := r.currentFile
+
+	// Less than PreOpen before the boundary, the next file is opened right away
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		r.mu.Lock()
+		next, name := r.nextFile, r.nextName
+		r.mu.Unlock()
+		if next != nil {
+			if name != "2024010211.log" {
+				t.Fatalf("pre-opened %q", name)
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("next file not pre-opened")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	r.mu.Lock()
+	preOpened := r.nextFile
+	r.mu.Unlock()
+
+	clock.Add(500 * time.Millisecond)
+	r.Write([]byte("first line of 11\n"))
+	if r.currentFile != preOpened || r.currentName != "2024010211.log" {
+		t.Errorf("rotation should use the pre-opened file, got %s", r.currentName)
+	}
+
+	// The previous file is closed in the background
+	r.waitBackground()
+	if _, err := old.Write([]byte("x")); err == nil {
+		t.Error("previous file should be closed")
+	}
+
+	for name, want := range map[string]string{
+		"2024010210.log": "last line of 10\n",
+		"2024010211.log": "first line of 11\n",
+	} {
+		data, err := os.ReadFile(filepath.Join(r.logDir, name))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v", name, data, err)
+		}
+	}
+}
+
+func TestRotator_StalePreOpenDiscarded(t *testing.T) {
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 30, 0, 0, time.UTC))
+	r := newClockedRotator(t, RotatorConfig{PreOpen: -1}, clock)
+	r.Write([]byte("a\n"))
+
+	r.preOpenNext(r.periodEnd)
+	if r.nextName != "2024010211.log" {
+		t.Fatalf("nextName = %q", r.nextName)
+	}
+
+	// The clock jumps past the next hour
+	clock.Add(2*time.Hour + 35*time.Minute)
+	r.Write([]byte("b\n"))
+	if r.currentName != "2024010213.log" || r.nextFile != nil {
+		t.Errorf("current %s, next %v", r.currentName, r.nextFile)
+	}
+	if _, err := os.Stat(filepath.Join(r.logDir, "2024010211.log")); !os.IsNotExist(err) {
+		t.Errorf("unused pre-opened file should be removed, stat err = %v", err)
+	}
+
+	// Pre-opening for a period the rotator left is ignored
+	r.preOpenNext(time.Date(2024, 1, 2, 11, 0, 0, 0, time.UTC))
+	if r.nextFile != nil {
+		t.Error("stale pre-open should be ignored")
+	}
+}
+
+func TestRotator_SyncPolicy(t *testing.T) {
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+
+	t.Run("every bytes", func(t *testing.T) {
+		r := newClockedRotator(t, RotatorConfig{Sync: SyncEveryBytes(10)}, clock)
+		r.Write([]byte("12345"))
+		if got := r.lockedUnsynced(); got != 5 {
+			t.Errorf("unsynced = %d", got)
+		}
+		r.Write([]byte("67890"))
+		if got := r.lockedUnsynced(); got != 0 {
+			t.Errorf("should sync after 10 bytes, unsynced = %d", got)
+		}
+	})
+
+	t.Run("never", func(t *testing.T) {
+		r := newClockedRotator(t, RotatorConfig{Sync: SyncNever}, clock)
+		r.Write([]byte("12345"))
+		r.Write([]byte("67890"))
+		if got := r.lockedUnsynced(); got != 10 {
+			t.Errorf("unsynced = %d", got)
+		}
+	})
+
+	t.Run("every interval", func(t *testing.T) {
+		r := newClockedRotator(t, RotatorConfig{}, clock).SetSyncPolicy(SyncEvery(5 * time.Millisecond))
+		r.Write([]byte("12345"))
+
+		deadline := time.Now().Add(2 * time.Second)
+		for r.lockedUnsynced() != 0 {
+			if time.Now().After(deadline) {
+				t.Fatal("interval sync did not run")
+			}
+			time.Sleep(time.Millisecond)
+		}
+	})
+
+	t.Run("defaults", func(t *testing.T) {
+		if SyncEveryBytes(0) != SyncOnRotate || SyncEvery(0) != SyncOnRotate || (SyncPolicy{}) != SyncOnRotate {
+			t.Error("invalid or zero policies should sync on rotate")
+		}
+	})
+}
@@ -7,14 +7,20 @@
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/lazygophers/log/logtest"
 )
 
 // Final comprehensive tests to reach 90% coverage
 
 func TestRotatorDetailedCoverage(t *testing.T) {
+	newRotator := func(t *testing.T, maxSize int64, maxFiles int) *Rotator {
+		clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+		return newClockedRotator(t, RotatorConfig{Policy: RotateHourly, MaxSize: maxSize, MaxFiles: maxFiles}, clock)
+	}
+
 	t.Run("NewHourlyRotator_basic_write", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 5)
+		rotator := newRotator(t, 100, 5)
 
 		rotator.Write([]byte("line 1\n"))
 		rotator.Write([]byte("line 2\n"))
@@ -23,40 +29,33 @@ func TestRotatorDetailedCoverage(t *testing.T) {
 		rotator.Close()
 
 		// Verify file was created
-		files, _ := os.ReadDir(tmpDir)
+		files, _ := os.ReadDir(rotator.logDir)
 		if len(files) == 0 {
 			t.Error("Should create log file")
 		}
 	})
 
 	t.Run("NewHourlyRotator_empty_write", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 5)
+		rotator := newRotator(t, 100, 5)
 
 		_, err := rotator.Write([]byte{})
 		if err != nil {
 			t.Errorf("Empty write failed: %v", err)
 		}
-
-		rotator.Close()
 	})
 
 	t.Run("NewHourlyRotator_large_write", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 1000, 10)
+		rotator := newRotator(t, 1000, 10)
 
 		largeData := strings.Repeat("test line\n", 1000)
 		_, err := rotator.Write([]byte(largeData))
 		if err != nil {
 			t.Errorf("Large write failed: %v", err)
 		}
-
-		rotator.Close()
 	})
 
 	t.Run("NewHourlyRotator_sync_operations", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 5)
+		rotator := newRotator(t, 100, 5)
 
 		rotator.Write([]byte("test\n"))
 
@@ -66,13 +65,10 @@ func TestRotatorDetailedCoverage(t *testing.T) {
 				t.Errorf("Sync %d failed: %v", i, err)
 			}
 		}
-
-		rotator.Close()
 	})
 
 	t.Run("NewHourlyRotator_close_operations", func(t *testing.T) {
-		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 5)
+		rotator := newRotator(t, 100, 5)
 
 		// Close without write
 		err := rotator.Close()
@@ -446,9 +442,11 @@ func TestIsAllDigits(t *testing.T) {
 }
 
 func TestCleanupOldFiles(t *testing.T) {
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 15, 30, 0, 0, time.Local))
+
 	t.Run("cleanupOldFiles_with_nonexistent_dir", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(filepath.Join(tmpDir, "nonexistent"), 100, 10)
+		rotator := NewHourlyRotator(filepath.Join(tmpDir, "nonexistent"), 100, 10).SetClock(clock)
 
 		// Should not panic
 		rotator.cleanupOldFiles()
@@ -457,7 +455,7 @@ func TestCleanupOldFiles(t *testing.T) {
 
 	t.Run("cleanupOldFiles_with_no_log_files", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 10)
+		rotator := NewHourlyRotator(tmpDir, 100, 10).SetClock(clock)
 
 		// Should not panic
 		rotator.cleanupOldFiles()
@@ -466,10 +464,10 @@ func TestCleanupOldFiles(t *testing.T) {
 
 	t.Run("cleanupOldFiles_with_valid_log_files", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 10)
+		rotator := NewHourlyRotator(tmpDir, 100, 10).SetClock(clock)
 
 		// Create some test log files with proper naming
-		timestamp := time.Now().Format("2006010215")
+		timestamp := clock.Now().Format("2006010215")
 		for i := 0; i < 5; i++ {
 			filename := filepath.Join(tmpDir, timestamp+".log."+fmt.Sprint(i))
 			_ = os.WriteFile(filename, []byte("test"), 0644)
@@ -482,10 +480,10 @@ func TestCleanupOldFiles(t *testing.T) {
 
 	t.Run("cleanupOldFiles_with_mixed_files", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 10)
+		rotator := NewHourlyRotator(tmpDir, 100, 10).SetClock(clock)
 
 		// Create mix of log files and non-log files
-		timestamp := time.Now().Format("2006010215")
+		timestamp := clock.Now().Format("2006010215")
 		_ = os.WriteFile(filepath.Join(tmpDir, timestamp+".log"), []byte("log1"), 0644)
 		_ = os.WriteFile(filepath.Join(tmpDir, "readme.txt"), []byte("readme"), 0644)
 		_ = os.WriteFile(filepath.Join(tmpDir, "config.json"), []byte("{}"), 0644)
@@ -497,7 +495,7 @@ func TestCleanupOldFiles(t *testing.T) {
 
 	t.Run("cleanupOldFiles_with_invalid_timestamp", func(t *testing.T) {
 		tmpDir := t.TempDir()
-		rotator := NewHourlyRotator(tmpDir, 100, 10)
+		rotator := NewHourlyRotator(tmpDir, 100, 10).SetClock(clock)
 
 		// Create log files with invalid timestamps
 		_ = os.WriteFile(filepath.Join(tmpDir, "123.log"), []byte("short"), 0644)
@@ -512,10 +510,10 @@ func TestCleanupOldFiles(t *testing.T) {
 	t.Run("cleanupOldFiles_deletes_old_files", func(t *testing.T) {
 		tmpDir := t.TempDir()
 		maxFiles := 3
-		rotator := NewHourlyRotator(tmpDir, 100, maxFiles)
+		rotator := NewHourlyRotator(tmpDir, 100, maxFiles).SetClock(clock)
 
 		// Create more log files than maxFiles with different timestamps
-		now := time.Now()
+		now := clock.Now()
 		for i := 0; i < 5; i++ {
 			timestamp := now.Add(time.Duration(i) * time.Hour).Format("2006010215")
 			filename := filepath.Join(tmpDir, timestamp+".log")
@@ -652,13 +650,20 @@ func TestRotatorFullCoverage(t *testing.T) {
 }
 
 func TestRotatorWriteBranch(t *testing.T) {
-	tmpDir := t.TempDir()
-	rotator := NewHourlyRotator(tmpDir, 50, 5) // Small size to trigger rotation
+	clock := logtest.NewFakeClock(time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC))
+	// Small size to trigger rotation
+	rotator := newClockedRotator(t, RotatorConfig{Policy: RotateHourly, MaxSize: 50, MaxFiles: 5}, clock)
 
 	// Write enough data to trigger rotation
 	for i := 0; i < 3; i++ {
-		rotator.Write([]byte("This is a long message to trigger rotation\n"))
+		if _, err := rotator.Write([]byte("This is a long message to trigger rotation\n")); err != nil {
+			t.Fatalf("Write %d failed: %v", i, err)
+		}
 	}
 
-	rotator.Close()
+	for _, name := range []string{"2024010210.log", "2024010210.log.1"} {
+		if _, err := os.Stat(filepath.Join(rotator.logDir, name)); err != nil {
+			t.Errorf("a write past MaxSize should start a shard: %v", err)
+		}
+	}
 }
@@ -0,0 +1,188 @@
+package log
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// samplerBuckets is the number of counters per level, messages are hashed onto them
+const samplerBuckets = 4096
+
+// Sampler limits how many identical entries are logged.
+//
+// Within each tick, the first N entries with a given level and message are
+// logged, then only every Mth one. Dropped entries are discarded before any
+// formatting takes place and counted per level. Ticks follow the clock of the
+// logger (see Logger.SetClock). A Sampler can be shared by several loggers.
+//
+//	logger.SetSampler(log.NewSampler(time.Second, 100, 100))
+type Sampler struct {
+	tick       time.Duration
+	first      uint64
+	thereafter uint64
+
+	counters [TraceLevel + 1][samplerBuckets]samplerCounter
+	dropped  [TraceLevel + 1]atomic.Uint64
+}
+
+// samplerCounter counts entries of one bucket within the current tick
+type samplerCounter struct {
+	resetAt atomic.Int64
+	count   atomic.Uint64
+}
+
+// NewSampler creates a Sampler logging, for each level and message, the first
+// entries within every tick and then every thereafter-th one.
+// A thereafter of 0 drops everything after the first entries.
+func NewSampler(tick time.Duration, first, thereafter int) *Sampler {
+	if first < 0 {
+		first = 0
+	}
+	if thereafter < 0 {
+		thereafter = 0
+	}
+	return &Sampler{
+		tick:       tick,
+		first:      uint64(first),
+		thereafter: uint64(thereafter),
+	}
+}
+
+// allow reports whether an entry logged at now, in Unix nanoseconds from the
+// logger's clock, should be logged, counting it as dropped otherwise.
+// Panic and fatal entries are never dropped, they must still panic or exit.
+func (s *Sampler) allow(level Level, msg string, now int64) bool {
+	if level <= FatalLevel || level > TraceLevel {
+		return true
+	}
+
+	counter := &s.counters[level][fnv32a(msg)%samplerBuckets]
+	n := counter.incCheckReset(now, s.tick)
+	if n <= s.first || (s.thereafter > 0 && (n-s.first)%s.thereafter == 0) {
+		return true
+	}
+
+	s.dropped[level].Add(1)
+	return false
+}
+
+// incCheckReset increments the counter, starting over when the tick has elapsed
+func (c *samplerCounter) incCheckReset(now int64, tick time.Duration) uint64 {
+	resetAt := c.resetAt.Load()
+	if resetAt > now {
+		return c.count.Add(1)
+	}
+
+	c.count.Store(1)
+	if !c.resetAt.CompareAndSwap(resetAt, now+tick.Nanoseconds()) {
+		// Another goroutine started the new tick
+		return c.count.Add(1)
+	}
+	return 1
+}
+
+// Dropped returns the number of entries dropped at level
+func (s *Sampler) Dropped(level Level) uint64 {
+	if level > TraceLevel {
+		return 0
+	}
+	return s.dropped[level].Load()
+}
+
+// DroppedTotal returns the number of entries dropped at all levels
+func (s *Sampler) DroppedTotal() uint64 {
+	var total uint64
+	for level := range s.dropped {
+		total += s.dropped[level].Load()
+	}
+	return total
+}
+
+// StartSummary logs a "N messages suppressed" warning to logger every interval
+// in which entries were dropped, with the per-level counts as fields.
+// The summary bypasses the logger's sampler and carries no caller.
+// Call the returned function to stop it.
+func (s *Sampler) StartSummary(logger *Logger, interval time.Duration) (stop func()) {
+	done := make(chan struct{})
+	ticker := time.NewTicker(interval)
+
+	var last [TraceLevel + 1]uint64
+	go func() {
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				s.logSummary(logger, &last)
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { close(done) })
+	}
+}
+
+// logSummary logs the entries dropped since last, then updates last
+func (s *Sampler) logSummary(logger *Logger, last *[TraceLevel + 1]uint64) {
+	var total uint64
+	fields := make([]interface{}, 0, 2*len(last))
+	for level := range last {
+		n := s.dropped[level].Load()
+		if delta := n - last[level]; delta > 0 {
+			total += delta
+			fields = append(fields, Level(level).String(), delta)
+		}
+		last[level] = n
+	}
+
+	if total == 0 {
+		return
+	}
+	c := logger.config()
+	if !c.level.Enabled(WarnLevel) {
+		return
+	}
+
+	// Built here rather than through Warnw, so the summary is never sampled
+	// away and carries no caller or trace of the ticker goroutine
+	entry := getEntry()
+	c.populateEntry(entry, WarnLevel, fmt.Sprintf("%d messages suppressed", total))
+	c.populateFields(entry, fields...)
+	c.fillPrefixSuffix(entry)
+
+	if c.async != nil && c.async.enqueue(c, entry, 0) {
+		return
+	}
+	c.emit(entry)
+}
+
+// fnv32a hashes s with 32-bit FNV-1a without allocating
+func fnv32a(s string) uint32 {
+	const (
+		offset32 = 2166136261
+		prime32  = 16777619
+	)
+	h := uint32(offset32)
+	for i := 0; i < len(s); i++ {
+		h ^= uint32(s[i])
+		h *= prime32
+	}
+	return h
+}
+
+// SetSampler attaches s to the logger, nil removes sampling
+func (p *Logger) SetSampler(s *Sampler) *Logger {
+	return p.update(func(c *loggerConfig) {
+		c.sampler = s
+	})
+}
+
+// Sampler returns the sampler attached to the logger, if any
+func (p *Logger) Sampler() *Sampler {
+	return p.config().sampler
+}
@@ -0,0 +1,250 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lazygophers/log/constant"
+)
+
+func TestSampler_FirstThenEvery(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSampler(time.Hour, 3, 5)
+	logger := newTestLogger(&buf).SetSampler(s)
+
+	for i := 0; i < 23; i++ {
+		logger.Info("hot loop")
+	}
+
+	// 1, 2, 3, then 8, 13, 18, 23
+	if got := strings.Count(buf.String(), "hot loop"); got != 7 {
+		t.Errorf("expected 7 lines, got %d", got)
+	}
+	if s.Dropped(InfoLevel) != 16 || s.DroppedTotal() != 16 {
+		t.Errorf("expected 16 dropped, got %d (total %d)", s.Dropped(InfoLevel), s.DroppedTotal())
+	}
+	if logger.Sampler() != s {
+		t.Error("Sampler should return the attached sampler")
+	}
+}
+
+func TestSampler_KeyedByLevelAndMessage(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSampler(time.Hour, 1, 0)
+	logger := newTestLogger(&buf).SetSampler(s)
+
+	for i := 0; i < 3; i++ {
+		logger.Info("a")
+		logger.Info("b")
+		logger.Warn("a")
+		logger.Debugw("c", "i", i)
+	}
+
+	if got := strings.Count(buf.String(), "\n"); got != 4 {
+		t.Errorf("expected one line per level and message, got %d: %q", got, buf.String())
+	}
+	if s.Dropped(InfoLevel) != 4 || s.Dropped(WarnLevel) != 2 || s.Dropped(DebugLevel) != 2 {
+		t.Errorf("unexpected per-level counts: info=%d warn=%d debug=%d",
+			s.Dropped(InfoLevel), s.Dropped(WarnLevel), s.Dropped(DebugLevel))
+	}
+	if s.Dropped(Level(42)) != 0 {
+		t.Error("unknown levels have no counter")
+	}
+}
+
+func TestSampler_TickResets(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetSampler(NewSampler(20*time.Millisecond, 1, 0))
+
+	logger.Info("tick")
+	logger.Info("tick")
+	time.Sleep(30 * time.Millisecond)
+	logger.Info("tick")
+
+	if got := strings.Count(buf.String(), "tick"); got != 2 {
+		t.Errorf("expected a new allowance after the tick, got %d lines", got)
+	}
+}
+
+func TestSampler_SkipsFormattingAndHooks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetSampler(NewSampler(time.Hour, 1, 0))
+	f := &countingFormatCalls{}
+	logger.SetFormatter(f)
+
+	hookCalls := 0
+	logger.AddHook(constant.EntryHookFunc(func(e *Entry) (*Entry, bool) {
+		hookCalls++
+		return e, true
+	}))
+
+	for i := 0; i < 10; i++ {
+		logger.Info("same")
+	}
+	if f.calls != 1 || hookCalls != 1 {
+		t.Errorf("dropped entries should not reach hooks or the formatter, got %d formats, %d hooks", f.calls, hookCalls)
+	}
+}
+
+type countingFormatCalls struct {
+	Formatter
+	calls int
+}
+
+func (f *countingFormatCalls) Format(entry interface{}) []byte {
+	f.calls++
+	return f.Formatter.Format(entry)
+}
+
+func TestSampler_NeverDropsPanic(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetSampler(NewSampler(time.Hour, 0, 0))
+
+	for i := 0; i < 2; i++ {
+		func() {
+			defer func() {
+				if recover() == nil {
+					t.Error("panic entries must not be sampled")
+				}
+			}()
+			logger.Panic("boom")
+		}()
+	}
+}
+
+func TestSampler_ContextAndSlog(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSampler(time.Hour, 1, 0)
+	logger := newTestLogger(&buf).SetSampler(s)
+
+	for i := 0; i < 3; i++ {
+		logger.InfoContext(t.Context(), "ctx")
+		NewSlogLogger(logger).Info("slog")
+	}
+	if s.Dropped(InfoLevel) != 4 {
+		t.Errorf("expected 4 dropped, got %d", s.Dropped(InfoLevel))
+	}
+}
+
+func TestSampler_Summary(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSampler(time.Hour, 1, 0)
+	logger := newTestLogger(&buf).SetSampler(s)
+
+	for i := 0; i < 4; i++ {
+		logger.Info("noisy")
+		logger.Debug("noisy")
+	}
+
+	var last [TraceLevel + 1]uint64
+	s.logSummary(logger, &last)
+	if !strings.Contains(buf.String(), "6 messages suppressed info=3 debug=3") {
+		t.Errorf("unexpected summary %q", buf.String())
+	}
+
+	buf.Reset()
+	s.logSummary(logger, &last)
+	if buf.Len() != 0 {
+		t.Errorf("no summary expected without new drops, got %q", buf.String())
+	}
+}
+
+func TestSampler_SummaryWithoutCaller(t *testing.T) {
+	var buf bytes.Buffer
+	s := NewSampler(time.Hour, 1, 0)
+	logger := New().SetOutput(&buf).SetFormatter(&JSONFormatter{}).SetSampler(s)
+
+	logger.Info("noisy")
+	logger.Info("noisy")
+	buf.Reset()
+
+	var last [TraceLevel + 1]uint64
+	s.logSummary(logger, &last)
+	if got := buf.String(); !strings.Contains(got, `"message":"1 messages suppressed"`) || strings.Contains(got, "caller") {
+		t.Errorf("summary should be logged without caller, got %q", got)
+	}
+	if logger.Sampler() != s {
+		t.Error("summary must not change the logger's sampler")
+	}
+}
+
+func TestSampler_StartSummary(t *testing.T) {
+	out := &lockedBuffer{}
+	s := NewSampler(time.Hour, 1, 0)
+	logger := New().SetOutput(out).EnableCaller(false).SetSampler(s)
+
+	stop := s.StartSummary(logger, 10*time.Millisecond)
+	defer stop()
+
+	for round := 0; round < 2; round++ {
+		logger.Info("x")
+		logger.Info("x")
+		waitForOutput(t, out, round+1)
+	}
+	stop()
+}
+
+// waitForOutput waits until out holds n summary lines
+func waitForOutput(t *testing.T, out *lockedBuffer, n int) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for strings.Count(out.String(), " messages suppressed") < n {
+		if time.Now().After(deadline) {
+			t.Fatalf("summary not logged, got %q", out.String())
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}
+
+func TestSampler_Concurrent(t *testing.T) {
+	out := &lockedBuffer{}
+	s := NewSampler(time.Hour, 10, 0)
+	logger := New().SetOutput(out).SetSampler(s)
+
+	const goroutines, iterations = 8, 100
+	var wg sync.WaitGroup
+	for g := 0; g < goroutines; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < iterations; i++ {
+				logger.Info("shared")
+			}
+		}()
+	}
+	wg.Wait()
+
+	if got := strings.Count(out.String(), "shared"); got != 10 {
+		t.Errorf("expected 10 lines, got %d", got)
+	}
+	if s.Dropped(InfoLevel) != goroutines*iterations-10 {
+		t.Errorf("expected %d dropped, got %d", goroutines*iterations-10, s.Dropped(InfoLevel))
+	}
+}
+
+func BenchmarkSampler_Dropped(b *testing.B) {
+	logger := New().SetOutput(&bytes.Buffer{}).SetSampler(NewSampler(time.Hour, 1, 0))
+	logger.Info("bench")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		logger.Info("bench")
+	}
+}
+
+func ExampleSampler() {
+	logger := New().SetOutput(&bytes.Buffer{})
+	s := NewSampler(time.Second, 2, 0)
+	logger.SetSampler(s)
+
+	for i := 0; i < 5; i++ {
+		logger.Info("retrying")
+	}
+	fmt.Println(s.Dropped(InfoLevel))
+	// Output: 3
+}
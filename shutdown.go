@@ -0,0 +1,166 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+)
+
+// ErrClosed is returned by writes to an output closed by Close or Shutdown.
+// It is os.ErrClosed, so writes to files closed by Shutdown match it as well.
+var ErrClosed = os.ErrClosed
+
+var (
+	// asyncInstances stores the AsyncWriters that are not closed yet
+	asyncInstances = make(map[*AsyncWriter]struct{})
+
+	// emitterInstances stores the workers started by Logger.SetAsync that are not closed yet
+	emitterInstances = make(map[*asyncEmitter]struct{})
+
+	// deduperInstances stores the Dedupers whose background flusher is running
+	deduperInstances = make(map[*Deduper]struct{})
+
+	// asyncMutex protects concurrent access to asyncInstances,
+	// emitterInstances and deduperInstances
+	asyncMutex sync.Mutex
+)
+
+// registerAsyncWriter records p so Shutdown flushes it
+func registerAsyncWriter(p *AsyncWriter) {
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+
+	asyncInstances[p] = struct{}{}
+}
+
+// unregisterAsyncWriter forgets a closed AsyncWriter
+func unregisterAsyncWriter(p *AsyncWriter) {
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+
+	delete(asyncInstances, p)
+}
+
+// registerEmitter records a Logger.SetAsync worker so Shutdown drains it
+func registerEmitter(e *asyncEmitter) {
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+
+	emitterInstances[e] = struct{}{}
+}
+
+// unregisterEmitter forgets a closed worker
+func unregisterEmitter(e *asyncEmitter) {
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+
+	delete(emitterInstances, e)
+}
+
+// registerDeduper records a Deduper so Shutdown writes its collapsed lines and stops it
+func registerDeduper(d *Deduper) {
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+
+	deduperInstances[d] = struct{}{}
+}
+
+// unregisterDeduper forgets a closed Deduper
+func unregisterDeduper(d *Deduper) {
+	asyncMutex.Lock()
+	defer asyncMutex.Unlock()
+
+	delete(deduperInstances, d)
+}
+
+// Shutdown flushes and closes everything the package runs in the background
+// and every output it created: the standard logger is synced, the workers of
+// Logger.SetAsync write out their queues and stop, Dedupers write their
+// collapsed lines and stop, AsyncWriters write out their buffered data and
+// are closed, then the rotators from GetOutputWriterHourly and the files from
+// GetOutputWriter are synced and closed and removed from the registry.
+//
+// Loggers using a stopped worker log synchronously again. Later writes to
+// the closed outputs fail with ErrClosed. Shutdown returns
+// ctx.Err() if ctx is done first; closing carries on in the background.
+//
+//	defer log.Shutdown(context.Background())
+func Shutdown(ctx context.Context) error {
+	done := make(chan error, 1)
+	go func() {
+		done <- shutdown()
+	}()
+
+	select {
+	case err := <-done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// shutdown stops the registered workers and closes the registered outputs in
+// the order data flows: logger queues, then collapsed lines, then AsyncWriters
+// since they usually write into the others
+func shutdown() error {
+	var errs []error
+
+	// Write out entries held back by the standard logger
+	std.config().sync()
+
+	asyncMutex.Lock()
+	emitters := make([]*asyncEmitter, 0, len(emitterInstances))
+	for e := range emitterInstances {
+		emitters = append(emitters, e)
+	}
+	asyncMutex.Unlock()
+
+	for _, e := range emitters {
+		e.close()
+	}
+
+	// The queues are drained, collapsed lines are written synchronously
+	asyncMutex.Lock()
+	dedupers := make([]*Deduper, 0, len(deduperInstances))
+	for d := range deduperInstances {
+		dedupers = append(dedupers, d)
+	}
+	asyncMutex.Unlock()
+
+	for _, d := range dedupers {
+		errs = append(errs, d.Close())
+	}
+
+	asyncMutex.Lock()
+	asyncs := make([]*AsyncWriter, 0, len(asyncInstances))
+	for p := range asyncInstances {
+		asyncs = append(asyncs, p)
+	}
+	asyncMutex.Unlock()
+
+	for _, p := range asyncs {
+		errs = append(errs, p.Close())
+	}
+
+	rotatorMutex.Lock()
+	rotators := rotatorInstances
+	files := fileInstances
+	rotatorInstances = make(map[string]*HourlyRotator)
+	fileInstances = make(map[string]syncCloser)
+	rotatorMutex.Unlock()
+
+	for _, r := range rotators {
+		errs = append(errs, r.Sync(), r.Close())
+	}
+	for _, f := range files {
+		if err := f.Sync(); err != nil && !errors.Is(err, os.ErrClosed) {
+			errs = append(errs, err)
+		}
+		if err := f.Close(); err != nil && !errors.Is(err, os.ErrClosed) {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
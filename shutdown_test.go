@@ -0,0 +1,133 @@
+package log
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestShutdown(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	rotator := GetOutputWriterHourly(tmpDir)
+	async := NewAsyncWriter(rotator)
+	file := GetOutputWriter(filepath.Join(tmpDir, "plain", "app.log"))
+
+	async.Write([]byte("buffered line\n"))
+	file.Write([]byte("file line\n"))
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	data, err := os.ReadFile(filepath.Join(tmpDir, "current.log"))
+	if err != nil || !strings.Contains(string(data), "buffered line") {
+		t.Errorf("async data should be flushed to the rotator, got %q, %v", data, err)
+	}
+
+	for name, w := range map[string]interface{ Write([]byte) (int, error) }{
+		"rotator": rotator,
+		"async":   async,
+		"file":    file,
+	} {
+		if _, err := w.Write([]byte("late\n")); !errors.Is(err, ErrClosed) {
+			t.Errorf("%s write after Shutdown = %v, want ErrClosed", name, err)
+		}
+	}
+
+	rotatorMutex.Lock()
+	left := len(rotatorInstances) + len(fileInstances)
+	rotatorMutex.Unlock()
+	if left != 0 {
+		t.Errorf("registry should be empty, %d outputs left", left)
+	}
+
+	// A new rotator is created for the directory
+	fresh := GetOutputWriterHourly(tmpDir)
+	defer fresh.Close()
+	if fresh == rotator {
+		t.Fatal("closed rotator must not be returned again")
+	}
+	if _, err := fresh.Write([]byte("after restart\n")); err != nil {
+		t.Errorf("fresh rotator write failed: %v", err)
+	}
+}
+
+func TestShutdown_DrainsLoggers(t *testing.T) {
+	out := &lockedBuffer{}
+	logger := newTestLogger(out).SetAsync(16).SetDeduper(NewDeduper(time.Hour))
+	for i := 0; i < 3; i++ {
+		logger.Info("retrying")
+	}
+
+	if err := Shutdown(context.Background()); err != nil {
+		t.Fatalf("Shutdown failed: %v", err)
+	}
+
+	if got := out.String(); strings.Count(got, "retrying") != 2 || !strings.Contains(got, "repeated=2") {
+		t.Errorf("queued entries and collapsed lines should be written, got %q", got)
+	}
+
+	asyncMutex.Lock()
+	left := len(emitterInstances) + len(deduperInstances)
+	asyncMutex.Unlock()
+	if left != 0 {
+		t.Errorf("registry should be empty, %d workers left", left)
+	}
+
+	// The logger writes synchronously afterwards
+	logger.Info("after shutdown")
+	if !strings.Contains(out.String(), "after shutdown") {
+		t.Error("entry logged after Shutdown should be written")
+	}
+}
+
+func TestGetOutputWriter_SharedByPath(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+
+	first := GetOutputWriter(name)
+	if second := GetOutputWriter(name); second != first {
+		t.Error("the same path should return the same file")
+	}
+
+	first.(*os.File).Close()
+	fresh := GetOutputWriter(name)
+	defer fresh.(*os.File).Close()
+	if fresh == first {
+		t.Error("closed file must not be returned again")
+	}
+	if _, err := fresh.Write([]byte("line\n")); err != nil {
+		t.Errorf("fresh file write failed: %v", err)
+	}
+}
+
+func TestRotator_CloseUnregisters(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	first := GetOutputWriterHourly(tmpDir)
+	first.Close()
+
+	second := GetOutputWriterHourly(tmpDir)
+	defer second.Close()
+	if first == second {
+		t.Error("closed rotator should be removed from the registry")
+	}
+}
+
+func TestShutdown_ContextDone(t *testing.T) {
+	w := &blockingWriter{blockCh: make(chan struct{})}
+	async := NewAsyncWriter(w)
+	async.Write([]byte("stuck\n"))
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	if err := Shutdown(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Shutdown = %v, want context.DeadlineExceeded", err)
+	}
+	close(w.blockCh)
+}
@@ -0,0 +1,191 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// SlogHandler implements slog.Handler on top of a *Logger, so records emitted
+// through the standard log/slog API share the Logger's formatter, hooks,
+// trace IDs and outputs.
+type SlogHandler struct {
+	logger *Logger
+
+	// group is the dot-separated prefix applied to attributes added later
+	group string
+}
+
+// NewSlogHandler creates a slog.Handler that routes records into logger
+func NewSlogHandler(logger *Logger) *SlogHandler {
+	return &SlogHandler{logger: logger}
+}
+
+// NewSlogLogger creates a ready to use *slog.Logger backed by logger
+func NewSlogLogger(logger *Logger) *slog.Logger {
+	return slog.New(NewSlogHandler(logger))
+}
+
+// Enabled implements slog.Handler
+//
+// With module levels the caller is not known yet, Handle makes the final decision.
+func (h *SlogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	c := h.logger.config()
+	if c.modules == nil {
+		return c.level.Enabled(slogLevelToLevel(level))
+	}
+	return c.modules.mayEnable(slogLevelToLevel(level), c.level)
+}
+
+// Handle implements slog.Handler
+//
+// The caller is taken from the record's PC instead of callerDepth, so the
+// reported location is always the slog call site. Trace ID and fields carried
+// by ctx are added and the context extractors run, as for InfoContext and the
+// other *Context methods.
+func (h *SlogHandler) Handle(ctx context.Context, r slog.Record) error {
+	c := h.logger.config()
+	level := slogLevelToLevel(r.Level)
+	if c.modules != nil && r.PC != 0 && !c.modules.enabledAt(r.PC, level, c.level) {
+		return nil
+	}
+	if c.sampler != nil && !c.sampler.allow(level, r.Message, c.now().UnixNano()) {
+		return nil
+	}
+
+	entry := getEntry()
+
+	c.populateEntry(entry, level, r.Message)
+	if !r.Time.IsZero() {
+		entry.Time = r.Time
+		entry.TimeStr = r.Time.Format(time.RFC3339Nano)
+	}
+
+	if r.NumAttrs() == 0 {
+		c.populateFields(entry)
+	} else {
+		entry.Fields = make([]KV, 0, len(c.fields)+r.NumAttrs())
+		entry.Fields = append(entry.Fields, c.fields...)
+		r.Attrs(func(a slog.Attr) bool {
+			entry.Fields = appendSlogAttr(entry.Fields, h.group, a)
+			return true
+		})
+		if len(c.hooks) == 0 {
+			c.attachEncodedFields(entry)
+		}
+	}
+
+	c.fillTraceInfo(entry)
+	c.fillPrefixSuffix(entry)
+	c.fillContext(ctx, entry)
+
+	if c.async != nil && c.async.enqueue(c, entry, r.PC) {
+		return nil
+	}
+
+	c.fillCallerFrame(entry, r.PC)
+	c.emit(entry)
+	return nil
+}
+
+// WithAttrs implements slog.Handler
+//
+// The attributes are bound to a derived logger (see Logger.With), so they are
+// resolved and pre-encoded once.
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	if len(attrs) == 0 {
+		return h
+	}
+
+	kvs := make([]KV, 0, len(attrs))
+	for _, a := range attrs {
+		kvs = appendSlogAttr(kvs, h.group, a)
+	}
+
+	h2 := *h
+	h2.logger = h.logger.withFields(kvs)
+	return &h2
+}
+
+// WithGroup implements slog.Handler
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+
+	h2 := *h
+	h2.group = joinSlogKey(h.group, name)
+	return &h2
+}
+
+// slogLevelToLevel maps a slog level onto the closest Level.
+// Levels above slog.LevelError map to ErrorLevel so slog never triggers panic or exit.
+func slogLevelToLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return ErrorLevel
+	case level >= slog.LevelWarn:
+		return WarnLevel
+	case level >= slog.LevelInfo:
+		return InfoLevel
+	case level >= slog.LevelDebug:
+		return DebugLevel
+	default:
+		return TraceLevel
+	}
+}
+
+// appendSlogAttr flattens a slog attribute into fields, prefixing keys with group.
+// Nested groups become dot-separated keys (e.g. "req.header.host").
+func appendSlogAttr(fields []KV, group string, a slog.Attr) []KV {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return fields
+	}
+
+	if a.Value.Kind() == slog.KindGroup {
+		attrs := a.Value.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		// Groups with an empty key are inlined into the parent
+		prefix := group
+		if a.Key != "" {
+			prefix = joinSlogKey(group, a.Key)
+		}
+		for _, ga := range attrs {
+			fields = appendSlogAttr(fields, prefix, ga)
+		}
+		return fields
+	}
+
+	return append(fields, slogField(joinSlogKey(group, a.Key), a.Value))
+}
+
+// slogField converts a resolved slog value to a typed field
+func slogField(key string, v slog.Value) Field {
+	switch v.Kind() {
+	case slog.KindString:
+		return String(key, v.String())
+	case slog.KindInt64:
+		return Int64(key, v.Int64())
+	case slog.KindFloat64:
+		return Float64(key, v.Float64())
+	case slog.KindBool:
+		return Bool(key, v.Bool())
+	case slog.KindDuration:
+		return Duration(key, v.Duration())
+	case slog.KindTime:
+		return Time(key, v.Time())
+	default:
+		return Field{Key: key, Value: v.Any()}
+	}
+}
+
+// joinSlogKey joins a group prefix and a key with a dot
+func joinSlogKey(group, key string) string {
+	if group == "" {
+		return key
+	}
+	return group + "." + key
+}
@@ -0,0 +1,198 @@
+package log
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/lazygophers/log/constant"
+)
+
+func decodeSlogLine(t *testing.T, buf *bytes.Buffer) map[string]interface{} {
+	t.Helper()
+	var m map[string]interface{}
+	if err := json.Unmarshal(bytes.TrimSpace(buf.Bytes()), &m); err != nil {
+		t.Fatalf("invalid JSON output %q: %v", buf.String(), err)
+	}
+	return m
+}
+
+func TestSlogLevelToLevel(t *testing.T) {
+	tests := []struct {
+		in   slog.Level
+		want Level
+	}{
+		{slog.LevelDebug - 4, TraceLevel},
+		{slog.LevelDebug, DebugLevel},
+		{slog.LevelInfo, InfoLevel},
+		{slog.LevelInfo + 1, InfoLevel},
+		{slog.LevelWarn, WarnLevel},
+		{slog.LevelError, ErrorLevel},
+		{slog.LevelError + 8, ErrorLevel},
+	}
+
+	for _, tt := range tests {
+		if got := slogLevelToLevel(tt.in); got != tt.want {
+			t.Errorf("slogLevelToLevel(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSlogHandler_Enabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetFormatter(&JSONFormatter{})
+	logger.SetLevel(WarnLevel)
+	h := NewSlogHandler(logger)
+
+	if h.Enabled(context.Background(), slog.LevelInfo) {
+		t.Error("Info should be disabled at WarnLevel")
+	}
+	if !h.Enabled(context.Background(), slog.LevelError) {
+		t.Error("Error should be enabled at WarnLevel")
+	}
+
+	NewSlogLogger(logger).Info("dropped")
+	if buf.Len() != 0 {
+		t.Errorf("disabled record should not be written, got %q", buf.String())
+	}
+}
+
+func TestSlogHandler_Fields(t *testing.T) {
+	var buf bytes.Buffer
+	sl := NewSlogLogger(newTestLogger(&buf).SetFormatter(&JSONFormatter{}))
+
+	sl.Warn("hello", "user", "alice", "count", 3)
+
+	m := decodeSlogLine(t, &buf)
+	if m["message"] != "hello" {
+		t.Errorf("message = %v, want hello", m["message"])
+	}
+	if m["level"] != "warn" {
+		t.Errorf("level = %v, want warn", m["level"])
+	}
+	fields, _ := m["fields"].(map[string]interface{})
+	if fields["user"] != "alice" || fields["count"] != float64(3) {
+		t.Errorf("unexpected fields %v", fields)
+	}
+}
+
+func TestSlogHandler_Context(t *testing.T) {
+	var buf bytes.Buffer
+	logger := New().SetOutput(&buf).SetFormatter(&JSONFormatter{})
+	logger.AddContextExtractor(func(ctx context.Context, entry *Entry) {
+		entry.Fields = append(entry.Fields, KV{Key: "tenant", Value: "t1"})
+	})
+
+	ctx := ContextWithFields(ContextWithTrace(context.Background(), "trace-ctx"), "request_id", "r1")
+	NewSlogLogger(logger).InfoContext(ctx, "hello", "user", "alice")
+
+	m := decodeSlogLine(t, &buf)
+	if m["trace_id"] != "trace-ctx" {
+		t.Errorf("trace_id = %v, want the trace ID carried by ctx", m["trace_id"])
+	}
+	fields, _ := m["fields"].(map[string]interface{})
+	if fields["user"] != "alice" || fields["request_id"] != "r1" || fields["tenant"] != "t1" {
+		t.Errorf("fields should include those of the record, the context and the extractors, got %v", fields)
+	}
+}
+
+func TestSlogHandler_Groups(t *testing.T) {
+	var buf bytes.Buffer
+	sl := NewSlogLogger(newTestLogger(&buf).SetFormatter(&JSONFormatter{}))
+
+	sl.With("service", "api").
+		WithGroup("req").
+		With("id", 7).
+		Info("handled",
+			slog.Group("header", slog.String("host", "example.com")),
+			slog.Group("", slog.String("inline", "yes")),
+			slog.Group("empty"),
+			"status", 200,
+		)
+
+	m := decodeSlogLine(t, &buf)
+	fields, _ := m["fields"].(map[string]interface{})
+	want := map[string]interface{}{
+		"service":         "api",
+		"req.id":          float64(7),
+		"req.header.host": "example.com",
+		"req.inline":      "yes",
+		"req.status":      float64(200),
+	}
+	if len(fields) != len(want) {
+		t.Errorf("fields = %v, want %v", fields, want)
+	}
+	for k, v := range want {
+		if fields[k] != v {
+			t.Errorf("fields[%q] = %v, want %v", k, fields[k], v)
+		}
+	}
+}
+
+func TestSlogHandler_WithDoesNotLeak(t *testing.T) {
+	var buf bytes.Buffer
+	sl := NewSlogLogger(newTestLogger(&buf).SetFormatter(&JSONFormatter{}))
+
+	_ = sl.With("bound", 1).WithGroup("g")
+	sl.Info("plain", "k", "v")
+
+	m := decodeSlogLine(t, &buf)
+	fields, _ := m["fields"].(map[string]interface{})
+	if len(fields) != 1 || fields["k"] != "v" {
+		t.Errorf("parent handler should be unaffected by derived handlers, got %v", fields)
+	}
+}
+
+func TestSlogHandler_CallerFromRecordPC(t *testing.T) {
+	var buf bytes.Buffer
+	sl := NewSlogLogger(newTestLogger(&buf).EnableCaller(true).SetFormatter(&JSONFormatter{}))
+
+	sl.Info("where")
+
+	m := decodeSlogLine(t, &buf)
+	file, _ := m["caller_file"].(string)
+	if !strings.HasSuffix(file, "slog_test.go") {
+		t.Errorf("caller_file = %q, want slog_test.go", file)
+	}
+	if fn, _ := m["caller_func"].(string); fn != "TestSlogHandler_CallerFromRecordPC" {
+		t.Errorf("caller_func = %q, want TestSlogHandler_CallerFromRecordPC", fn)
+	}
+}
+
+func TestSlogHandler_CallerDisabled(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetFormatter(&JSONFormatter{})
+	logger.EnableCaller(false)
+
+	NewSlogLogger(logger).Info("no caller")
+
+	m := decodeSlogLine(t, &buf)
+	if _, ok := m["caller_file"]; ok {
+		t.Errorf("caller_file should be omitted, got %v", m["caller_file"])
+	}
+}
+
+func TestSlogHandler_Hooks(t *testing.T) {
+	var buf bytes.Buffer
+	logger := newTestLogger(&buf).SetFormatter(&JSONFormatter{})
+	logger.AddHook(constant.HookFunc(func(entry interface{}) interface{} {
+		if e, ok := entry.(*Entry); ok && e.Message == "skip" {
+			return nil
+		}
+		return entry
+	}))
+	sl := NewSlogLogger(logger)
+
+	sl.Info("skip")
+	if buf.Len() != 0 {
+		t.Errorf("hook should filter slog records, got %q", buf.String())
+	}
+
+	sl.Info("keep")
+	if !strings.Contains(buf.String(), "keep") {
+		t.Errorf("expected output to contain keep, got %q", buf.String())
+	}
+}
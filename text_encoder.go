@@ -0,0 +1,239 @@
+package log
+
+import (
+	"strconv"
+	"time"
+	"unicode/utf8"
+
+	"github.com/lazygophers/log/constant"
+)
+
+// textEncoder is the ObjectEncoder and ArrayEncoder of Formatter and
+// LogfmtFormatter. Members of nested objects are written as
+// prefix.key=value, array elements as prefix.index=value.
+type textEncoder struct {
+	buf    []byte
+	prefix string
+	first  bool // nothing written yet on the line
+	index  int  // next array element
+	quote  bool // quote values as logfmt requires
+}
+
+// appendTextFields appends fields as space separated key=value pairs,
+// quoting values that need it when quote is set.
+// Keys following a namespace are prefixed with it, as in ns.key=value.
+func appendTextFields(dst []byte, fields []KV, quote bool) []byte {
+	enc := &textEncoder{buf: dst, first: true, quote: quote}
+	for _, field := range fields {
+		switch {
+		case field.Type == constant.FieldTypeNamespace:
+			enc.prefix += field.Key + "."
+		case isMarshaler(field):
+			mark, first := len(enc.buf), enc.first
+			if err := enc.AddAny(field.Key, field.Value); err != nil {
+				// Replace what the marshaler wrote by its error
+				enc.buf, enc.first = enc.buf[:mark], first
+				enc.AddString(field.Key+"Error", err.Error())
+			}
+		default:
+			start := enc.key(field.Key)
+			enc.buf = appendTextValue(enc.buf, field)
+			enc.quoteFrom(start)
+		}
+	}
+	return enc.buf
+}
+
+// key starts a key=value pair, returning where the value starts
+func (e *textEncoder) key(key string) int {
+	if !e.first {
+		e.buf = append(e.buf, ' ')
+	}
+	e.first = false
+	if e.quote {
+		e.buf = appendLogfmtKey(e.buf, e.prefix)
+		e.buf = appendLogfmtKey(e.buf, key)
+	} else {
+		e.buf = append(e.buf, e.prefix...)
+		e.buf = append(e.buf, key...)
+	}
+	e.buf = append(e.buf, '=')
+	return len(e.buf)
+}
+
+// appendLogfmtKey appends key with the bytes logfmt keys can't hold, spaces,
+// control characters, quotes and '=', replaced by '_'
+func appendLogfmtKey(dst []byte, key string) []byte {
+	for i := 0; i < len(key); i++ {
+		if c := key[i]; c <= ' ' || c == '=' || c == '"' || c == 0x7f {
+			dst = append(dst, '_')
+		} else {
+			dst = append(dst, c)
+		}
+	}
+	return dst
+}
+
+// quoteFrom quotes the value written from start when the encoder quotes
+// and the value needs it
+func (e *textEncoder) quoteFrom(start int) {
+	if !e.quote || !needsLogfmtQuote(e.buf[start:]) {
+		return
+	}
+	value := string(e.buf[start:])
+	e.buf = appendJSONString(e.buf[:start], value)
+}
+
+// needsLogfmtQuote reports whether a logfmt value must be quoted: when it is
+// empty or holds spaces, control characters, quotes, '=' or invalid UTF-8
+func needsLogfmtQuote(value []byte) bool {
+	if len(value) == 0 {
+		return true
+	}
+	for _, c := range value {
+		if c <= ' ' || c == '=' || c == '"' || c == '\\' || c == 0x7f {
+			return true
+		}
+	}
+	return !utf8.Valid(value)
+}
+
+// nextIndex returns the key of the next array element
+func (e *textEncoder) nextIndex() string {
+	i := e.index
+	e.index++
+	return strconv.Itoa(i)
+}
+
+// nest runs fn with key added to the prefix and a fresh array index
+func (e *textEncoder) nest(key string, fn func() error) error {
+	prefix, index := e.prefix, e.index
+	e.prefix, e.index = prefix+key+".", 0
+	err := fn()
+	e.prefix, e.index = prefix, index
+	return err
+}
+
+// AddString implements ObjectEncoder
+func (e *textEncoder) AddString(key, val string) {
+	e.key(key)
+	if e.quote && needsLogfmtQuote([]byte(val)) {
+		e.buf = appendJSONString(e.buf, val)
+		return
+	}
+	e.buf = append(e.buf, val...)
+}
+
+// AddInt implements ObjectEncoder
+func (e *textEncoder) AddInt(key string, val int) {
+	e.key(key)
+	e.buf = strconv.AppendInt(e.buf, int64(val), 10)
+}
+
+// AddInt64 implements ObjectEncoder
+func (e *textEncoder) AddInt64(key string, val int64) {
+	e.key(key)
+	e.buf = strconv.AppendInt(e.buf, val, 10)
+}
+
+// AddFloat64 implements ObjectEncoder
+func (e *textEncoder) AddFloat64(key string, val float64) {
+	e.key(key)
+	e.buf = strconv.AppendFloat(e.buf, val, 'g', -1, 64)
+}
+
+// AddBool implements ObjectEncoder
+func (e *textEncoder) AddBool(key string, val bool) {
+	e.key(key)
+	e.buf = strconv.AppendBool(e.buf, val)
+}
+
+// AddDuration implements ObjectEncoder
+func (e *textEncoder) AddDuration(key string, val time.Duration) {
+	e.key(key)
+	e.buf = append(e.buf, val.String()...)
+}
+
+// AddTime implements ObjectEncoder, as RFC 3339 with nanoseconds
+func (e *textEncoder) AddTime(key string, val time.Time) {
+	e.key(key)
+	e.buf = val.AppendFormat(e.buf, time.RFC3339Nano)
+}
+
+// AddObject implements ObjectEncoder
+func (e *textEncoder) AddObject(key string, obj ObjectMarshaler) error {
+	return e.nest(key, func() error {
+		return obj.MarshalLogObject(e)
+	})
+}
+
+// AddArray implements ObjectEncoder
+func (e *textEncoder) AddArray(key string, arr ArrayMarshaler) error {
+	return e.nest(key, func() error {
+		return arr.MarshalLogArray(e)
+	})
+}
+
+// AddAny implements ObjectEncoder
+func (e *textEncoder) AddAny(key string, val interface{}) error {
+	switch v := val.(type) {
+	case ObjectMarshaler:
+		return e.AddObject(key, v)
+	case ArrayMarshaler:
+		return e.AddArray(key, v)
+	}
+	start := e.key(key)
+	e.buf = appendTextValue(e.buf, KV{Value: val})
+	e.quoteFrom(start)
+	return nil
+}
+
+// AppendString implements ArrayEncoder
+func (e *textEncoder) AppendString(val string) {
+	e.AddString(e.nextIndex(), val)
+}
+
+// AppendInt implements ArrayEncoder
+func (e *textEncoder) AppendInt(val int) {
+	e.AddInt(e.nextIndex(), val)
+}
+
+// AppendInt64 implements ArrayEncoder
+func (e *textEncoder) AppendInt64(val int64) {
+	e.AddInt64(e.nextIndex(), val)
+}
+
+// AppendFloat64 implements ArrayEncoder
+func (e *textEncoder) AppendFloat64(val float64) {
+	e.AddFloat64(e.nextIndex(), val)
+}
+
+// AppendBool implements ArrayEncoder
+func (e *textEncoder) AppendBool(val bool) {
+	e.AddBool(e.nextIndex(), val)
+}
+
+// AppendDuration implements ArrayEncoder
+func (e *textEncoder) AppendDuration(val time.Duration) {
+	e.AddDuration(e.nextIndex(), val)
+}
+
+// AppendTime implements ArrayEncoder
+func (e *textEncoder) AppendTime(val time.Time) {
+	e.AddTime(e.nextIndex(), val)
+}
+
+// AppendObject implements ArrayEncoder
+func (e *textEncoder) AppendObject(obj ObjectMarshaler) error {
+	return e.AddObject(e.nextIndex(), obj)
+}
+
+// AppendArray implements ArrayEncoder
+func (e *textEncoder) AppendArray(arr ArrayMarshaler) error {
+	return e.AddArray(e.nextIndex(), arr)
+}
+
+// AppendAny implements ArrayEncoder
+func (e *textEncoder) AppendAny(val interface{}) error {
+	return e.AddAny(e.nextIndex(), val)
+}
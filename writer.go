@@ -1,6 +1,10 @@
 package log
 
-import "io"
+import (
+	"io"
+
+	"github.com/lazygophers/log/constant"
+)
 
 // Writer defines a closable writer interface
 type Writer interface {
@@ -10,3 +14,61 @@ type Writer interface {
 	// Close closes the writer and releases all resources
 	Close() error
 }
+
+// LevelWriter is implemented by writers that take the level of each entry
+// into account, such as AsyncWriter. Loggers call WriteLevel instead of Write.
+type LevelWriter interface {
+	WriteLevel(level Level, p []byte) (n int, err error)
+}
+
+// levelWriteSyncer keeps WriteLevel of a writer wrapped by constant.AddSync
+type levelWriteSyncer struct {
+	constant.WriteSyncer
+	LevelWriter
+}
+
+// addLevelSync converts w to a WriteSyncer, keeping WriteLevel if w has it
+func addLevelSync(w io.Writer) constant.WriteSyncer {
+	ws := constant.AddSync(w)
+	if lw, ok := w.(LevelWriter); ok {
+		if _, ok := ws.(LevelWriter); !ok {
+			return levelWriteSyncer{WriteSyncer: ws, LevelWriter: lw}
+		}
+	}
+	return ws
+}
+
+// newMultiWriteSyncer writes to all writers, passing levels on to those
+// implementing LevelWriter
+func newMultiWriteSyncer(writers ...constant.WriteSyncer) constant.WriteSyncer {
+	for _, w := range writers {
+		if _, ok := w.(LevelWriter); ok {
+			return &multiLevelWriteSyncer{WriteSyncer: constant.NewMultiWriteSyncer(writers...), writers: writers}
+		}
+	}
+	return constant.NewMultiWriteSyncer(writers...)
+}
+
+// multiLevelWriteSyncer is a multi writer implementing LevelWriter
+type multiLevelWriteSyncer struct {
+	constant.WriteSyncer
+	writers []constant.WriteSyncer
+}
+
+// WriteLevel writes p to every writer, stopping at the first error
+func (m *multiLevelWriteSyncer) WriteLevel(level Level, p []byte) (n int, err error) {
+	for _, w := range m.writers {
+		if lw, ok := w.(LevelWriter); ok {
+			n, err = lw.WriteLevel(level, p)
+		} else {
+			n, err = w.Write(p)
+		}
+		if err != nil {
+			return
+		}
+		if n != len(p) {
+			return n, io.ErrShortWrite
+		}
+	}
+	return len(p), nil
+}
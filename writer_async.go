@@ -4,18 +4,159 @@
 	"bytes"
 	"errors"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
+// DefaultAsyncMaxEntries is the number of entries an AsyncWriter buffers by default
+const DefaultAsyncMaxEntries = 1024
+
+// OverflowPolicy decides what an AsyncWriter does with a write when its buffer is full
+type OverflowPolicy int
+
+const (
+	// OverflowDropNewest rejects the write with ErrAsyncWriterFull, the default
+	OverflowDropNewest OverflowPolicy = iota
+
+	// OverflowBlock waits until the buffer has room
+	OverflowBlock
+
+	// OverflowBlockTimeout waits up to BlockTimeout, then rejects the write
+	// with ErrAsyncWriterFull
+	OverflowBlockTimeout
+
+	// OverflowDropOldest discards the oldest buffered entries to make room
+	OverflowDropOldest
+
+	// OverflowDropBelowLevel rejects entries less severe than KeepLevel and
+	// makes room for the others by discarding the oldest less severe entry,
+	// waiting when there is none. Writes without a level count as less severe.
+	OverflowDropBelowLevel
+)
+
+// AsyncWriterConfig configures an AsyncWriter
+type AsyncWriterConfig struct {
+	// MaxEntries is the number of entries buffered, defaults to DefaultAsyncMaxEntries
+	MaxEntries int
+
+	// MaxBytes caps the bytes buffered, 0 means no limit.
+	// A single larger entry is still accepted into an empty buffer.
+	MaxBytes int
+
+	// Overflow is applied when the buffer is full, defaults to OverflowDropNewest
+	Overflow OverflowPolicy
+
+	// BlockTimeout bounds the wait of OverflowBlockTimeout, defaults to one second
+	BlockTimeout time.Duration
+
+	// KeepLevel points at the least severe level OverflowDropBelowLevel never
+	// drops, nil defaults to ErrorLevel
+	KeepLevel *Level
+
+	// FlushInterval lets entries wait up to this long to be written in a
+	// larger batch, 0 writes as soon as possible. Sync and Close write at once.
+	FlushInterval time.Duration
+
+	// MaxBatch caps the entries per write to the underlying writer, 0 means no cap
+	MaxBatch int
+}
+
+// AsyncWriterStats are the counters of an AsyncWriter
+type AsyncWriterStats struct {
+	// Written is the number of entries handed to the underlying writer
+	Written uint64
+
+	// Dropped is the number of entries rejected or discarded on overflow
+	Dropped uint64
+
+	// Blocked is the number of writes that waited for room
+	Blocked uint64
+
+	// TimedOut is the number of writes rejected after waiting BlockTimeout
+	TimedOut uint64
+
+	// Queued and QueuedBytes describe the buffer at the time of the call
+	Queued      int
+	QueuedBytes int
+}
+
+// asyncEntry is a buffered write
+type asyncEntry struct {
+	b     []byte
+	seq   uint64 // position in the order of writes, starting at 1
+	level Level
+	// leveled is false for plain writes, which have no level
+	leveled bool
+}
+
 // AsyncWriter defines an asynchronous log writer
 type AsyncWriter struct {
-	writer Writer               // writer performs actual write operations
-	c      chan []byte          // c is the channel for buffering log data
-	close  chan *sync.WaitGroup // close channel for shutdown signal with WaitGroup sync
+	writer    Writer // writer performs actual write operations
+	config    AsyncWriterConfig
+	keepLevel Level // resolved KeepLevel
+
+	mu          sync.Mutex
+	queue       []asyncEntry  // buffered entries, oldest first
+	queuedBytes int           // total size of the buffered entries
+	space       chan struct{} // closed and replaced whenever room is made
+	closed      bool          // closed rejects writes once Close has been called
+	firstQueued time.Time     // when the oldest buffered entry was written
+
+	// Entries are numbered as they are buffered, flushed is the number up to
+	// which all of them were written or dropped, syncWanted the number Sync waits for
+	enqueued   uint64
+	flushed    uint64
+	syncWanted uint64
+	progress   chan struct{} // closed and replaced whenever flushed moves
+
+	wake chan struct{} // wakes the background goroutine
+	done chan struct{} // closed when the background goroutine exits
+
+	written  atomic.Uint64
+	dropped  atomic.Uint64
+	blocked  atomic.Uint64
+	timedOut atomic.Uint64
 }
 
 // ErrAsyncWriterFull is returned when the async writer buffer is full
 var ErrAsyncWriterFull = errors.New("async writer full")
 
+// NewAsyncWriter creates and initializes an AsyncWriter instance buffering up
+// to DefaultAsyncMaxEntries entries and rejecting writes beyond
+func NewAsyncWriter(writer Writer) *AsyncWriter {
+	return NewAsyncWriterWithConfig(writer, AsyncWriterConfig{})
+}
+
+// NewAsyncWriterWithConfig creates an AsyncWriter with the given capacity and overflow policy
+func NewAsyncWriterWithConfig(writer Writer, config AsyncWriterConfig) *AsyncWriter {
+	if config.MaxEntries <= 0 {
+		config.MaxEntries = DefaultAsyncMaxEntries
+	}
+	if config.BlockTimeout <= 0 {
+		config.BlockTimeout = time.Second
+	}
+	keep := ErrorLevel
+	if config.KeepLevel != nil {
+		keep = *config.KeepLevel
+	}
+
+	p := &AsyncWriter{
+		writer:    writer,
+		config:    config,
+		keepLevel: keep,
+		space:     make(chan struct{}),
+		progress:  make(chan struct{}),
+		wake:      make(chan struct{}, 1),
+		done:      make(chan struct{}),
+	}
+	registerAsyncWriter(p)
+
+	// Start background goroutine to consume buffered data and batch write
+	go p.run()
+
+	return p
+}
+
 // Write asynchronously writes byte data
 //
 // CRITICAL: This method copies the input slice to avoid buffer pool data races.
@@ -23,84 +164,290 @@ type AsyncWriter struct {
 // after the write call returns. Without copying, the async goroutine may read
 // corrupted data from a reused buffer.
 func (p *AsyncWriter) Write(b []byte) (n int, err error) {
+	return p.enqueue(asyncEntry{b: b})
+}
+
+// WriteLevel is Write for an entry logged at level, used by
+// OverflowDropBelowLevel. Loggers call it instead of Write.
+func (p *AsyncWriter) WriteLevel(level Level, b []byte) (n int, err error) {
+	return p.enqueue(asyncEntry{b: b, level: level, leveled: true})
+}
+
+// enqueue buffers e, applying the overflow policy when the buffer is full
+func (p *AsyncWriter) enqueue(e asyncEntry) (int, error) {
+	n := len(e.b)
+
 	// Copy the slice to avoid buffer pool concurrency issues
 	// The input bytes typically come from formatter buffer pool and will be
 	// reused immediately after this function returns. We need our own copy.
-	copiedBytes := make([]byte, len(b))
-	copy(copiedBytes, b)
+	e.b = bytes.Clone(e.b)
 
-	select {
-	case p.c <- copiedBytes:
-		return len(b), nil
-	default:
-		return 0, ErrAsyncWriterFull
+	var deadline <-chan time.Time
+	waited := false
+
+	p.mu.Lock()
+	for {
+		if p.closed {
+			p.mu.Unlock()
+			return 0, ErrClosed
+		}
+		if p.fits(len(e.b)) {
+			break
+		}
+
+		switch p.config.Overflow {
+		case OverflowDropOldest:
+			p.dropAt(0)
+			continue
+
+		case OverflowDropBelowLevel:
+			if !p.keep(e) {
+				p.mu.Unlock()
+				p.dropped.Add(1)
+				return 0, ErrAsyncWriterFull
+			}
+			if i := p.droppable(); i >= 0 {
+				p.dropAt(i)
+				continue
+			}
+
+		case OverflowBlock:
+
+		case OverflowBlockTimeout:
+			if deadline == nil {
+				timer := time.NewTimer(p.config.BlockTimeout)
+				defer timer.Stop()
+				deadline = timer.C
+			}
+
+		default:
+			p.mu.Unlock()
+			p.dropped.Add(1)
+			return 0, ErrAsyncWriterFull
+		}
+
+		// Wait for the background goroutine to make room
+		if !waited {
+			waited = true
+			p.blocked.Add(1)
+		}
+		space := p.space
+		p.mu.Unlock()
+
+		select {
+		case <-space:
+		case <-deadline:
+			p.dropped.Add(1)
+			p.timedOut.Add(1)
+			return 0, ErrAsyncWriterFull
+		}
+		p.mu.Lock()
 	}
+
+	if len(p.queue) == 0 {
+		p.firstQueued = time.Now()
+	}
+	p.enqueued++
+	e.seq = p.enqueued
+	p.queue = append(p.queue, e)
+	p.queuedBytes += len(e.b)
+	p.mu.Unlock()
+
+	p.notify()
+	return n, nil
 }
 
-// Close gracefully shuts down the async writer
-func (p *AsyncWriter) Close() error {
-	var w sync.WaitGroup
-	w.Add(1)
+// notify wakes the background goroutine
+func (p *AsyncWriter) notify() {
 	select {
-	case p.close <- &w:
-		w.Wait()
+	case p.wake <- struct{}{}:
 	default:
 	}
-	return nil
 }
 
-// NewAsyncWriter creates and initializes an AsyncWriter instance
-func NewAsyncWriter(writer Writer) *AsyncWriter {
-	p := &AsyncWriter{
-		writer: writer,
-		c:      make(chan []byte, 1024),       // Initialize buffer channel with capacity 1024
-		close:  make(chan *sync.WaitGroup, 1), // Initialize close signal channel with capacity 1
+// fits reports whether size more bytes fit in the buffer. Must be called with p.mu held.
+func (p *AsyncWriter) fits(size int) bool {
+	if len(p.queue) >= p.config.MaxEntries {
+		return false
+	}
+	return p.config.MaxBytes <= 0 || len(p.queue) == 0 || p.queuedBytes+size <= p.config.MaxBytes
+}
+
+// keep reports whether OverflowDropBelowLevel keeps e
+func (p *AsyncWriter) keep(e asyncEntry) bool {
+	return e.leveled && e.level <= p.keepLevel
+}
+
+// droppable returns the index of the oldest entry OverflowDropBelowLevel may
+// discard, -1 if none. Must be called with p.mu held.
+func (p *AsyncWriter) droppable() int {
+	for i, e := range p.queue {
+		if !p.keep(e) {
+			return i
+		}
 	}
+	return -1
+}
+
+// dropAt discards the buffered entry at i. Must be called with p.mu held.
+func (p *AsyncWriter) dropAt(i int) {
+	p.queuedBytes -= len(p.queue[i].b)
+	p.queue = append(p.queue[:i], p.queue[i+1:]...)
+	p.dropped.Add(1)
+}
+
+// run writes buffered entries in batches until Close
+func (p *AsyncWriter) run() {
+	defer close(p.done)
 
-	// Start background goroutine to consume channel data and batch write
-	go func() {
-		var cache bytes.Buffer // Create byte buffer to collect multiple log entries
-		for {
-			cache.Reset() // Reset buffer at the beginning of each loop
+	var cache bytes.Buffer // Collects the entries of a batch
+	var batch []asyncEntry
+	var timer *time.Timer
+	for {
+		p.mu.Lock()
+		if len(p.queue) == 0 {
+			// Everything buffered so far was written or dropped
+			p.advance(p.enqueued)
+			closed := p.closed
+			p.mu.Unlock()
+
+			if closed {
+				return
+			}
+			<-p.wake
+			continue
+		}
+
+		if wait := p.holdFor(); wait > 0 {
+			p.mu.Unlock()
+
+			// Give the batch time to fill up, Sync and Close cut it short
+			if timer == nil {
+				timer = time.NewTimer(wait)
+			} else {
+				timer.Reset(wait)
+			}
 			select {
-			// case1: Normal log data reception
-			case c := <-p.c:
-				// Write first log entry to buffer
-				_, _ = cache.Write(c)
-				// Inner loop to read as much data as possible for batch processing
-				for {
-					select {
-					case c := <-p.c:
-						_, _ = cache.Write(c) // Append subsequent log entries to buffer
-					default:
-						goto OUT // If channel is empty, exit inner loop and perform write
-					}
-				}
-			OUT:
-				// Write collected log entries to underlying writer in one batch
-				_, _ = p.writer.Write(cache.Bytes())
-
-			// case2: Shutdown signal received, prepare graceful exit
-			case w := <-p.close:
-				// Process all remaining log entries in channel before exit
-				for {
-					select {
-					case c := <-p.c:
-						_, _ = cache.Write(c) // Append remaining log entries to buffer
-					default:
-						goto END // If channel is empty, exit loop
-					}
-				}
-			END:
-				// Write any remaining log entries in buffer to underlying writer
-				if cache.Len() > 0 {
-					_, _ = p.writer.Write(cache.Bytes())
-				}
-				w.Done() // Notify Close() method that cleanup is complete
-				return   // Exit goroutine
+			case <-timer.C:
+			case <-p.wake:
+				timer.Stop()
 			}
+			continue
 		}
-	}()
 
-	return p
+		// Take a batch, writers blocked on space can carry on
+		n := len(p.queue)
+		if p.config.MaxBatch > 0 {
+			n = min(n, p.config.MaxBatch)
+		}
+		batch = append(batch[:0], p.queue[:n]...)
+		rest := copy(p.queue, p.queue[n:])
+		clear(p.queue[rest:])
+		p.queue = p.queue[:rest]
+		for _, e := range batch {
+			p.queuedBytes -= len(e.b)
+		}
+		if rest > 0 {
+			p.firstQueued = time.Now()
+		}
+		close(p.space)
+		p.space = make(chan struct{})
+		p.mu.Unlock()
+
+		cache.Reset()
+		for _, e := range batch {
+			_, _ = cache.Write(e.b)
+		}
+		// Write collected log entries to underlying writer in one batch
+		_, _ = p.writer.Write(cache.Bytes())
+		p.written.Add(uint64(len(batch)))
+
+		p.mu.Lock()
+		p.advance(batch[len(batch)-1].seq)
+		p.mu.Unlock()
+		clear(batch)
+	}
+}
+
+// holdFor returns how long the buffered entries may still wait for a larger
+// batch, 0 to write them now. Must be called with p.mu held.
+func (p *AsyncWriter) holdFor() time.Duration {
+	if p.config.FlushInterval <= 0 || p.closed || p.syncWanted > p.flushed {
+		return 0
+	}
+	if p.config.MaxBatch > 0 && len(p.queue) >= p.config.MaxBatch {
+		return 0
+	}
+	return p.config.FlushInterval - time.Since(p.firstQueued)
+}
+
+// advance records that entries up to seq were written or dropped.
+// Must be called with p.mu held.
+func (p *AsyncWriter) advance(seq uint64) {
+	if seq > p.flushed {
+		p.flushed = seq
+		close(p.progress)
+		p.progress = make(chan struct{})
+	}
+}
+
+// Sync blocks until every entry written before the call is handed to the
+// underlying writer, then syncs the underlying writer if it supports it
+func (p *AsyncWriter) Sync() error {
+	p.mu.Lock()
+	target := p.enqueued
+	p.syncWanted = max(p.syncWanted, target)
+	for p.flushed < target {
+		progress := p.progress
+		p.mu.Unlock()
+
+		p.notify()
+		<-progress
+		p.mu.Lock()
+	}
+	p.mu.Unlock()
+
+	if s, ok := p.writer.(interface{ Sync() error }); ok {
+		return s.Sync()
+	}
+	return nil
+}
+
+// Stats returns the counters of the writer
+func (p *AsyncWriter) Stats() AsyncWriterStats {
+	p.mu.Lock()
+	queued, queuedBytes := len(p.queue), p.queuedBytes
+	p.mu.Unlock()
+
+	return AsyncWriterStats{
+		Written:     p.written.Load(),
+		Dropped:     p.dropped.Load(),
+		Blocked:     p.blocked.Load(),
+		TimedOut:    p.timedOut.Load(),
+		Queued:      queued,
+		QueuedBytes: queuedBytes,
+	}
+}
+
+// Close gracefully shuts down the async writer, writing out buffered data.
+// It does not close the underlying writer. Later writes fail with ErrClosed.
+// Close may be called several times and concurrently with Write.
+func (p *AsyncWriter) Close() error {
+	p.mu.Lock()
+	if p.closed {
+		p.mu.Unlock()
+		<-p.done
+		return nil
+	}
+	p.closed = true
+	// Release writers waiting for room, they fail with ErrClosed
+	close(p.space)
+	p.space = make(chan struct{})
+	p.mu.Unlock()
+
+	unregisterAsyncWriter(p)
+
+	p.notify()
+	<-p.done
+	return nil
 }
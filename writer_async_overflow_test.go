@@ -0,0 +1,269 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// gateWriter records writes, holding the first one until open is called
+type gateWriter struct {
+	lockedBuffer
+	gate chan struct{}
+	once sync.Once
+}
+
+func newGateWriter() *gateWriter {
+	return &gateWriter{gate: make(chan struct{})}
+}
+
+func (w *gateWriter) Write(p []byte) (int, error) {
+	<-w.gate
+	return w.lockedBuffer.Write(p)
+}
+
+func (w *gateWriter) Close() error { return nil }
+
+func (w *gateWriter) open() { w.once.Do(func() { close(w.gate) }) }
+
+// newStalledAsyncWriter returns an AsyncWriter whose background goroutine is
+// stuck writing "trigger;" until out.open is called
+func newStalledAsyncWriter(t *testing.T, config AsyncWriterConfig) (*AsyncWriter, *gateWriter) {
+	t.Helper()
+	out := newGateWriter()
+	w := NewAsyncWriterWithConfig(out, config)
+	t.Cleanup(func() {
+		out.open()
+		w.Close()
+	})
+
+	w.Write([]byte("trigger;"))
+	waitForStats(t, w, func(s AsyncWriterStats) bool { return s.Queued == 0 })
+	return w, out
+}
+
+func waitForStats(t *testing.T, w *AsyncWriter, cond func(AsyncWriterStats) bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond(w.Stats()) {
+		if time.Now().After(deadline) {
+			t.Fatalf("condition not met, stats %+v", w.Stats())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// drain releases the writer, closes it and returns everything written
+func drain(w *AsyncWriter, out *gateWriter) string {
+	out.open()
+	w.Close()
+	return out.String()
+}
+
+func TestAsyncWriter_DropNewest(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 2})
+
+	w.Write([]byte("a;"))
+	w.Write([]byte("b;"))
+	if _, err := w.Write([]byte("c;")); err != ErrAsyncWriterFull {
+		t.Errorf("Write on a full buffer = %v", err)
+	}
+
+	if s := w.Stats(); s.Dropped != 1 || s.Queued != 2 || s.QueuedBytes != 4 {
+		t.Errorf("stats %+v", s)
+	}
+	if got := drain(w, out); got != "trigger;a;b;" {
+		t.Errorf("output %q", got)
+	}
+	if s := w.Stats(); s.Written != 3 {
+		t.Errorf("Written = %d", s.Written)
+	}
+}
+
+func TestAsyncWriter_MaxBytes(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxBytes: 10})
+
+	w.Write([]byte("aaaa"))
+	w.Write([]byte("bbbb"))
+	if _, err := w.Write([]byte("cccc")); err != ErrAsyncWriterFull {
+		t.Errorf("Write beyond MaxBytes = %v", err)
+	}
+	if got := drain(w, out); got != "trigger;aaaabbbb" {
+		t.Errorf("output %q", got)
+	}
+
+	// An entry larger than MaxBytes fits in an empty buffer
+	big, bigOut := newStalledAsyncWriter(t, AsyncWriterConfig{MaxBytes: 2})
+	if _, err := big.Write([]byte("larger")); err != nil {
+		t.Errorf("large entry rejected: %v", err)
+	}
+	if got := drain(big, bigOut); got != "trigger;larger" {
+		t.Errorf("output %q", got)
+	}
+}
+
+func TestAsyncWriter_DropOldest(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 2, Overflow: OverflowDropOldest})
+
+	for _, s := range []string{"a;", "b;", "c;", "d;"} {
+		if _, err := w.Write([]byte(s)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if got := drain(w, out); got != "trigger;c;d;" {
+		t.Errorf("output %q", got)
+	}
+	if s := w.Stats(); s.Dropped != 2 {
+		t.Errorf("Dropped = %d", s.Dropped)
+	}
+}
+
+func TestAsyncWriter_Block(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 1, Overflow: OverflowBlock})
+	w.Write([]byte("a;"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("b;"))
+		done <- err
+	}()
+
+	waitForStats(t, w, func(s AsyncWriterStats) bool { return s.Blocked == 1 })
+	select {
+	case err := <-done:
+		t.Fatalf("write should block, returned %v", err)
+	default:
+	}
+
+	out.open()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if got := drain(w, out); got != "trigger;a;b;" {
+		t.Errorf("output %q", got)
+	}
+}
+
+func TestAsyncWriter_BlockTimeout(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{
+		MaxEntries:   1,
+		Overflow:     OverflowBlockTimeout,
+		BlockTimeout: 10 * time.Millisecond,
+	})
+	w.Write([]byte("a;"))
+
+	start := time.Now()
+	if _, err := w.Write([]byte("b;")); err != ErrAsyncWriterFull {
+		t.Errorf("Write = %v", err)
+	}
+	if time.Since(start) < 10*time.Millisecond {
+		t.Error("write should wait for the timeout")
+	}
+	if s := w.Stats(); s.Blocked != 1 || s.TimedOut != 1 || s.Dropped != 1 {
+		t.Errorf("stats %+v", s)
+	}
+	if got := drain(w, out); got != "trigger;a;" {
+		t.Errorf("output %q", got)
+	}
+}
+
+func TestAsyncWriter_DropBelowLevel(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 2, Overflow: OverflowDropBelowLevel})
+
+	w.WriteLevel(InfoLevel, []byte("info;"))
+	w.WriteLevel(ErrorLevel, []byte("error1;"))
+
+	if _, err := w.WriteLevel(DebugLevel, []byte("debug;")); err != ErrAsyncWriterFull {
+		t.Errorf("less severe entry should be rejected, got %v", err)
+	}
+	if _, err := w.Write([]byte("plain;")); err != ErrAsyncWriterFull {
+		t.Errorf("plain write should be rejected, got %v", err)
+	}
+	// Evicts the info entry
+	if _, err := w.WriteLevel(ErrorLevel, []byte("error2;")); err != nil {
+		t.Errorf("error entry rejected: %v", err)
+	}
+
+	// Only kept entries are buffered, the next one waits
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.WriteLevel(FatalLevel, []byte("fatal;"))
+		done <- err
+	}()
+	waitForStats(t, w, func(s AsyncWriterStats) bool { return s.Blocked == 1 })
+	out.open()
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+
+	if got := drain(w, out); got != "trigger;error1;error2;fatal;" {
+		t.Errorf("output %q", got)
+	}
+	if s := w.Stats(); s.Dropped != 3 {
+		t.Errorf("Dropped = %d", s.Dropped)
+	}
+}
+
+func TestAsyncWriter_DropBelowLevelKeepPanic(t *testing.T) {
+	keep := PanicLevel
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 2, Overflow: OverflowDropBelowLevel, KeepLevel: &keep})
+
+	w.WriteLevel(InfoLevel, []byte("info;"))
+	w.WriteLevel(PanicLevel, []byte("panic1;"))
+
+	// PanicLevel is kept as set, not taken for unset
+	if _, err := w.WriteLevel(ErrorLevel, []byte("error;")); err != ErrAsyncWriterFull {
+		t.Errorf("error entry should be rejected, got %v", err)
+	}
+	if _, err := w.WriteLevel(PanicLevel, []byte("panic2;")); err != nil {
+		t.Errorf("panic entry rejected: %v", err)
+	}
+
+	if got := drain(w, out); got != "trigger;panic1;panic2;" {
+		t.Errorf("output %q", got)
+	}
+}
+
+func TestAsyncWriter_DropBelowLevelLogger(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 1, Overflow: OverflowDropBelowLevel})
+	logger := New().SetOutput(w).EnableCaller(false).EnableTrace(false).SetFormatter(&JSONFormatter{})
+
+	logger.Info("routine")
+	logger.Warn("dropped")
+	logger.Error("kept")
+
+	// The error entry evicts the info one, the warning never fits
+	got := drain(w, out)
+	if strings.Contains(got, "routine") || strings.Contains(got, "dropped") || !strings.Contains(got, "kept") {
+		t.Errorf("output %q", got)
+	}
+}
+
+func TestAsyncWriter_CloseReleasesBlocked(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 1, Overflow: OverflowBlock})
+	w.Write([]byte("a;"))
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := w.Write([]byte("b;"))
+		done <- err
+	}()
+	waitForStats(t, w, func(s AsyncWriterStats) bool { return s.Blocked == 1 })
+
+	closed := make(chan struct{})
+	go func() {
+		w.Close()
+		close(closed)
+	}()
+	if err := <-done; !errors.Is(err, ErrClosed) {
+		t.Errorf("blocked write = %v, want ErrClosed", err)
+	}
+
+	out.open()
+	<-closed
+	if got := out.String(); got != "trigger;a;" {
+		t.Errorf("output %q", got)
+	}
+}
@@ -0,0 +1,206 @@
+package log
+
+import (
+	"errors"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// recordingWriter records every write separately and counts syncs.
+// Writes wait for hold to be closed when it is set.
+type recordingWriter struct {
+	mu     sync.Mutex
+	writes []string
+	delay  time.Duration
+	hold   chan struct{}
+	syncs  atomic.Int32
+}
+
+func (w *recordingWriter) Write(p []byte) (int, error) {
+	if w.hold != nil {
+		<-w.hold
+	}
+	time.Sleep(w.delay)
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writes = append(w.writes, string(p))
+	return len(p), nil
+}
+
+func (w *recordingWriter) Sync() error {
+	w.syncs.Add(1)
+	return nil
+}
+
+func (w *recordingWriter) Close() error { return nil }
+
+func (w *recordingWriter) Writes() []string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return append([]string(nil), w.writes...)
+}
+
+func TestAsyncWriter_Sync(t *testing.T) {
+	out := &recordingWriter{delay: 5 * time.Millisecond}
+	w := NewAsyncWriter(out)
+	defer w.Close()
+
+	for i := 0; i < 20; i++ {
+		w.Write([]byte("line\n"))
+	}
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+
+	if got := strings.Count(strings.Join(out.Writes(), ""), "line\n"); got != 20 {
+		t.Errorf("Sync returned with %d of 20 lines written", got)
+	}
+	if out.syncs.Load() != 1 {
+		t.Errorf("underlying writer synced %d times", out.syncs.Load())
+	}
+
+	// Nothing pending
+	if err := w.Sync(); err != nil || out.syncs.Load() != 2 {
+		t.Errorf("Sync = %v, syncs %d", err, out.syncs.Load())
+	}
+}
+
+func TestAsyncWriter_SyncAfterDrops(t *testing.T) {
+	w, out := newStalledAsyncWriter(t, AsyncWriterConfig{MaxEntries: 1, Overflow: OverflowDropOldest})
+	w.Write([]byte("a;"))
+	w.Write([]byte("b;"))
+
+	done := make(chan struct{})
+	go func() {
+		w.Sync()
+		close(done)
+	}()
+	out.open()
+
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("Sync should not wait for dropped entries")
+	}
+	if got := out.String(); got != "trigger;b;" {
+		t.Errorf("output %q", got)
+	}
+}
+
+func TestAsyncWriter_LoggerPanicFlushes(t *testing.T) {
+	out := &recordingWriter{delay: 20 * time.Millisecond}
+	w := NewAsyncWriter(out)
+	defer w.Close()
+	logger := New().SetOutput(w).EnableCaller(false).EnableTrace(false)
+
+	func() {
+		defer func() { recover() }()
+		logger.Info("before")
+		logger.Panic("going down")
+	}()
+
+	// Everything is written by the time the panic propagates, as it must
+	// be before Fatal exits the process
+	got := strings.Join(out.Writes(), "")
+	if !strings.Contains(got, "before") || !strings.Contains(got, "going down") {
+		t.Errorf("queued lines should be written before panicking, got %q", got)
+	}
+	if out.syncs.Load() == 0 {
+		t.Error("underlying writer should be synced")
+	}
+}
+
+func TestAsyncWriter_FlushInterval(t *testing.T) {
+	out := &recordingWriter{}
+	w := NewAsyncWriterWithConfig(out, AsyncWriterConfig{FlushInterval: 100 * time.Millisecond})
+	defer w.Close()
+
+	start := time.Now()
+	w.Write([]byte("a;"))
+	w.Write([]byte("b;"))
+	w.Write([]byte("c;"))
+	waitForStats(t, w, func(s AsyncWriterStats) bool { return s.Written == 3 })
+
+	if time.Since(start) < 100*time.Millisecond {
+		t.Error("entries should wait for the flush interval")
+	}
+	if got := out.Writes(); len(got) != 1 || got[0] != "a;b;c;" {
+		t.Errorf("entries should be written in one batch, got %q", got)
+	}
+}
+
+func TestAsyncWriter_SyncCutsFlushInterval(t *testing.T) {
+	out := &recordingWriter{}
+	w := NewAsyncWriterWithConfig(out, AsyncWriterConfig{FlushInterval: time.Hour})
+
+	w.Write([]byte("a;"))
+	if err := w.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	if got := out.Writes(); len(got) != 1 || got[0] != "a;" {
+		t.Errorf("Sync should write at once, got %q", got)
+	}
+
+	w.Write([]byte("b;"))
+	w.Close()
+	if got := out.Writes(); len(got) != 2 || got[1] != "b;" {
+		t.Errorf("Close should write at once, got %q", got)
+	}
+}
+
+func TestAsyncWriter_MaxBatch(t *testing.T) {
+	out := &recordingWriter{hold: make(chan struct{})}
+	w := NewAsyncWriterWithConfig(out, AsyncWriterConfig{MaxBatch: 2})
+	defer w.Close()
+
+	w.Write([]byte("trigger;"))
+	waitForStats(t, w, func(s AsyncWriterStats) bool { return s.Queued == 0 })
+	for _, s := range []string{"a;", "b;", "c;", "d;", "e;"} {
+		w.Write([]byte(s))
+	}
+	close(out.hold)
+	w.Sync()
+
+	if got := strings.Join(out.Writes(), "|"); got != "trigger;|a;b;|c;d;|e;" {
+		t.Errorf("batches %q", got)
+	}
+}
+
+func TestAsyncWriter_CloseConcurrentWithWrite(t *testing.T) {
+	out := &recordingWriter{}
+	w := NewAsyncWriterWithConfig(out, AsyncWriterConfig{Overflow: OverflowBlock, MaxEntries: 4})
+
+	var wg sync.WaitGroup
+	var accepted atomic.Int64
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 200; i++ {
+				_, err := w.Write([]byte("x"))
+				if err == nil {
+					accepted.Add(1)
+				} else if !errors.Is(err, ErrClosed) {
+					t.Errorf("unexpected error %v", err)
+					return
+				}
+			}
+		}()
+	}
+	for c := 0; c < 3; c++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			time.Sleep(time.Millisecond)
+			w.Close()
+		}()
+	}
+	wg.Wait()
+
+	if got := int64(len(strings.Join(out.Writes(), ""))); got != accepted.Load() {
+		t.Errorf("accepted %d writes, %d written", accepted.Load(), got)
+	}
+}
@@ -0,0 +1,202 @@
+package log
+
+import (
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sync"
+	"syscall"
+	"time"
+)
+
+// DefaultReopenCheckInterval is how often a ReopenWriter checks whether its
+// file was renamed or deleted
+const DefaultReopenCheckInterval = time.Second
+
+// ReopenWriter is a file writer that reopens its path on demand, for use with
+// external rotation such as logrotate without copytruncate.
+//
+// The file is reopened by Reopen, on SIGHUP once ReopenOnSignal was called,
+// and when a periodic check finds the path no longer points to the open file
+// (renamed or deleted). Writes and reopens are serialised, each line goes
+// entirely to either the old or the new file.
+type ReopenWriter struct {
+	mu            sync.Mutex
+	filename      string
+	file          *os.File
+	info          os.FileInfo // info of the open file, compared with the path
+	checkInterval time.Duration
+	lastCheck     time.Time
+	closed        bool
+}
+
+// GetOutputWriterReopen creates a file log writer reopening filename on
+// Reopen, SIGHUP (see ReopenOnSignal) or when the file is moved away.
+// Calls for the same path share the writer until it is closed.
+func GetOutputWriterReopen(filename string) *ReopenWriter {
+	rotatorMutex.Lock()
+	defer rotatorMutex.Unlock()
+
+	key := fileKey(filename)
+	if w, ok := fileInstances[key].(*ReopenWriter); ok {
+		return w
+	}
+
+	w := &ReopenWriter{
+		filename:      filename,
+		checkInterval: DefaultReopenCheckInterval,
+	}
+
+	ensureDir(filepath.Dir(filename))
+	if err := w.open(); err != nil {
+		// Panic if creating log writer fails (critical functionality)
+		std.Panicf("failed to create log file writer %s: %v", filename, err)
+	}
+
+	fileInstances[key] = w
+	return w
+}
+
+// SetCheckInterval sets how often writes check whether the file was renamed
+// or deleted, 0 checks on every write and a negative interval disables checks
+func (w *ReopenWriter) SetCheckInterval(d time.Duration) *ReopenWriter {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.checkInterval = d
+	return w
+}
+
+// Write implements io.Writer interface
+func (w *ReopenWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return 0, ErrClosed
+	}
+
+	if w.checkInterval >= 0 {
+		if now := time.Now(); now.Sub(w.lastCheck) >= w.checkInterval {
+			w.lastCheck = now
+			if !w.unchanged() {
+				if err := w.reopen(); err != nil {
+					return 0, err
+				}
+			}
+		}
+	}
+
+	return w.file.Write(p)
+}
+
+// Reopen closes the file and opens its path again, creating it if it was
+// moved away
+func (w *ReopenWriter) Reopen() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return ErrClosed
+	}
+	return w.reopen()
+}
+
+// ReopenOnSignal reopens the file whenever one of sigs is received,
+// SIGHUP when none are given. Call the returned function to stop.
+func (w *ReopenWriter) ReopenOnSignal(sigs ...os.Signal) (stop func()) {
+	if len(sigs) == 0 {
+		sigs = []os.Signal{syscall.SIGHUP}
+	}
+
+	c := make(chan os.Signal, 1)
+	done := make(chan struct{})
+	signal.Notify(c, sigs...)
+
+	go func() {
+		for {
+			select {
+			case <-c:
+				if err := w.Reopen(); err != nil && err != ErrClosed {
+					std.Errorf("failed to reopen log file %s: %v", w.filename, err)
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			signal.Stop(c)
+			close(done)
+		})
+	}
+}
+
+// unchanged reports whether the path still points to the open file
+func (w *ReopenWriter) unchanged() bool {
+	info, err := os.Stat(w.filename)
+	return err == nil && os.SameFile(info, w.info)
+}
+
+// reopen swaps in a freshly opened file, keeping the old one on failure.
+// Must be called with w.mu held.
+func (w *ReopenWriter) reopen() error {
+	old := w.file
+
+	ensureDir(filepath.Dir(w.filename))
+	if err := w.open(); err != nil {
+		return err
+	}
+
+	if old != nil {
+		_ = old.Sync()
+		_ = old.Close()
+	}
+	return nil
+}
+
+// open opens the path and records its file info
+func (w *ReopenWriter) open() error {
+	file, err := openLogFile(w.filename)
+	if err != nil {
+		return err
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	w.file, w.info = file, info
+	return nil
+}
+
+// Sync syncs current file content to disk
+func (w *ReopenWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+// Close closes the file, later writes fail with ErrClosed;
+// GetOutputWriterReopen creates a new writer for the path.
+func (w *ReopenWriter) Close() error {
+	unregisterFile(w.filename, w)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	return w.file.Close()
+}
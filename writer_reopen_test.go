@@ -0,0 +1,136 @@
+package log
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func readLines(t *testing.T, name string) []string {
+	t.Helper()
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var lines []string
+	sc := bufio.NewScanner(strings.NewReader(string(data)))
+	for sc.Scan() {
+		lines = append(lines, sc.Text())
+	}
+	return lines
+}
+
+func TestReopenWriter_Reopen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	w := GetOutputWriterReopen(name).SetCheckInterval(-1)
+	defer w.Close()
+
+	w.Write([]byte("before\n"))
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	// Still written to the renamed file until reopened
+	w.Write([]byte("renamed\n"))
+
+	if err := w.Reopen(); err != nil {
+		t.Fatal(err)
+	}
+	w.Write([]byte("after\n"))
+
+	if got := strings.Join(readLines(t, name+".1"), ","); got != "before,renamed" {
+		t.Errorf("rotated file = %q", got)
+	}
+	if got := strings.Join(readLines(t, name), ","); got != "after" {
+		t.Errorf("new file = %q", got)
+	}
+}
+
+func TestReopenWriter_DetectsMovedFile(t *testing.T) {
+	for _, move := range []string{"rename", "delete"} {
+		t.Run(move, func(t *testing.T) {
+			name := filepath.Join(t.TempDir(), "app.log")
+			w := GetOutputWriterReopen(name).SetCheckInterval(0)
+			defer w.Close()
+
+			w.Write([]byte("before\n"))
+			var err error
+			if move == "rename" {
+				err = os.Rename(name, name+".1")
+			} else {
+				err = os.Remove(name)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			w.Write([]byte("after\n"))
+			if got := strings.Join(readLines(t, name), ","); got != "after" {
+				t.Errorf("file should be recreated, got %q", got)
+			}
+		})
+	}
+}
+
+func TestReopenWriter_ConcurrentReopen(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	w := GetOutputWriterReopen(name).SetCheckInterval(0)
+	defer w.Close()
+
+	const writers, lines, rotations = 8, 200, 20
+
+	var wg sync.WaitGroup
+	for g := 0; g < writers; g++ {
+		wg.Add(1)
+		go func(g int) {
+			defer wg.Done()
+			for i := 0; i < lines; i++ {
+				line := fmt.Sprintf("writer=%d line=%d %s\n", g, i, strings.Repeat("x", 64))
+				if _, err := w.Write([]byte(line)); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}(g)
+	}
+
+	for r := 1; r <= rotations; r++ {
+		_ = os.Rename(name, fmt.Sprintf("%s.%d", name, r))
+		if r%2 == 0 {
+			_ = w.Reopen()
+		}
+	}
+	wg.Wait()
+
+	seen := make(map[string]bool)
+	files, _ := filepath.Glob(name + "*")
+	for _, file := range files {
+		for _, line := range readLines(t, file) {
+			var g, i int
+			if _, err := fmt.Sscanf(line, "writer=%d line=%d", &g, &i); err != nil || len(line) != len(fmt.Sprintf("writer=%d line=%d ", g, i))+64 {
+				t.Fatalf("interleaved line %q in %s", line, file)
+			}
+			seen[line[:strings.LastIndex(line, " ")]] = true
+		}
+	}
+	if len(seen) != writers*lines {
+		t.Errorf("expected %d lines across %d files, found %d", writers*lines, len(files), len(seen))
+	}
+}
+
+func TestReopenWriter_Closed(t *testing.T) {
+	w := GetOutputWriterReopen(filepath.Join(t.TempDir(), "app.log"))
+	w.Close()
+	w.Close()
+
+	if _, err := w.Write([]byte("late\n")); !errors.Is(err, ErrClosed) {
+		t.Errorf("Write after Close = %v", err)
+	}
+	if err := w.Reopen(); !errors.Is(err, ErrClosed) {
+		t.Errorf("Reopen after Close = %v", err)
+	}
+}
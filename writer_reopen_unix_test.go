@@ -0,0 +1,45 @@
+//go:build unix
+
+package log
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+func TestReopenWriter_SIGHUP(t *testing.T) {
+	name := filepath.Join(t.TempDir(), "app.log")
+	w := GetOutputWriterReopen(name).SetCheckInterval(-1)
+	defer w.Close()
+
+	stop := w.ReopenOnSignal()
+	defer stop()
+
+	w.Write([]byte("before\n"))
+	if err := os.Rename(name, name+".1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+		t.Fatal(err)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		if _, err := os.Stat(name); err == nil {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("file not reopened on SIGHUP")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	w.Write([]byte("after\n"))
+	if got := strings.Join(readLines(t, name), ","); got != "after" {
+		t.Errorf("new file = %q", got)
+	}
+}